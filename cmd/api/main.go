@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,20 +11,38 @@ import (
 	"syscall"
 	"time"
 
+	"pr-reviewer-service/internal/chat"
 	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/email"
+	"pr-reviewer-service/internal/github"
+	"pr-reviewer-service/internal/gitlab"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/internal/oidc"
 	"pr-reviewer-service/internal/repository/postgres"
 	"pr-reviewer-service/internal/service"
 	httptransport "pr-reviewer-service/internal/transport/http"
+	"pr-reviewer-service/internal/vcs"
+	"pr-reviewer-service/internal/webhook"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres" // driver
 	_ "github.com/golang-migrate/migrate/v4/source/file"       // driver
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	if err := run(logger); err != nil {
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+		err = runSync(logger, os.Args[2:])
+	} else {
+		err = run(logger)
+	}
+
+	if err != nil {
 		logger.Error("application startup error", "error", err)
 		os.Exit(1)
 	}
@@ -32,33 +51,132 @@ func main() {
 func run(logger *slog.Logger) error {
 	cfg := config.LoadConfig()
 
-	logger.Info("connecting to database...")
-	dbPool, err := postgres.NewPsqlConnection(postgres.Config{DSN: cfg.DatabaseDSN})
+	dbPool, err := openDatabase(cfg, logger)
 	if err != nil {
 		return err
 	}
 	defer dbPool.Close()
-	logger.Info("database connection established")
-
-	logger.Info("running database migrations...")
-	m, err := migrate.New("file://migrations", cfg.DatabaseDSN)
-	if err != nil {
-		return err
-	}
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return err
-	}
-	logger.Info("database migrations complete")
 
 	teamRepo := postgres.NewTeamRepo(dbPool)
 	userRepo := postgres.NewUserRepo(dbPool)
 	prRepo := postgres.NewPullRequestRepo(dbPool)
+	orgRepo := postgres.NewOrgRepo(dbPool)
+	webhookRepo := postgres.NewWebhookRepo(dbPool)
+	chatChannelRepo := postgres.NewChatChannelRepo(dbPool)
+	identityRepo := postgres.NewIdentityRepo(dbPool)
+	tokenRepo := postgres.NewAPITokenRepo(dbPool)
+	idempotencyRepo := postgres.NewIdempotencyRepo(dbPool)
+	unitOfWork := postgres.NewUnitOfWork(dbPool)
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	chatService := service.NewChatService(chatChannelRepo, teamRepo, userRepo, identityRepo)
+	chatCfg := chat.DefaultConfig()
+	chatQueue := notify.NewQueue("chat", chat.NewSender(chatService, &http.Client{Timeout: chatCfg.Timeout}, chatCfg), chatCfg.QueueSize, logger)
+	go chatQueue.Run(context.Background())
+
+	queues := []*notify.Queue{chatQueue}
+	notifier := notify.Notifiers{chatQueue}
+
+	if cfg.SMTPAddr != "" {
+		emailCfg := email.DefaultConfig()
+		emailCfg.Addr = cfg.SMTPAddr
+		emailCfg.From = cfg.SMTPFrom
+		emailCfg.Username = cfg.SMTPUsername
+		emailCfg.Password = cfg.SMTPPassword
+		emailSender := email.NewSender(userRepo, prRepo, emailCfg, logger)
+
+		emailQueue := notify.NewQueue("email", emailSender, emailCfg.QueueSize, logger)
+		go emailQueue.Run(context.Background())
+		queues = append(queues, emailQueue)
+		notifier = append(notifier, emailQueue)
+
+		if cfg.EmailDigestAt != "" {
+			digestAt, err := email.ParseDigestTime(cfg.EmailDigestAt)
+			if err != nil {
+				return err
+			}
+			go emailSender.RunDigests(workersCtx, digestAt)
+		}
+	}
+
+	// The VCS service needs the pull request service, which needs the
+	// notifier, so the queue gets its sender and starts once both exist.
+	vcsCfg := vcs.DefaultConfig()
+	vcsClients := map[domain.IdentityProvider]service.VCSClient{}
+	vcsAccounts := map[domain.IdentityProvider]service.VCSAccountResolver{}
+	if cfg.GitHubToken != "" {
+		githubCfg := github.DefaultConfig()
+		githubCfg.APIURL = cfg.GitHubAPIURL
+		githubCfg.Token = cfg.GitHubToken
+		vcsClients[domain.IdentityGitHub] = github.NewClient(&http.Client{Timeout: githubCfg.Timeout}, githubCfg)
+	}
+	if cfg.GitLabToken != "" {
+		gitlabCfg := gitlab.DefaultConfig()
+		gitlabCfg.URL = cfg.GitLabURL
+		gitlabCfg.Token = cfg.GitLabToken
+		gitlabClient := gitlab.NewClient(&http.Client{Timeout: gitlabCfg.Timeout}, gitlabCfg)
+		vcsClients[domain.IdentityGitLab] = gitlabClient
+		vcsAccounts[domain.IdentityGitLab] = gitlabClient
+	}
+
+	var vcsQueue *notify.Queue
+	var vcsSender *vcs.Sender
+	if len(vcsClients) > 0 {
+		send := notify.SenderFunc(func(ctx context.Context, n service.Notification) error {
+			return vcsSender.Send(ctx, n)
+		})
+		retry := notify.Retry{Attempts: vcsCfg.Attempts, Backoff: vcsCfg.Backoff}
+		vcsQueue = notify.NewRetryingQueue("vcs", send, vcsCfg.QueueSize, retry, logger)
+		queues = append(queues, vcsQueue)
+		notifier = append(notifier, vcsQueue)
+	}
+
+	teamService := service.NewTeamService(teamRepo, userRepo, unitOfWork, notifier)
+	userService := service.NewUserService(userRepo, prRepo, unitOfWork, notifier)
+	prService := service.NewPullRequestService(prRepo, userRepo, teamRepo, unitOfWork, notifier)
+	orgSyncService := service.NewOrgSyncService(orgRepo, unitOfWork, notifier)
+	stateService := service.NewStateService(unitOfWork)
+	webhookService := service.NewWebhookService(webhookRepo)
+	identityService := service.NewIdentityService(identityRepo, userRepo)
+	vcsService := service.NewVCSService(identityRepo, prRepo, prService, vcsAccounts)
+	tokenService := service.NewTokenService(tokenRepo, cfg.AdminToken)
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo, prRepo)
-	prService := service.NewPullRequestService(prRepo, userRepo)
+	idempotencyTTL, err := time.ParseDuration(cfg.IdempotencyTTL)
+	if err != nil || idempotencyTTL <= 0 {
+		return fmt.Errorf("invalid IDEMPOTENCY_TTL %q", cfg.IdempotencyTTL)
+	}
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, idempotencyTTL)
+	go runIdempotencyPurge(workersCtx, idempotencyService, logger)
+	vcsSender = vcs.NewSender(vcsService, vcsClients)
+	if vcsQueue != nil {
+		go vcsQueue.Run(context.Background())
+	}
+
+	integrations := httptransport.IntegrationConfig{
+		GitHubWebhookSecret: cfg.GitHubWebhookSecret,
+		GitLabWebhookToken:  cfg.GitLabWebhookToken,
+	}
+	auth := httptransport.AuthConfig{Required: cfg.AdminToken != "" || cfg.OIDCJWKS != ""}
+	if cfg.OIDCJWKS != "" {
+		oidcCfg := oidc.DefaultConfig()
+		oidcCfg.JWKS = cfg.OIDCJWKS
+		oidcCfg.Issuer = cfg.OIDCIssuer
+		oidcCfg.Audience = cfg.OIDCAudience
+		oidcCfg.UserIDClaim = cfg.OIDCUserIDClaim
+		oidcCfg.RolesClaim = cfg.OIDCRolesClaim
 
-	httpHandler := httptransport.NewHandler(teamService, userService, prService, logger)
+		verifier, err := oidc.NewVerifier(context.Background(), &http.Client{Timeout: oidcCfg.Timeout}, oidcCfg)
+		if err != nil {
+			return err
+		}
+		auth.Verifier = verifier
+	}
+	if !auth.Required {
+		logger.Warn("neither ADMIN_TOKEN nor OIDC_JWKS is set, the API is open to anyone who can reach it")
+	}
+	httpHandler := httptransport.NewHandler(teamService, userService, prService, orgSyncService, stateService, webhookService, chatService, identityService, vcsService, tokenService, idempotencyService, integrations, auth, logger)
 
 	router := httpHandler.RegisterRoutes()
 
@@ -70,6 +188,10 @@ func run(logger *slog.Logger) error {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	webhookCfg := webhook.DefaultConfig()
+	dispatcher := webhook.NewDispatcher(webhookRepo, &http.Client{Timeout: webhookCfg.Timeout}, webhookCfg, logger)
+	go dispatcher.Run(workersCtx)
+
 	serverErrors := make(chan error, 1)
 
 	go func() {
@@ -94,6 +216,52 @@ func run(logger *slog.Logger) error {
 		return err
 	}
 
+	for _, queue := range queues {
+		if err := queue.Close(ctx); err != nil {
+			logger.Warn("notifications left unsent", "error", err)
+		}
+	}
+
 	logger.Info("server shut down gracefully")
 	return nil
 }
+
+// runIdempotencyPurge deletes expired idempotent requests once an hour.
+func runIdempotencyPurge(ctx context.Context, s *service.IdempotencyService, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Purge(ctx); err != nil {
+				logger.Error("failed to purge idempotency keys", "error", err)
+			}
+		}
+	}
+}
+
+func openDatabase(cfg config.Config, logger *slog.Logger) (*pgxpool.Pool, error) {
+	logger.Info("connecting to database...")
+	dbPool, err := postgres.NewPsqlConnection(postgres.Config{DSN: cfg.DatabaseDSN})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("database connection established")
+
+	logger.Info("running database migrations...")
+	m, err := migrate.New("file://migrations", cfg.DatabaseDSN)
+	if err != nil {
+		dbPool.Close()
+		return nil, err
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		dbPool.Close()
+		return nil, err
+	}
+	logger.Info("database migrations complete")
+
+	return dbPool, nil
+}
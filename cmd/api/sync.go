@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/orgspec"
+	"pr-reviewer-service/internal/repository/postgres"
+	"pr-reviewer-service/internal/service"
+)
+
+// runSync implements `api sync -file org.yaml [-plan]`: it brings the org
+// structure in line with the file and prints the diff to stdout.
+func runSync(logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	file := flags.String("file", "", "path to the org spec in YAML or JSON")
+	plan := flags.Bool("plan", false, "only print the diff, do not apply it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return errors.New("sync: -file is required")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	spec, err := orgspec.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+
+	dbPool, err := openDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer dbPool.Close()
+
+	// The CLI does not notify chats, the reviews it hands over are listed in
+	// its output.
+	orgSyncService := service.NewOrgSyncService(
+		postgres.NewOrgRepo(dbPool),
+		postgres.NewUnitOfWork(dbPool),
+		nil,
+	)
+
+	sync, err := orgSyncService.Sync(domain.WithActor(context.Background(), "cli:sync"), spec, *plan)
+	if err != nil {
+		return err
+	}
+
+	if len(sync.Changes) == 0 {
+		fmt.Println("no changes")
+		return nil
+	}
+
+	for _, change := range sync.Changes {
+		fmt.Println(change.String())
+	}
+
+	for _, released := range sync.ReleasedReviews {
+		if released.ReplacedBy == "" {
+			fmt.Printf("review on %s dropped\n", released.PullRequestID)
+			continue
+		}
+		fmt.Printf("review on %s reassigned to %s\n", released.PullRequestID, released.ReplacedBy)
+	}
+
+	if !sync.Applied {
+		fmt.Printf("plan: %d change(s), nothing applied\n", len(sync.Changes))
+	}
+
+	return nil
+}
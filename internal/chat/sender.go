@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pr-reviewer-service/internal/service"
+	"time"
+)
+
+const maxErrorBodyBytes = 512
+
+type Config struct {
+	// QueueSize bounds the notifications waiting to be posted.
+	QueueSize int
+	// Timeout limits a single post to a chat.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		QueueSize: 256,
+		Timeout:   5 * time.Second,
+	}
+}
+
+// Payload is the incoming webhook body, Slack and Mattermost both accept it.
+type Payload struct {
+	Text string `json:"text"`
+}
+
+// Sender posts notifications to the chat channels of the teams.
+type Sender struct {
+	chatService *service.ChatService
+	client      *http.Client
+	cfg         Config
+}
+
+func NewSender(cs *service.ChatService, client *http.Client, cfg Config) *Sender {
+	return &Sender{
+		chatService: cs,
+		client:      client,
+		cfg:         cfg,
+	}
+}
+
+// Send posts the notification, it does nothing when no team has a channel
+// for it.
+func (s *Sender) Send(ctx context.Context, notification service.Notification) error {
+	msg, ok, err := s.chatService.Message(ctx, notification)
+	if err != nil || !ok {
+		return err
+	}
+
+	body, err := json.Marshal(Payload{Text: msg.Text})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return fmt.Errorf("chat responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return nil
+}
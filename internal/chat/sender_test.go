@@ -0,0 +1,108 @@
+package chat_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/chat"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the chat messages it gets.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	messages []chat.Payload
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload chat.Payload
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.messages = append(rc.messages, payload)
+	if rc.status != 0 {
+		w.WriteHeader(rc.status)
+		return
+	}
+	_, _ = io.WriteString(w, "ok")
+}
+
+func (rc *receiver) texts() []string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	texts := make([]string, len(rc.messages))
+	for i, msg := range rc.messages {
+		texts[i] = msg.Text
+	}
+
+	return texts
+}
+
+func setupSenderTest(t *testing.T) (context.Context, *receiver, *chat.Sender) {
+	t.Helper()
+
+	ctx := context.Background()
+	storage, _ := inmemory.NewStorage()
+	teamRepo := inmemory.NewTeamRepo(storage)
+	userRepo := inmemory.NewUserRepo(storage)
+	chatService := service.NewChatService(inmemory.NewChatChannelRepo(storage), teamRepo, userRepo, inmemory.NewIdentityRepo(storage))
+
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	require.NoError(t, teamRepo.Create(ctx, domain.Team{Name: "backend"}))
+	require.NoError(t, teamRepo.Create(ctx, domain.Team{Name: "frontend"}))
+	require.NoError(t, userRepo.Create(ctx, domain.User{ID: "u1", Username: "alice", TeamName: "backend", IsActive: true}))
+	require.NoError(t, userRepo.Create(ctx, domain.User{ID: "u2", Username: "bob", TeamName: "backend", IsActive: true}))
+	_, err := chatService.SetChannel(ctx, domain.ChatChannel{TeamName: "backend", WebhookURL: server.URL})
+	require.NoError(t, err)
+
+	return ctx, rc, chat.NewSender(chatService, server.Client(), chat.Config{Timeout: time.Second})
+}
+
+func assignment(prID domain.PullRequestID, teamName domain.TeamName) service.Notification {
+	return service.Notification{
+		Kind: domain.EventReviewerAssigned,
+		PullRequest: domain.PullRequest{
+			ID:                prID,
+			Name:              "Add search",
+			AuthorID:          "u1",
+			TeamName:          teamName,
+			AssignedReviewers: []domain.UserID{"u2"},
+		},
+		Reviewers: []domain.UserID{"u2"},
+	}
+}
+
+func TestSendPostsToTeamChannel(t *testing.T) {
+	ctx, rc, sender := setupSenderTest(t)
+
+	require.NoError(t, sender.Send(ctx, assignment("pr-1", "backend")))
+	require.NoError(t, sender.Send(ctx, assignment("pr-2", "frontend")))
+
+	assert.Equal(t, []string{`@bob please review "Add search" (pr-1) by alice`}, rc.texts())
+}
+
+func TestSendReportsChatErrors(t *testing.T) {
+	ctx, rc, sender := setupSenderTest(t)
+	rc.status = http.StatusNotFound
+
+	err := sender.Send(ctx, assignment("pr-1", "backend"))
+
+	assert.ErrorContains(t, err, "chat responded 404")
+}
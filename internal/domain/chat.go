@@ -0,0 +1,17 @@
+package domain
+
+// ChatEventKinds are the events posted to team chat channels.
+var ChatEventKinds = []PREventKind{
+	EventReviewerAssigned,
+	EventReviewerReassigned,
+	EventMerged,
+}
+
+// ChatChannel is the Slack or Mattermost incoming webhook a team is notified
+// through. Templates override the default message per event kind, they are
+// Go text/templates.
+type ChatChannel struct {
+	TeamName   TeamName
+	WebhookURL string
+	Templates  map[PREventKind]string
+}
@@ -3,10 +3,22 @@ package domain
 import "errors"
 
 var (
-	ErrTeamExists  = errors.New("team already exists")
-	ErrPRExists    = errors.New("pull request already exists")
-	ErrPRMerged    = errors.New("operation not allowed on merged pull request")
-	ErrNotAssigned = errors.New("reviewer is not assigned to this pull request")
-	ErrNoCandidate = errors.New("no active replacement candidate available in team")
-	ErrNotFound    = errors.New("resource not found")
+	ErrTeamExists      = errors.New("team already exists")
+	ErrUserExists      = errors.New("user already exists")
+	ErrPRExists        = errors.New("pull request already exists")
+	ErrPRMerged        = errors.New("operation not allowed on merged pull request")
+	ErrPRClosed        = errors.New("operation not allowed on closed pull request")
+	ErrNotAssigned     = errors.New("reviewer is not assigned to this pull request")
+	ErrNoCandidate     = errors.New("no active replacement candidate available in team")
+	ErrNotFound        = errors.New("resource not found")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrUserInOtherTeam = errors.New("user already belongs to another team")
+	ErrTeamArchived    = errors.New("team is archived")
+	ErrTeamNotEmpty    = errors.New("team still has members, pull requests or sub-teams")
+	ErrHasOpenReviews  = errors.New("user still has open reviews")
+	ErrStorageNotEmpty = errors.New("storage already holds data")
+	ErrUnauthorized    = errors.New("missing or invalid credentials")
+	ErrForbidden       = errors.New("operation not permitted")
+	ErrKeyReused       = errors.New("idempotency key was used for another request")
+	ErrKeyInProgress   = errors.New("request with this idempotency key is still in progress")
 )
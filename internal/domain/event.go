@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type PREventKind string
+
+const (
+	EventCreated            PREventKind = "CREATED"
+	EventReviewerAssigned   PREventKind = "REVIEWER_ASSIGNED"
+	EventReviewerReassigned PREventKind = "REVIEWER_REASSIGNED"
+	EventReviewerRemoved    PREventKind = "REVIEWER_REMOVED"
+	EventReviewSubmitted    PREventKind = "REVIEW_SUBMITTED"
+	EventAuthorChanged      PREventKind = "AUTHOR_CHANGED"
+	EventMerged             PREventKind = "MERGED"
+	EventClosed             PREventKind = "CLOSED"
+	EventReopened           PREventKind = "REOPENED"
+)
+
+var PREventKinds = []PREventKind{
+	EventCreated,
+	EventReviewerAssigned,
+	EventReviewerReassigned,
+	EventReviewerRemoved,
+	EventReviewSubmitted,
+	EventAuthorChanged,
+	EventMerged,
+	EventClosed,
+	EventReopened,
+}
+
+// ReassignReason tells why a reviewer was replaced or removed.
+type ReassignReason string
+
+const (
+	ReassignManual       ReassignReason = "MANUAL"
+	ReassignLeftTeam     ReassignReason = "LEFT_TEAM"
+	ReassignTeamArchived ReassignReason = "TEAM_ARCHIVED"
+	ReassignDeactivated  ReassignReason = "DEACTIVATED"
+	ReassignUserDeleted  ReassignReason = "USER_DELETED"
+	ReassignOffboarded   ReassignReason = "OFFBOARDED"
+)
+
+// PREvent is an entry of the append-only pull request timeline. UserID is
+// the reviewer the event is about, or the new author for AUTHOR_CHANGED;
+// PreviousUserID is the replaced reviewer or the previous author.
+type PREvent struct {
+	ID             int64
+	PullRequestID  PullRequestID
+	Kind           PREventKind
+	UserID         UserID
+	PreviousUserID UserID
+	ReviewState    ReviewState
+	Reason         ReassignReason
+	Actor          string
+	CreatedAt      time.Time
+}
+
+type actorKey struct{}
+
+// WithActor records who triggers the changes made with the context, the
+// actor ends up in the pull request timeline.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor or an empty string.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
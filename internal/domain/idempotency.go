@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// IdempotentRequest is a request made with an idempotency key and, once it
+// completed, the response it got. Duplicates of the request get the same
+// response until the request expires.
+type IdempotentRequest struct {
+	Key         string
+	RequestHash string
+	// StatusCode is zero while the request is in progress.
+	StatusCode int
+	Body       []byte
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+func (r IdempotentRequest) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "strings"
+
+// IdentityProvider is an external system users have accounts at.
+type IdentityProvider string
+
+const (
+	IdentityGitHub IdentityProvider = "github"
+	IdentityGitLab IdentityProvider = "gitlab"
+	// IdentitySlack external IDs are Slack member IDs like U012AB3CD.
+	IdentitySlack IdentityProvider = "slack"
+	IdentityEmail IdentityProvider = "email"
+)
+
+var IdentityProviders = []IdentityProvider{
+	IdentityGitHub,
+	IdentityGitLab,
+	IdentitySlack,
+	IdentityEmail,
+}
+
+// UserIdentity links a user to their account at an external provider.
+type UserIdentity struct {
+	UserID     UserID
+	Provider   IdentityProvider
+	ExternalID string
+}
+
+// NormalizeExternalID brings an external ID to the form it is stored in,
+// GitHub and GitLab usernames and emails are case-insensitive.
+func NormalizeExternalID(provider IdentityProvider, externalID string) string {
+	externalID = strings.TrimSpace(externalID)
+	if provider == IdentityGitHub || provider == IdentityGitLab || provider == IdentityEmail {
+		return strings.ToLower(externalID)
+	}
+	return externalID
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrgSpec is the desired structure of the whole organisation. A user listed
+// in several teams is a member of all of them, the first one is the home team.
+type OrgSpec struct {
+	Teams []TeamSpec
+}
+
+type TeamSpec struct {
+	Name       TeamName
+	ParentName TeamName
+	Members    []MemberSpec
+}
+
+type MemberSpec struct {
+	UserID   UserID
+	Username string
+	FullName string
+	Email    string
+	Role     UserRole
+	IsActive bool
+}
+
+// OrgSnapshot is the current structure of the organisation, deleted users
+// are not part of it.
+type OrgSnapshot struct {
+	Teams []Team
+	Users []User
+}
+
+type OrgChangeKind string
+
+const (
+	ChangeCreateTeam       OrgChangeKind = "CREATE_TEAM"
+	ChangeSetTeamParent    OrgChangeKind = "SET_TEAM_PARENT"
+	ChangeCreateUser       OrgChangeKind = "CREATE_USER"
+	ChangeUpdateUser       OrgChangeKind = "UPDATE_USER"
+	ChangeAddMembership    OrgChangeKind = "ADD_MEMBERSHIP"
+	ChangeSetHomeTeam      OrgChangeKind = "SET_HOME_TEAM"
+	ChangeRemoveMembership OrgChangeKind = "REMOVE_MEMBERSHIP"
+	ChangeArchiveTeam      OrgChangeKind = "ARCHIVE_TEAM"
+)
+
+// OrgChange is a single step of an org sync. Team changes use TeamName and
+// ParentName, user changes carry the desired user in User and membership
+// changes use User.ID with TeamName.
+type OrgChange struct {
+	Kind       OrgChangeKind
+	TeamName   TeamName
+	ParentName TeamName
+	User       User
+}
+
+func (c OrgChange) String() string {
+	switch c.Kind {
+	case ChangeCreateTeam:
+		if c.ParentName != "" {
+			return fmt.Sprintf("+ team %s (parent %s)", c.TeamName, c.ParentName)
+		}
+		return fmt.Sprintf("+ team %s", c.TeamName)
+	case ChangeSetTeamParent:
+		if c.ParentName == "" {
+			return fmt.Sprintf("~ team %s: no parent", c.TeamName)
+		}
+		return fmt.Sprintf("~ team %s: parent %s", c.TeamName, c.ParentName)
+	case ChangeArchiveTeam:
+		return fmt.Sprintf("- team %s (archive)", c.TeamName)
+	case ChangeCreateUser:
+		return fmt.Sprintf("+ user %s (%s)", c.User.ID, describeUser(c.User))
+	case ChangeUpdateUser:
+		return fmt.Sprintf("~ user %s (%s)", c.User.ID, describeUser(c.User))
+	case ChangeAddMembership:
+		return fmt.Sprintf("+ member %s of %s", c.User.ID, c.TeamName)
+	case ChangeRemoveMembership:
+		return fmt.Sprintf("- member %s of %s", c.User.ID, c.TeamName)
+	case ChangeSetHomeTeam:
+		if c.TeamName == "" {
+			return fmt.Sprintf("~ user %s: no home team", c.User.ID)
+		}
+		return fmt.Sprintf("~ user %s: home team %s", c.User.ID, c.TeamName)
+	}
+
+	return string(c.Kind)
+}
+
+func describeUser(u User) string {
+	parts := []string{"username=" + u.Username}
+	if u.FullName != "" {
+		parts = append(parts, "full_name="+u.FullName)
+	}
+	if u.Email != "" {
+		parts = append(parts, "email="+u.Email)
+	}
+	parts = append(parts, "role="+string(u.Role), fmt.Sprintf("active=%t", u.IsActive))
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type SortOrder string
+
+const (
+	SortOldestFirst SortOrder = "oldest"
+	SortNewestFirst SortOrder = "newest"
+)
+
+// PullRequestCursor points at the last pull request of a page. The next page
+// starts strictly after it in the requested sort order.
+type PullRequestCursor struct {
+	CreatedAt time.Time
+	ID        PullRequestID
+}
+
+type PullRequestFilter struct {
+	Statuses []PRStatus
+	TeamName TeamName
+	Order    SortOrder
+	Limit    int
+	After    *PullRequestCursor
+}
+
+func (f PullRequestFilter) MatchesTeam(teamName TeamName) bool {
+	return f.TeamName == "" || f.TeamName == teamName
+}
+
+func (f PullRequestFilter) MatchesStatus(status PRStatus) bool {
+	if len(f.Statuses) == 0 {
+		return true
+	}
+
+	for _, s := range f.Statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAfterCursor reports whether an item with the given key belongs to the
+// page that follows the filter cursor.
+func (f PullRequestFilter) IsAfterCursor(createdAt time.Time, id PullRequestID) bool {
+	if f.After == nil {
+		return true
+	}
+
+	cmp := createdAt.Compare(f.After.CreatedAt)
+	if cmp == 0 {
+		cmp = strings.Compare(string(id), string(f.After.ID))
+	}
+
+	if f.Order == SortNewestFirst {
+		return cmp < 0
+	}
+
+	return cmp > 0
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal is the signed-in user a request is made by.
+type Principal struct {
+	UserID UserID
+	Roles  []UserRole
+}
+
+func (p Principal) HasRole(role UserRole) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal set with WithPrincipal, false
+// means the request was not made by a signed-in user.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -9,21 +9,62 @@ type PRStatus string
 const (
 	StatusOpen   PRStatus = "OPEN"
 	StatusMerged PRStatus = "MERGED"
+	StatusClosed PRStatus = "CLOSED"
+)
+
+var PRStatuses = []PRStatus{StatusOpen, StatusMerged, StatusClosed}
+
+type ReviewState string
+
+const (
+	ReviewPending          ReviewState = "PENDING"
+	ReviewApproved         ReviewState = "APPROVED"
+	ReviewChangesRequested ReviewState = "CHANGES_REQUESTED"
 )
 
 type PullRequest struct {
 	ID                PullRequestID
 	Name              string
 	AuthorID          UserID
+	TeamName          TeamName
 	Status            PRStatus
 	AssignedReviewers []UserID
+	ReviewStates      map[UserID]ReviewState
 	CreatedAt         time.Time
 	MergedAt          *time.Time
+	ClosedAt          *time.Time
+}
+
+// ReviewState returns the state of the given reviewer, a reviewer without
+// a recorded state is still pending.
+func (pr PullRequest) ReviewState(reviewerID UserID) ReviewState {
+	if state, ok := pr.ReviewStates[reviewerID]; ok {
+		return state
+	}
+
+	return ReviewPending
+}
+
+// OpenDuration returns how long the pull request has been open, merged and
+// closed pull requests stop the clock at merge or close time.
+func (pr PullRequest) OpenDuration(now time.Time) time.Duration {
+	if pr.MergedAt != nil {
+		return pr.MergedAt.Sub(pr.CreatedAt)
+	}
+
+	if pr.ClosedAt != nil {
+		return pr.ClosedAt.Sub(pr.CreatedAt)
+	}
+
+	return now.Sub(pr.CreatedAt)
 }
 
 type PullRequestShort struct {
-	ID       PullRequestID
-	Name     string
-	AuthorID UserID
-	Status   PRStatus
+	ID          PullRequestID
+	Name        string
+	AuthorID    UserID
+	TeamName    TeamName
+	Status      PRStatus
+	CreatedAt   time.Time
+	ReviewState ReviewState
 }
@@ -1,8 +1,21 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+)
+
 type Team struct {
-	Name    TeamName
-	Members []TeamMember
+	Name       TeamName
+	ParentName TeamName
+	Members    []TeamMember
+	ArchivedAt *time.Time
+	// SubTeams is only filled when descendants were requested explicitly.
+	SubTeams []Team
+}
+
+func (t Team) IsArchived() bool {
+	return t.ArchivedAt != nil
 }
 
 type TeamMember struct {
@@ -10,3 +23,22 @@ type TeamMember struct {
 	Username string
 	IsActive bool
 }
+
+type MemberConflict struct {
+	UserID   UserID
+	TeamName TeamName
+}
+
+// TeamConflictError lists members of a new team who already belong to
+// another team.
+type TeamConflictError struct {
+	Conflicts []MemberConflict
+}
+
+func (e *TeamConflictError) Error() string {
+	return fmt.Sprintf("%s: %d member(s) conflict", ErrUserInOtherTeam, len(e.Conflicts))
+}
+
+func (e *TeamConflictError) Unwrap() error {
+	return ErrUserInOtherTeam
+}
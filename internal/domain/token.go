@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// TokenScope is a part of the API a token grants access to.
+type TokenScope string
+
+const (
+	ScopeRead       TokenScope = "read"
+	ScopeTeamsWrite TokenScope = "teams:write"
+	ScopePRsWrite   TokenScope = "prs:write"
+	// ScopeAdmin grants every other scope as well.
+	ScopeAdmin TokenScope = "admin"
+)
+
+var TokenScopes = []TokenScope{
+	ScopeRead,
+	ScopeTeamsWrite,
+	ScopePRsWrite,
+	ScopeAdmin,
+}
+
+// APIToken is a bearer token for the API. Only the hash of the secret is
+// kept, the secret itself is shown once when the token is issued.
+type APIToken struct {
+	ID         int64
+	Name       string
+	Scopes     []TokenScope
+	Hash       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (t APIToken) Allows(scope TokenScope) bool {
+	return slices.Contains(t.Scopes, ScopeAdmin) || slices.Contains(t.Scopes, scope)
+}
+
+type apiTokenKey struct{}
+
+// WithAPIToken records the API token a request is made with.
+func WithAPIToken(ctx context.Context, token APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenKey{}, token)
+}
+
+// APITokenFromContext returns the token set with WithAPIToken, false means
+// the request was not made with an API token.
+func APITokenFromContext(ctx context.Context) (APIToken, bool) {
+	token, ok := ctx.Value(apiTokenKey{}).(APIToken)
+	return token, ok
+}
@@ -1,8 +1,79 @@
 package domain
 
+import (
+	"slices"
+	"time"
+)
+
+type UserRole string
+
+const (
+	RoleMember UserRole = "MEMBER"
+	RoleLead   UserRole = "LEAD"
+	RoleAdmin  UserRole = "ADMIN"
+)
+
+var UserRoles = []UserRole{RoleMember, RoleLead, RoleAdmin}
+
 type User struct {
 	ID       UserID
 	Username string
-	TeamName TeamName
-	IsActive bool
+	FullName string
+	Email    string
+	Role     UserRole
+	// TeamName is the user's home team, Teams lists every team the user
+	// reviews for and always includes the home team.
+	TeamName  TeamName
+	Teams     []TeamName
+	IsActive  bool
+	DeletedAt *time.Time
+}
+
+func (u User) IsMemberOf(teamName TeamName) bool {
+	if teamName == "" {
+		return false
+	}
+
+	return u.TeamName == teamName || slices.Contains(u.Teams, teamName)
+}
+
+func (u User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// UserFilter narrows down the user directory. Users are ordered by ID and
+// After holds the ID of the last user of the previous page. Deleted users
+// are only listed with IncludeDeleted.
+type UserFilter struct {
+	TeamName       TeamName
+	Username       string
+	IsActive       *bool
+	Role           UserRole
+	IncludeDeleted bool
+	Limit          int
+	After          UserID
+}
+
+func (f UserFilter) Matches(user User) bool {
+	if user.IsDeleted() && !f.IncludeDeleted {
+		return false
+	}
+
+	if f.TeamName != "" && !user.IsMemberOf(f.TeamName) {
+		return false
+	}
+
+	if f.Username != "" && user.Username != f.Username {
+		return false
+	}
+
+	if f.IsActive != nil && user.IsActive != *f.IsActive {
+		return false
+	}
+
+	if f.Role != "" && user.Role != f.Role {
+		return false
+	}
+
+	return f.After == "" || user.ID > f.After
 }
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+type WebhookSubscription struct {
+	ID         int64
+	URL        string
+	EventTypes []PREventKind
+	Secret     string
+	CreatedAt  time.Time
+}
+
+func (s WebhookSubscription) Wants(kind PREventKind) bool {
+	return slices.Contains(s.EventTypes, kind)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliveryDelivered DeliveryStatus = "DELIVERED"
+	// DeliveryDead marks deliveries that ran out of attempts, they stay in
+	// the outbox as dead letters until redelivered.
+	DeliveryDead DeliveryStatus = "DEAD"
+)
+
+// WebhookDelivery is an outbox entry: one event for one subscription.
+type WebhookDelivery struct {
+	ID            int64
+	Subscription  WebhookSubscription
+	Event         PREvent
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	DeliveredAt   *time.Time
+}
@@ -0,0 +1,294 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"pr-reviewer-service/internal/service"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/*.html.tmpl"))
+)
+
+type Config struct {
+	// Addr is the host:port of the SMTP server. STARTTLS is used when the
+	// server offers it, credentials are only sent when Username is set.
+	Addr     string
+	From     string
+	Username string
+	Password string
+	// Timeout limits a whole SMTP session.
+	Timeout time.Duration
+	// QueueSize bounds the assignment emails waiting to be sent.
+	QueueSize int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:   10 * time.Second,
+		QueueSize: 256,
+	}
+}
+
+type assignmentData struct {
+	Reviewer         domain.User
+	Author           domain.User
+	PullRequest      domain.PullRequest
+	PreviousReviewer domain.User
+}
+
+type digestReview struct {
+	domain.PullRequestShort
+	Author domain.User
+}
+
+type digestData struct {
+	Reviewer domain.User
+	Reviews  []digestReview
+}
+
+// Sender emails reviewers about their assignments and sends the daily digest
+// of open reviews. Users without an email address, inactive and deleted users
+// get no email.
+type Sender struct {
+	userRepo repository.UserRepository
+	prRepo   repository.PullRequestRepository
+	cfg      Config
+	logger   *slog.Logger
+}
+
+func NewSender(ur repository.UserRepository, prr repository.PullRequestRepository, cfg Config, logger *slog.Logger) *Sender {
+	return &Sender{
+		userRepo: ur,
+		prRepo:   prr,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// Send emails the reviewers of an assignment or reassignment, other
+// notifications are ignored.
+func (s *Sender) Send(ctx context.Context, n service.Notification) error {
+	if n.Kind != domain.EventReviewerAssigned && n.Kind != domain.EventReviewerReassigned {
+		return nil
+	}
+
+	data := assignmentData{
+		PullRequest: n.PullRequest,
+		Author:      s.user(ctx, n.PullRequest.AuthorID),
+	}
+	if n.PreviousReviewer != "" {
+		data.PreviousReviewer = s.user(ctx, n.PreviousReviewer)
+	}
+
+	var errs []error
+	for _, reviewerID := range n.Reviewers {
+		data.Reviewer = s.user(ctx, reviewerID)
+		if !wantsEmail(data.Reviewer) {
+			continue
+		}
+
+		msg, err := render(data.Reviewer.Email, fmt.Sprintf("Review requested: %s", n.PullRequest.Name), "assignment", data)
+		if err == nil {
+			err = s.send(ctx, msg, time.Now())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("email to %s: %w", reviewerID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SendDigests emails every active user with open reviews a list of them and
+// returns how many digests were sent. A failed digest does not stop the
+// others.
+func (s *Sender) SendDigests(ctx context.Context, now time.Time) (int, error) {
+	active := true
+	users, err := s.userRepo.List(ctx, domain.UserFilter{IsActive: &active})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	authors := map[domain.UserID]domain.User{}
+	var errs []error
+	for _, user := range users {
+		if !wantsEmail(user) {
+			continue
+		}
+
+		reviews, err := s.prRepo.PullRequestsByReviewer(ctx, user.ID, domain.PullRequestFilter{
+			Statuses: []domain.PRStatus{domain.StatusOpen},
+			Order:    domain.SortOldestFirst,
+		})
+		if err != nil {
+			return sent, err
+		}
+		if len(reviews) == 0 {
+			continue
+		}
+
+		data := digestData{Reviewer: user, Reviews: make([]digestReview, len(reviews))}
+		for i, review := range reviews {
+			author, ok := authors[review.AuthorID]
+			if !ok {
+				author = s.user(ctx, review.AuthorID)
+				authors[review.AuthorID] = author
+			}
+			data.Reviews[i] = digestReview{PullRequestShort: review, Author: author}
+		}
+
+		msg, err := render(user.Email, fmt.Sprintf("%d open review(s) waiting for you", len(reviews)), "digest", data)
+		if err == nil {
+			err = s.send(ctx, msg, now)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest to %s: %w", user.ID, err))
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// ParseDigestTime parses a time of day such as "09:00" into the offset from
+// midnight RunDigests expects.
+func ParseDigestTime(value string) (time.Duration, error) {
+	at, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid digest time %q, expected HH:MM", value)
+	}
+
+	return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute, nil
+}
+
+// RunDigests sends the digests every day at the given time of day, in UTC,
+// until the context is cancelled.
+func (s *Sender) RunDigests(ctx context.Context, at time.Duration) {
+	for {
+		now := time.Now().UTC()
+		next := now.Truncate(24 * time.Hour).Add(at)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			sent, err := s.SendDigests(ctx, now)
+			if err != nil && ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "email digest failed", "sent", sent, "error", err)
+			}
+		}
+	}
+}
+
+func (s *Sender) user(ctx context.Context, userID domain.UserID) domain.User {
+	user, err := s.userRepo.UserByID(ctx, userID)
+	if err != nil {
+		return domain.User{ID: userID, Username: string(userID)}
+	}
+
+	return user
+}
+
+func wantsEmail(user domain.User) bool {
+	return user.Email != "" && user.IsActive && !user.IsDeleted()
+}
+
+// render executes the text and the HTML template of the given name.
+func render(to string, subject string, name string, data any) (message, error) {
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return message{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return message{}, err
+	}
+
+	return message{to: to, subject: subject, text: text.String(), html: html.String()}, nil
+}
+
+// send delivers the message in one SMTP session bounded by the timeout.
+func (s *Sender) send(ctx context.Context, msg message, now time.Time) error {
+	body, err := msg.bytes(s.cfg.From, now)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: s.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.cfg.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(s.cfg.Addr)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
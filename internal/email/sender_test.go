@@ -0,0 +1,280 @@
+package email_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/email"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedMail struct {
+	from    string
+	to      []string
+	subject string
+	// parts maps the content type of each alternative to its decoded body.
+	parts map[string]string
+}
+
+// smtpStub is an in-process SMTP server that accepts every message and keeps
+// it. It offers no extensions, so neither STARTTLS nor AUTH are used.
+type smtpStub struct {
+	listener net.Listener
+
+	mu    sync.Mutex
+	mails []receivedMail
+}
+
+func startSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	stub := &smtpStub{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+
+	return stub
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var from string
+	var to []string
+
+	_ = tp.PrintfLine("220 stub ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO", "NOOP", "RSET":
+			_ = tp.PrintfLine("250 stub")
+		case "MAIL":
+			from = addressOf(line)
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			to = append(to, addressOf(line))
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			err = s.store(from, to, strings.Join(lines, "\r\n"))
+			from, to = "", nil
+			if err != nil {
+				_ = tp.PrintfLine("554 %v", err)
+				continue
+			}
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func addressOf(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+
+	return line[start+1 : end]
+}
+
+// store parses the message, a malformed one is rejected so that the sender
+// reports it.
+func (s *smtpStub) store(from string, to []string, data string) error {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[mediaType] = string(body)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mails = append(s.mails, receivedMail{from: from, to: to, subject: subject, parts: parts})
+
+	return nil
+}
+
+func (s *smtpStub) received() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]receivedMail(nil), s.mails...)
+}
+
+type testEmailEnviroment struct {
+	ctx    context.Context
+	prRepo *inmemory.PullRequestRepo
+	stub   *smtpStub
+	sender *email.Sender
+}
+
+func setupEmailTest(t *testing.T) testEmailEnviroment {
+	t.Helper()
+
+	ctx := context.Background()
+	storage, _ := inmemory.NewStorage()
+	userRepo := inmemory.NewUserRepo(storage)
+	prRepo := inmemory.NewPullRequestRepo(storage)
+	stub := startSMTPStub(t)
+
+	require.NoError(t, userRepo.Create(ctx, domain.User{ID: "u1", Username: "alice", Email: "alice@example.com", IsActive: true}))
+	require.NoError(t, userRepo.Create(ctx, domain.User{ID: "u2", Username: "bob", Email: "bob@example.com", IsActive: true}))
+	require.NoError(t, userRepo.Create(ctx, domain.User{ID: "u3", Username: "carol", IsActive: true}))
+	require.NoError(t, userRepo.Create(ctx, domain.User{ID: "u4", Username: "dave", Email: "dave@example.com", IsActive: false}))
+
+	cfg := email.Config{Addr: stub.listener.Addr().String(), From: "reviews@example.com", Timeout: 5 * time.Second}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return testEmailEnviroment{
+		ctx:    ctx,
+		prRepo: prRepo,
+		stub:   stub,
+		sender: email.NewSender(userRepo, prRepo, cfg, logger),
+	}
+}
+
+func TestSendEmailsAssignedReviewers(t *testing.T) {
+	e := setupEmailTest(t)
+
+	pr := domain.PullRequest{ID: "pr-1", Name: "Escape <b> tags", AuthorID: "u1", TeamName: "backend"}
+	err := e.sender.Send(e.ctx, service.Notification{
+		Kind:        domain.EventReviewerAssigned,
+		PullRequest: pr,
+		Reviewers:   []domain.UserID{"u2", "u3", "u4"},
+	})
+	require.NoError(t, err)
+
+	mails := e.stub.received()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "reviews@example.com", mails[0].from)
+	assert.Equal(t, []string{"bob@example.com"}, mails[0].to)
+	assert.Equal(t, "Review requested: Escape <b> tags", mails[0].subject)
+	assert.Contains(t, mails[0].parts["text/plain"], `you were asked to review "Escape <b> tags" (pr-1).`)
+	assert.Contains(t, mails[0].parts["text/plain"], "Author: alice")
+	assert.Contains(t, mails[0].parts["text/html"], "<strong>Escape &lt;b&gt; tags</strong>")
+}
+
+func TestSendEmailsReviewerTakingOver(t *testing.T) {
+	e := setupEmailTest(t)
+
+	err := e.sender.Send(e.ctx, service.Notification{
+		Kind:             domain.EventReviewerReassigned,
+		PullRequest:      domain.PullRequest{ID: "pr-1", Name: "Add search", AuthorID: "u1"},
+		Reviewers:        []domain.UserID{"u2"},
+		PreviousReviewer: "u3",
+	})
+	require.NoError(t, err)
+
+	mails := e.stub.received()
+	require.Len(t, mails, 1)
+	assert.Contains(t, mails[0].parts["text/plain"], `you took over the review of "Add search" (pr-1) from carol.`)
+}
+
+func TestSendIgnoresMerges(t *testing.T) {
+	e := setupEmailTest(t)
+
+	err := e.sender.Send(e.ctx, service.Notification{
+		Kind:        domain.EventMerged,
+		PullRequest: domain.PullRequest{ID: "pr-1", Name: "Add search", AuthorID: "u1"},
+		Reviewers:   []domain.UserID{"u2"},
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, e.stub.received())
+}
+
+func TestSendDigestsBundlesOpenReviews(t *testing.T) {
+	e := setupEmailTest(t)
+
+	createdAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, pr := range []domain.PullRequest{
+		{ID: "pr-1", Name: "Add search", AuthorID: "u1", Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{"u2"}, CreatedAt: createdAt},
+		{ID: "pr-2", Name: "Fix typo", AuthorID: "u1", Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{"u2", "u4"}, CreatedAt: createdAt.Add(time.Hour)},
+		{ID: "pr-3", Name: "Drop cache", AuthorID: "u1", Status: domain.StatusMerged, AssignedReviewers: []domain.UserID{"u2"}, CreatedAt: createdAt},
+		{ID: "pr-4", Name: "Bump deps", AuthorID: "u2", Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{"u3"}, CreatedAt: createdAt},
+	} {
+		_, err := e.prRepo.Create(e.ctx, pr)
+		require.NoError(t, err)
+	}
+
+	sent, err := e.sender.SendDigests(e.ctx, createdAt.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	mails := e.stub.received()
+	require.Len(t, mails, 1)
+	assert.Equal(t, []string{"bob@example.com"}, mails[0].to)
+	assert.Equal(t, "2 open review(s) waiting for you", mails[0].subject)
+
+	text := mails[0].parts["text/plain"]
+	assert.Contains(t, text, `- "Add search" (pr-1) by alice, open since 2026-05-01`)
+	assert.Contains(t, text, `- "Fix typo" (pr-2) by alice, open since 2026-05-01`)
+	assert.NotContains(t, text, "pr-3")
+	assert.Less(t, strings.Index(text, "pr-1"), strings.Index(text, "pr-2"))
+	assert.Contains(t, mails[0].parts["text/html"], "<li><strong>Add search</strong> (pr-1)")
+}
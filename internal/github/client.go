@@ -0,0 +1,106 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxErrorBodyBytes = 512
+
+type Config struct {
+	// APIURL is https://api.github.com or the API of a GitHub Enterprise
+	// Server, e.g. https://github.example.com/api/v3.
+	APIURL string
+	Token  string
+	// Timeout limits a single API call.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		APIURL:  "https://api.github.com",
+		Timeout: 10 * time.Second,
+	}
+}
+
+// APIError is a response of the GitHub API outside of 2xx.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github responded %d: %s", e.StatusCode, e.Message)
+}
+
+// Retryable tells whether the same call may succeed later.
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client requests reviews on GitHub pull requests through the REST API.
+type Client struct {
+	client *http.Client
+	cfg    Config
+}
+
+func NewClient(client *http.Client, cfg Config) *Client {
+	return &Client{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+type reviewersRequest struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+func (c *Client) RequestReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	return c.call(ctx, http.MethodPost, requestedReviewersPath(repository, number), reviewersRequest{Reviewers: logins})
+}
+
+func (c *Client) RemoveReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	return c.call(ctx, http.MethodDelete, requestedReviewersPath(repository, number), reviewersRequest{Reviewers: logins})
+}
+
+func requestedReviewersPath(repository string, number int) string {
+	return fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repository, number)
+}
+
+func (c *Client) call(ctx context.Context, method, path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.APIURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &APIError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(snippet))}
+	}
+
+	return nil
+}
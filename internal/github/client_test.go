@@ -0,0 +1,89 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/github"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiCall struct {
+	Method    string
+	Path      string
+	Auth      string
+	Reviewers []string
+}
+
+// fakeAPI records the calls of the requested reviewers endpoint.
+type fakeAPI struct {
+	mu     sync.Mutex
+	status int
+	calls  []apiCall
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reviewers []string `json:"reviewers"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, apiCall{Method: r.Method, Path: r.URL.Path, Auth: r.Header.Get("Authorization"), Reviewers: body.Reviewers})
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		_, _ = w.Write([]byte(`{"message":"Reviews may only be requested from collaborators."}`))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func newClient(t *testing.T, api *fakeAPI) *github.Client {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	cfg := github.DefaultConfig()
+	cfg.APIURL = server.URL + "/"
+	cfg.Token = "ghp_test"
+
+	return github.NewClient(server.Client(), cfg)
+}
+
+func TestClientRequestsAndRemovesReviewers(t *testing.T) {
+	api := &fakeAPI{}
+	client := newClient(t, api)
+	ctx := context.Background()
+
+	require.NoError(t, client.RequestReviewers(ctx, "acme/api", 42, []string{"octocat", "hubot"}))
+	require.NoError(t, client.RemoveReviewers(ctx, "acme/api", 42, []string{"hubot"}))
+
+	assert.Equal(t, []apiCall{
+		{Method: http.MethodPost, Path: "/repos/acme/api/pulls/42/requested_reviewers", Auth: "Bearer ghp_test", Reviewers: []string{"octocat", "hubot"}},
+		{Method: http.MethodDelete, Path: "/repos/acme/api/pulls/42/requested_reviewers", Auth: "Bearer ghp_test", Reviewers: []string{"hubot"}},
+	}, api.calls)
+}
+
+func TestClientErrorsTellWhetherToRetry(t *testing.T) {
+	ctx := context.Background()
+
+	api := &fakeAPI{status: http.StatusUnprocessableEntity}
+	err := newClient(t, api).RequestReviewers(ctx, "acme/api", 42, []string{"stranger"})
+	var apiErr *github.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.False(t, apiErr.Retryable())
+
+	api = &fakeAPI{status: http.StatusBadGateway}
+	err = newClient(t, api).RequestReviewers(ctx, "acme/api", 42, []string{"octocat"})
+	require.ErrorAs(t, err, &apiErr)
+	assert.True(t, apiErr.Retryable())
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+const (
+	SignatureHeader = "X-Hub-Signature-256"
+	EventHeader     = "X-GitHub-Event"
+
+	signaturePrefix = "sha256="
+)
+
+// VerifySignature checks the X-Hub-Signature-256 header: the hex
+// HMAC-SHA256 of the body keyed with the webhook secret. An empty secret
+// accepts nothing.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+type User struct {
+	Login string `json:"login"`
+}
+
+type Repository struct {
+	FullName string `json:"full_name"`
+}
+
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Draft  bool   `json:"draft"`
+	Merged bool   `json:"merged"`
+	User   User   `json:"user"`
+}
+
+// PullRequestEvent is the payload of the pull_request event, only the
+// fields the service uses are decoded.
+type PullRequestEvent struct {
+	Action      string      `json:"action"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  Repository  `json:"repository"`
+	Sender      User        `json:"sender"`
+}
+
+func (e PullRequestEvent) VCSPullRequest() service.VCSPullRequest {
+	return service.VCSPullRequest{
+		Provider:   domain.IdentityGitHub,
+		Repository: e.Repository.FullName,
+		Number:     e.PullRequest.Number,
+		Title:      e.PullRequest.Title,
+		Author:     e.PullRequest.User.Login,
+		Draft:      e.PullRequest.Draft,
+	}
+}
@@ -0,0 +1,27 @@
+package github_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"pr-reviewer-service/internal/github"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+
+	assert.True(t, github.VerifySignature("s3cret", body, sign("s3cret", body)))
+	assert.False(t, github.VerifySignature("s3cret", body, sign("other", body)))
+	assert.False(t, github.VerifySignature("s3cret", []byte(`{"action":"closed"}`), sign("s3cret", body)))
+	assert.False(t, github.VerifySignature("s3cret", body, ""))
+	assert.False(t, github.VerifySignature("", body, sign("", body)))
+}
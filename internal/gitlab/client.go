@@ -0,0 +1,201 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxErrorBodyBytes = 512
+
+type Config struct {
+	// URL is the address of the GitLab instance, e.g. https://gitlab.com.
+	URL   string
+	Token string
+	// Timeout limits a single API call.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		URL:     "https://gitlab.com",
+		Timeout: 10 * time.Second,
+	}
+}
+
+// APIError is a response of the GitLab API outside of 2xx.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gitlab responded %d: %s", e.StatusCode, e.Message)
+}
+
+// Retryable tells whether the same call may succeed later.
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client requests reviews on GitLab merge requests through the REST API.
+// GitLab replaces all reviewers at once and by user ID, so every change
+// reads the current reviewers and looks the usernames up first.
+type Client struct {
+	client *http.Client
+	cfg    Config
+}
+
+func NewClient(client *http.Client, cfg Config) *Client {
+	return &Client{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+type apiUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type apiMergeRequest struct {
+	Reviewers []apiUser `json:"reviewers"`
+}
+
+type updateReviewersRequest struct {
+	ReviewerIDs []int64 `json:"reviewer_ids"`
+}
+
+func (c *Client) RequestReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	reviewerIDs, err := c.reviewerIDs(ctx, repository, number)
+	if err != nil {
+		return err
+	}
+
+	requested := len(reviewerIDs)
+	for _, login := range logins {
+		userID, err := c.userID(ctx, login)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(reviewerIDs, userID) {
+			reviewerIDs = append(reviewerIDs, userID)
+		}
+	}
+
+	if len(reviewerIDs) == requested {
+		return nil
+	}
+
+	return c.call(ctx, http.MethodPut, mergeRequestPath(repository, number), updateReviewersRequest{ReviewerIDs: reviewerIDs}, nil)
+}
+
+func (c *Client) RemoveReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	var mr apiMergeRequest
+	if err := c.call(ctx, http.MethodGet, mergeRequestPath(repository, number), nil, &mr); err != nil {
+		return err
+	}
+
+	reviewerIDs := []int64{}
+	for _, reviewer := range mr.Reviewers {
+		if !slices.ContainsFunc(logins, func(login string) bool { return strings.EqualFold(login, reviewer.Username) }) {
+			reviewerIDs = append(reviewerIDs, reviewer.ID)
+		}
+	}
+
+	if len(reviewerIDs) == len(mr.Reviewers) {
+		return nil
+	}
+
+	return c.call(ctx, http.MethodPut, mergeRequestPath(repository, number), updateReviewersRequest{ReviewerIDs: reviewerIDs}, nil)
+}
+
+func (c *Client) reviewerIDs(ctx context.Context, repository string, number int) ([]int64, error) {
+	var mr apiMergeRequest
+	if err := c.call(ctx, http.MethodGet, mergeRequestPath(repository, number), nil, &mr); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(mr.Reviewers))
+	for i, reviewer := range mr.Reviewers {
+		ids[i] = reviewer.ID
+	}
+
+	return ids, nil
+}
+
+// Login returns the username of the GitLab user with the ID.
+func (c *Client) Login(ctx context.Context, accountID int64) (string, error) {
+	var user apiUser
+	if err := c.call(ctx, http.MethodGet, fmt.Sprintf("/users/%d", accountID), nil, &user); err != nil {
+		return "", err
+	}
+
+	return user.Username, nil
+}
+
+func (c *Client) userID(ctx context.Context, username string) (int64, error) {
+	var users []apiUser
+	if err := c.call(ctx, http.MethodGet, "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+		return 0, err
+	}
+
+	if len(users) == 0 {
+		return 0, &APIError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("no user %q", username)}
+	}
+
+	return users[0].ID, nil
+}
+
+// mergeRequestPath addresses the project by its path, which GitLab takes
+// URL-encoded as a single segment.
+func mergeRequestPath(repository string, number int) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%d", url.PathEscape(repository), number)
+}
+
+func (c *Client) call(ctx context.Context, method, path string, payload, result any) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.URL, "/")+"/api/v4"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.cfg.Token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &APIError{StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(snippet))}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,131 @@
+package gitlab_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/gitlab"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI keeps the reviewers of a single merge request of platform/api.
+type fakeAPI struct {
+	mu        sync.Mutex
+	users     map[string]int64
+	reviewers []int64
+	updates   int
+}
+
+const mergeRequestPath = "/api/v4/projects/platform%2Fapi/merge_requests/7"
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("PRIVATE-TOKEN") != "glpat-test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v4/users":
+		users := []map[string]any{}
+		username := r.URL.Query().Get("username")
+		if id, ok := f.users[username]; ok {
+			users = append(users, map[string]any{"id": id, "username": username})
+		}
+		_ = json.NewEncoder(w).Encode(users)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v4/users/"):
+		for username, id := range f.users {
+			if r.URL.Path == fmt.Sprintf("/api/v4/users/%d", id) {
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "username": username})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodGet && r.URL.EscapedPath() == mergeRequestPath:
+		reviewers := []map[string]any{}
+		for username, id := range f.users {
+			for _, reviewerID := range f.reviewers {
+				if reviewerID == id {
+					reviewers = append(reviewers, map[string]any{"id": id, "username": username})
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"iid": 7, "reviewers": reviewers})
+	case r.Method == http.MethodPut && r.URL.EscapedPath() == mergeRequestPath:
+		var body struct {
+			ReviewerIDs []int64 `json:"reviewer_ids"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.reviewers = body.ReviewerIDs
+		f.updates++
+		_ = json.NewEncoder(w).Encode(map[string]any{"iid": 7})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newClient(t *testing.T, api *fakeAPI) *gitlab.Client {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	cfg := gitlab.DefaultConfig()
+	cfg.URL = server.URL
+	cfg.Token = "glpat-test"
+
+	return gitlab.NewClient(server.Client(), cfg)
+}
+
+func TestClientKeepsOtherReviewers(t *testing.T) {
+	api := &fakeAPI{users: map[string]int64{"jdoe": 1, "asmith": 2, "bwhite": 3}, reviewers: []int64{3}}
+	client := newClient(t, api)
+	ctx := context.Background()
+
+	require.NoError(t, client.RequestReviewers(ctx, "platform/api", 7, []string{"jdoe", "asmith"}))
+	assert.Equal(t, []int64{3, 1, 2}, api.reviewers)
+
+	// requesting again changes nothing
+	require.NoError(t, client.RequestReviewers(ctx, "platform/api", 7, []string{"jdoe"}))
+	assert.Equal(t, 1, api.updates)
+
+	require.NoError(t, client.RemoveReviewers(ctx, "platform/api", 7, []string{"JDoe"}))
+	assert.ElementsMatch(t, []int64{3, 2}, api.reviewers)
+
+	require.NoError(t, client.RemoveReviewers(ctx, "platform/api", 7, []string{"jdoe"}))
+	assert.Equal(t, 2, api.updates)
+}
+
+func TestClientFailsForUnknownUser(t *testing.T) {
+	api := &fakeAPI{users: map[string]int64{}}
+
+	err := newClient(t, api).RequestReviewers(context.Background(), "platform/api", 7, []string{"ghost"})
+
+	var apiErr *gitlab.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.False(t, apiErr.Retryable())
+	assert.Zero(t, api.updates)
+}
+
+func TestClientLooksUpLogins(t *testing.T) {
+	api := &fakeAPI{users: map[string]int64{"jdoe": 1}}
+	client := newClient(t, api)
+
+	login, err := client.Login(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe", login)
+
+	_, err = client.Login(context.Background(), 2)
+	var apiErr *gitlab.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.False(t, apiErr.Retryable())
+}
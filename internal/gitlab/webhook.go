@@ -0,0 +1,96 @@
+package gitlab
+
+import (
+	"crypto/subtle"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+const (
+	TokenHeader = "X-Gitlab-Token"
+	EventHeader = "X-Gitlab-Event"
+
+	MergeRequestHook = "Merge Request Hook"
+)
+
+// VerifyToken compares the X-Gitlab-Token header with the secret token of
+// the webhook in constant time. An empty secret accepts nothing.
+func VerifyToken(secret, token string) bool {
+	if secret == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type Project struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type MergeRequest struct {
+	IID      int    `json:"iid"`
+	Title    string `json:"title"`
+	Action   string `json:"action"`
+	AuthorID int64  `json:"author_id"`
+	Draft    bool   `json:"draft"`
+	// WorkInProgress is what GitLab before 15.0 sends instead of Draft.
+	WorkInProgress bool `json:"work_in_progress"`
+}
+
+type BoolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+type Changes struct {
+	Draft          *BoolChange `json:"draft"`
+	WorkInProgress *BoolChange `json:"work_in_progress"`
+}
+
+// MergeRequestEvent is the payload of the Merge Request Hook, only the
+// fields the service uses are decoded. User is whoever triggered the event,
+// the payload carries only the ID of the author. Changes hold only the
+// changes the service acts on, other updates like new reviewers, a new
+// target branch or title are ignored.
+type MergeRequestEvent struct {
+	ObjectKind       string       `json:"object_kind"`
+	User             User         `json:"user"`
+	Project          Project      `json:"project"`
+	ObjectAttributes MergeRequest `json:"object_attributes"`
+	Changes          Changes      `json:"changes"`
+}
+
+// MarkedReady tells whether an update took the merge request out of draft.
+func (e MergeRequestEvent) MarkedReady() bool {
+	for _, change := range []*BoolChange{e.Changes.Draft, e.Changes.WorkInProgress} {
+		if change != nil && change.Previous && !change.Current {
+			return true
+		}
+	}
+	return false
+}
+
+// VCSPullRequest names the author by login when the author triggered the
+// event, otherwise only by account ID, which has to be looked up.
+func (e MergeRequestEvent) VCSPullRequest() service.VCSPullRequest {
+	vpr := service.VCSPullRequest{
+		Provider:   domain.IdentityGitLab,
+		Repository: e.Project.PathWithNamespace,
+		Number:     e.ObjectAttributes.IID,
+		Title:      e.ObjectAttributes.Title,
+		Draft:      e.ObjectAttributes.Draft || e.ObjectAttributes.WorkInProgress,
+	}
+
+	if e.ObjectAttributes.AuthorID == e.User.ID {
+		vpr.Author = e.User.Username
+	} else {
+		vpr.AuthorAccountID = e.ObjectAttributes.AuthorID
+	}
+
+	return vpr
+}
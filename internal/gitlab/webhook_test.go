@@ -0,0 +1,83 @@
+package gitlab_test
+
+import (
+	"encoding/json"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/gitlab"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyToken(t *testing.T) {
+	assert.True(t, gitlab.VerifyToken("s3cret", "s3cret"))
+	assert.False(t, gitlab.VerifyToken("s3cret", "other"))
+	assert.False(t, gitlab.VerifyToken("s3cret", ""))
+	assert.False(t, gitlab.VerifyToken("", ""))
+}
+
+func TestMergeRequestEventMarkedReady(t *testing.T) {
+	payload := `{
+		"object_kind": "merge_request",
+		"user": {"username": "jdoe"},
+		"project": {"path_with_namespace": "platform/api"},
+		"object_attributes": {"iid": 7, "title": "Add search", "action": "update", "draft": false},
+		"changes": {"draft": {"previous": true, "current": false}}
+	}`
+
+	var event gitlab.MergeRequestEvent
+	require.NoError(t, json.Unmarshal([]byte(payload), &event))
+
+	assert.True(t, event.MarkedReady())
+	assert.Equal(t, domain.PullRequestID("gitlab:platform/api#7"), event.VCSPullRequest().ID())
+	assert.Equal(t, "jdoe", event.VCSPullRequest().Author)
+	assert.False(t, event.VCSPullRequest().Draft)
+
+	event.Changes.Draft = &gitlab.BoolChange{Previous: false, Current: true}
+	assert.False(t, event.MarkedReady())
+
+	event.Changes = gitlab.Changes{WorkInProgress: &gitlab.BoolChange{Previous: true, Current: false}}
+	assert.True(t, event.MarkedReady())
+}
+
+func TestMergeRequestEventAuthorIsNotWhoeverTriggeredIt(t *testing.T) {
+	payload := `{
+		"object_kind": "merge_request",
+		"user": {"id": 2, "username": "asmith"},
+		"project": {"path_with_namespace": "platform/api"},
+		"object_attributes": {"iid": 7, "title": "Add search", "action": "update", "author_id": 1},
+		"changes": {"draft": {"previous": true, "current": false}}
+	}`
+
+	var event gitlab.MergeRequestEvent
+	require.NoError(t, json.Unmarshal([]byte(payload), &event))
+
+	vpr := event.VCSPullRequest()
+	assert.Empty(t, vpr.Author)
+	assert.Equal(t, int64(1), vpr.AuthorAccountID)
+
+	event.User = gitlab.User{ID: 1, Username: "jdoe"}
+	vpr = event.VCSPullRequest()
+	assert.Equal(t, "jdoe", vpr.Author)
+	assert.Zero(t, vpr.AuthorAccountID)
+}
+
+func TestMergeRequestEventIgnoresOtherUpdates(t *testing.T) {
+	payload := `{
+		"object_kind": "merge_request",
+		"user": {"id": 1, "username": "jdoe"},
+		"project": {"path_with_namespace": "platform/api"},
+		"object_attributes": {"iid": 7, "title": "Add search", "action": "update", "author_id": 1},
+		"changes": {
+			"reviewers": {"previous": [], "current": [{"id": 2, "username": "asmith"}]},
+			"target_branch": {"previous": "main", "current": "release"},
+			"title": {"previous": "Draft: Add search", "current": "Add search"}
+		}
+	}`
+
+	var event gitlab.MergeRequestEvent
+	require.NoError(t, json.Unmarshal([]byte(payload), &event))
+
+	assert.False(t, event.MarkedReady())
+}
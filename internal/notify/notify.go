@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"pr-reviewer-service/internal/service"
+	"sync"
+	"time"
+)
+
+// Sender delivers a single notification synchronously.
+type Sender interface {
+	Send(ctx context.Context, n service.Notification) error
+}
+
+// SenderFunc lets a function be a Sender.
+type SenderFunc func(ctx context.Context, n service.Notification) error
+
+func (f SenderFunc) Send(ctx context.Context, n service.Notification) error {
+	return f(ctx, n)
+}
+
+// Notifiers hands every notification to each of the notifiers.
+type Notifiers []service.Notifier
+
+func (ns Notifiers) Notify(ctx context.Context, n service.Notification) {
+	for _, notifier := range ns {
+		notifier.Notify(ctx, n)
+	}
+}
+
+// Queue lets a sender deliver notifications in the background, so that
+// requests never wait for it. A full queue drops notifications and failed
+// deliveries are only logged, unless the queue retries them.
+type Queue struct {
+	name   string
+	sender Sender
+	retry  Retry
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan queued
+	done   chan struct{}
+}
+
+type queued struct {
+	notification service.Notification
+	attempt      int
+}
+
+// NewQueue creates a queue holding up to size notifications, name tells the
+// queues apart in the logs.
+func NewQueue(name string, sender Sender, size int, logger *slog.Logger) *Queue {
+	return NewRetryingQueue(name, sender, size, Retry{Attempts: 1}, logger)
+}
+
+// NewRetryingQueue creates a queue that puts failed notifications back once
+// their backoff is over, retries still pending on Close are dropped.
+func NewRetryingQueue(name string, sender Sender, size int, retry Retry, logger *slog.Logger) *Queue {
+	return &Queue{
+		name:   name,
+		sender: sender,
+		retry:  retry,
+		logger: logger,
+		queue:  make(chan queued, size),
+		done:   make(chan struct{}),
+	}
+}
+
+func (q *Queue) Notify(ctx context.Context, n service.Notification) {
+	q.enqueue(ctx, queued{notification: n, attempt: 1})
+}
+
+func (q *Queue) enqueue(ctx context.Context, item queued) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	n := item.notification
+	if q.closed {
+		if item.attempt > 1 {
+			q.logger.WarnContext(ctx, "notification retry dropped, queue is closed",
+				"queue", q.name,
+				"event", n.Kind,
+				"pull_request_id", n.PullRequest.ID,
+			)
+		}
+		return
+	}
+
+	select {
+	case q.queue <- item:
+	default:
+		q.logger.WarnContext(ctx, "notification dropped, queue is full",
+			"queue", q.name,
+			"event", n.Kind,
+			"pull_request_id", n.PullRequest.ID,
+		)
+	}
+}
+
+// Run sends the queued notifications until the queue is closed and drained.
+func (q *Queue) Run(ctx context.Context) {
+	defer close(q.done)
+
+	for item := range q.queue {
+		n := item.notification
+		err := q.sender.Send(ctx, n)
+		if err == nil {
+			continue
+		}
+
+		if q.retry.allows(item.attempt, err) {
+			delay := q.retry.delay(item.attempt)
+			q.logger.WarnContext(ctx, "notification failed, retrying",
+				"queue", q.name,
+				"event", n.Kind,
+				"pull_request_id", n.PullRequest.ID,
+				"attempt", item.attempt,
+				"retry_in", delay,
+				"error", err,
+			)
+
+			next := queued{notification: n, attempt: item.attempt + 1}
+			time.AfterFunc(delay, func() { q.enqueue(ctx, next) })
+			continue
+		}
+
+		q.logger.ErrorContext(ctx, "notification failed",
+			"queue", q.name,
+			"event", n.Kind,
+			"pull_request_id", n.PullRequest.ID,
+			"error", err,
+		)
+	}
+}
+
+// Close stops accepting notifications and waits for Run to send the queued
+// ones or for the context to end.
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,180 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/internal/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []domain.PullRequestID
+	err  error
+}
+
+func (s *recordingSender) Send(_ context.Context, n service.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, n.PullRequest.ID)
+	return s.err
+}
+
+func notification(prID domain.PullRequestID) service.Notification {
+	return service.Notification{
+		Kind:        domain.EventReviewerAssigned,
+		PullRequest: domain.PullRequest{ID: prID},
+	}
+}
+
+func newQueue(sender notify.Sender, size int) *notify.Queue {
+	return notify.NewQueue("test", sender, size, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestQueueCloseDrainsQueue(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingSender{err: errors.New("unreachable")}
+	queue := newQueue(sender, 10)
+
+	queue.Notify(ctx, notification("pr-1"))
+	queue.Notify(ctx, notification("pr-2"))
+	go queue.Run(ctx)
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, queue.Close(closeCtx))
+
+	// notifications after Close are dropped
+	queue.Notify(ctx, notification("pr-3"))
+
+	assert.Equal(t, []domain.PullRequestID{"pr-1", "pr-2"}, sender.sent)
+}
+
+func TestQueueDropsWhenFull(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingSender{}
+	queue := newQueue(sender, 1)
+
+	queue.Notify(ctx, notification("pr-1"))
+	queue.Notify(ctx, notification("pr-2"))
+	go queue.Run(ctx)
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, queue.Close(closeCtx))
+
+	assert.Equal(t, []domain.PullRequestID{"pr-1"}, sender.sent)
+}
+
+func TestNotifiersNotifyEveryNotifier(t *testing.T) {
+	ctx := context.Background()
+	first, second := &recordingSender{}, &recordingSender{}
+	firstQueue, secondQueue := newQueue(first, 1), newQueue(second, 1)
+
+	notify.Notifiers{firstQueue, secondQueue}.Notify(ctx, notification("pr-1"))
+	go firstQueue.Run(ctx)
+	go secondQueue.Run(ctx)
+	require.NoError(t, firstQueue.Close(ctx))
+	require.NoError(t, secondQueue.Close(ctx))
+
+	assert.Equal(t, []domain.PullRequestID{"pr-1"}, first.sent)
+	assert.Equal(t, []domain.PullRequestID{"pr-1"}, second.sent)
+}
+
+type permanentError struct{}
+
+func (permanentError) Error() string   { return "rejected" }
+func (permanentError) Retryable() bool { return false }
+
+// flakySender fails the first sends of each pull request.
+type flakySender struct {
+	recordingSender
+	failures int
+	attempts map[domain.PullRequestID]int
+}
+
+func (s *flakySender) Send(ctx context.Context, n service.Notification) error {
+	_ = s.recordingSender.Send(ctx, n)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attempts == nil {
+		s.attempts = map[domain.PullRequestID]int{}
+	}
+	s.attempts[n.PullRequest.ID]++
+	if s.attempts[n.PullRequest.ID] <= s.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (s *recordingSender) sentIDs() []domain.PullRequestID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]domain.PullRequestID(nil), s.sent...)
+}
+
+func newRetryingQueue(sender notify.Sender, retry notify.Retry) *notify.Queue {
+	return notify.NewRetryingQueue("test", sender, 10, retry, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestRetryingQueueSendsUntilSuccess(t *testing.T) {
+	ctx := context.Background()
+	sender := &flakySender{failures: 2}
+	queue := newRetryingQueue(sender, notify.Retry{Attempts: 3, Backoff: time.Millisecond})
+	go queue.Run(ctx)
+	defer queue.Close(ctx)
+
+	queue.Notify(ctx, notification("pr-1"))
+
+	assert.Eventually(t, func() bool { return len(sender.sentIDs()) == 3 }, 5*time.Second, time.Millisecond)
+}
+
+func TestRetryingQueueSendsOthersWhileWaiting(t *testing.T) {
+	ctx := context.Background()
+	sender := &flakySender{failures: 1}
+	queue := newRetryingQueue(sender, notify.Retry{Attempts: 2, Backoff: time.Hour})
+	go queue.Run(ctx)
+
+	queue.Notify(ctx, notification("pr-1"))
+	queue.Notify(ctx, notification("pr-2"))
+
+	// the retry of pr-1 waits, pr-2 does not, the pending retries are
+	// dropped on Close
+	require.Eventually(t, func() bool { return len(sender.sentIDs()) == 2 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, queue.Close(ctx))
+	assert.Equal(t, []domain.PullRequestID{"pr-1", "pr-2"}, sender.sentIDs())
+}
+
+func TestRetryingQueueGivesUp(t *testing.T) {
+	ctx := context.Background()
+	sender := &flakySender{failures: 5}
+	queue := newRetryingQueue(sender, notify.Retry{Attempts: 3, Backoff: time.Millisecond})
+	go queue.Run(ctx)
+
+	queue.Notify(ctx, notification("pr-1"))
+	require.Eventually(t, func() bool { return len(sender.sentIDs()) == 3 }, 5*time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, queue.Close(ctx))
+	assert.Len(t, sender.sentIDs(), 3)
+
+	rejecting := &recordingSender{err: permanentError{}}
+	queue = newRetryingQueue(rejecting, notify.Retry{Attempts: 3, Backoff: time.Millisecond})
+	go queue.Run(ctx)
+
+	queue.Notify(ctx, notification("pr-1"))
+	require.NoError(t, queue.Close(ctx))
+	assert.Len(t, rejecting.sentIDs(), 1)
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"errors"
+	"time"
+)
+
+// Retry tells a queue to send again when the sender fails, Backoff after the
+// failed attempt and twice as long after each next one. The waiting happens
+// off the queue, which sends other notifications in the meantime. Errors
+// with a Retryable method returning false are given up on at once.
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// delay is how long to wait after the failed attempt.
+func (r Retry) delay(attempt int) time.Duration {
+	return r.Backoff << (attempt - 1)
+}
+
+func (r Retry) allows(attempt int, err error) bool {
+	return attempt < r.Attempts && retryable(err)
+}
+
+func retryable(err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	return true
+}
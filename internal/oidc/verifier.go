@@ -0,0 +1,380 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // hashes of the supported algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"pr-reviewer-service/internal/domain"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxKeySetBytes = 1 << 20
+
+type Config struct {
+	// JWKS is the path or the http(s) URL of the key set tokens are signed
+	// with.
+	JWKS string
+	// Issuer and Audience are checked against the iss and aud claims when
+	// they are set.
+	Issuer   string
+	Audience string
+	// UserIDClaim and RolesClaim are paths of claims like "sub" or
+	// "realm_access.roles", nested objects are separated by dots.
+	UserIDClaim string
+	RolesClaim  string
+	// Leeway allows for clock skew when checking exp and nbf.
+	Leeway time.Duration
+	// RefreshInterval limits how often a key set URL is fetched again for a
+	// key it didn't have, e.g. after the keys were rotated.
+	RefreshInterval time.Duration
+	// Timeout limits fetching the key set.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		UserIDClaim:     "sub",
+		RolesClaim:      "roles",
+		Leeway:          time.Minute,
+		RefreshInterval: 5 * time.Minute,
+		Timeout:         10 * time.Second,
+	}
+}
+
+// Verifier checks JWTs of signed-in users and maps their claims onto a
+// principal. Users without a known role in the roles claim are members.
+type Verifier struct {
+	client *http.Client
+	cfg    Config
+
+	mu        sync.Mutex
+	keys      []publicKey
+	fetchedAt time.Time
+}
+
+// NewVerifier loads the key set right away, so that a wrong path or URL is
+// noticed at startup.
+func NewVerifier(ctx context.Context, client *http.Client, cfg Config) (*Verifier, error) {
+	v := &Verifier{
+		client: client,
+		cfg:    cfg,
+	}
+
+	if err := v.loadKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature and the time, issuer and audience claims of
+// the token. Tokens that fail the checks are domain.ErrUnauthorized.
+func (v *Verifier) Verify(ctx context.Context, token string) (domain.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return domain.Principal{}, fmt.Errorf("%w: malformed token", domain.ErrUnauthorized)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: malformed token header", domain.ErrUnauthorized)
+	}
+
+	hash, ok := algorithmHashes[h.Alg]
+	if !ok {
+		return domain.Principal{}, fmt.Errorf("%w: unsupported algorithm %q", domain.ErrUnauthorized, h.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: malformed token signature", domain.ErrUnauthorized)
+	}
+
+	keys, err := v.keysFor(ctx, h)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	digest := hash.New()
+	digest.Write([]byte(parts[0] + "." + parts[1]))
+	hashed := digest.Sum(nil)
+
+	if !slices.ContainsFunc(keys, func(key publicKey) bool { return verifySignature(h.Alg, hash, key.Key, hashed, signature) }) {
+		return domain.Principal{}, fmt.Errorf("%w: invalid token signature", domain.ErrUnauthorized)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: malformed token claims", domain.ErrUnauthorized)
+	}
+
+	if err := v.checkClaims(claims, time.Now()); err != nil {
+		return domain.Principal{}, err
+	}
+
+	return v.principal(claims)
+}
+
+var algorithmHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+func verifySignature(alg string, hash crypto.Hash, key crypto.PublicKey, hashed, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, hash, hashed, signature) == nil
+		case "PS":
+			return rsa.VerifyPSS(key, hash, hashed, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if alg[:2] != "ES" || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, hashed, r, s)
+	}
+
+	return false
+}
+
+// keysFor returns the keys the token may be signed with. A key set URL is
+// fetched again when it has no such key, at most once per refresh interval.
+func (v *Verifier) keysFor(ctx context.Context, h header) ([]publicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys := matchingKeys(v.keys, h)
+	if len(keys) > 0 || !isURL(v.cfg.JWKS) || time.Since(v.fetchedAt) < v.cfg.RefreshInterval {
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("%w: unknown signing key %q", domain.ErrUnauthorized, h.Kid)
+		}
+		return keys, nil
+	}
+
+	if err := v.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	keys = matchingKeys(v.keys, h)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: unknown signing key %q", domain.ErrUnauthorized, h.Kid)
+	}
+
+	return keys, nil
+}
+
+func matchingKeys(keys []publicKey, h header) []publicKey {
+	matching := []publicKey{}
+	for _, key := range keys {
+		if h.Kid != "" && key.ID != h.Kid {
+			continue
+		}
+		if key.Alg != "" && key.Alg != h.Alg {
+			continue
+		}
+		matching = append(matching, key)
+	}
+
+	return matching
+}
+
+func (v *Verifier) loadKeys(ctx context.Context) error {
+	if isURL(v.cfg.JWKS) {
+		return v.fetchKeys(ctx)
+	}
+
+	data, err := os.ReadFile(v.cfg.JWKS)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return err
+	}
+	v.keys = keys
+
+	return nil
+}
+
+// fetchKeys replaces the keys with those at the key set URL, the caller
+// holds the lock or owns the verifier.
+func (v *Verifier) fetchKeys(ctx context.Context) error {
+	v.fetchedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, v.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKS, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySetBytes))
+	if err != nil {
+		return fmt.Errorf("fetching jwks: %w", err)
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return err
+	}
+	v.keys = keys
+
+	return nil
+}
+
+func (v *Verifier) checkClaims(claims map[string]any, now time.Time) error {
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("%w: token has no expiry", domain.ErrUnauthorized)
+	}
+	if now.After(exp.Add(v.cfg.Leeway)) {
+		return fmt.Errorf("%w: token expired", domain.ErrUnauthorized)
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.cfg.Leeway).Before(nbf) {
+		return fmt.Errorf("%w: token is not valid yet", domain.ErrUnauthorized)
+	}
+
+	if v.cfg.Issuer != "" && claims["iss"] != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected token issuer", domain.ErrUnauthorized)
+	}
+
+	if v.cfg.Audience != "" && !slices.Contains(stringsClaim(claims["aud"]), v.cfg.Audience) {
+		return fmt.Errorf("%w: unexpected token audience", domain.ErrUnauthorized)
+	}
+
+	return nil
+}
+
+func (v *Verifier) principal(claims map[string]any) (domain.Principal, error) {
+	userID, _ := claimAt(claims, v.cfg.UserIDClaim).(string)
+	if userID == "" {
+		return domain.Principal{}, fmt.Errorf("%w: token has no %q claim", domain.ErrUnauthorized, v.cfg.UserIDClaim)
+	}
+
+	roles := []domain.UserRole{}
+	for _, claimed := range stringsClaim(claimAt(claims, v.cfg.RolesClaim)) {
+		role := domain.UserRole(strings.ToUpper(claimed))
+		if slices.Contains(domain.UserRoles, role) && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		roles = append(roles, domain.RoleMember)
+	}
+
+	return domain.Principal{UserID: domain.UserID(userID), Roles: roles}, nil
+}
+
+func claimAt(claims map[string]any, path string) any {
+	var value any = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	return value
+}
+
+// stringsClaim reads claims that are a string or an array of strings, like
+// aud.
+func stringsClaim(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := []string{}
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func numericDate(value any) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(seconds), 0), true
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("trailing data")
+	}
+
+	return nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
@@ -0,0 +1,270 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/oidc"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKey struct {
+	id  string
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newRSAKey(t *testing.T, id string) testKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testKey{id: id, rsa: key}
+}
+
+func newECKey(t *testing.T, id string) testKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testKey{id: id, ec: key}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func keySet(t *testing.T, keys ...testKey) []byte {
+	jwks := []map[string]string{}
+	for _, key := range keys {
+		if key.rsa != nil {
+			jwks = append(jwks, map[string]string{
+				"kty": "RSA", "kid": key.id, "use": "sig", "alg": "RS256",
+				"n": b64(key.rsa.N.Bytes()),
+				"e": b64(big.NewInt(int64(key.rsa.E)).Bytes()),
+			})
+		} else {
+			point := key.ec.PublicKey
+			jwks = append(jwks, map[string]string{
+				"kty": "EC", "kid": key.id, "crv": "P-256",
+				"x": b64(point.X.FillBytes(make([]byte, 32))),
+				"y": b64(point.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+	}
+
+	data, err := json.Marshal(map[string]any{"keys": jwks})
+	require.NoError(t, err)
+	return data
+}
+
+func sign(t *testing.T, key testKey, claims map[string]any) string {
+	alg := "RS256"
+	if key.ec != nil {
+		alg = "ES256"
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": key.id, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := b64(header) + "." + b64(payload)
+	hashed := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	if key.rsa != nil {
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key.rsa, crypto.SHA256, hashed[:])
+		require.NoError(t, err)
+	} else {
+		r, s, err := ecdsa.Sign(rand.Reader, key.ec, hashed[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signingInput + "." + b64(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":   "https://sso.example.com",
+		"aud":   []string{"pr-reviewer"},
+		"sub":   "u1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"lead", "unknown"},
+	}
+}
+
+func newFileVerifier(t *testing.T, keys ...testKey) *oidc.Verifier {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keySet(t, keys...), 0o600))
+
+	cfg := oidc.DefaultConfig()
+	cfg.JWKS = path
+	cfg.Issuer = "https://sso.example.com"
+	cfg.Audience = "pr-reviewer"
+
+	verifier, err := oidc.NewVerifier(context.Background(), http.DefaultClient, cfg)
+	require.NoError(t, err)
+	return verifier
+}
+
+func TestVerifyMapsClaims(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t, "rsa-1"), newECKey(t, "ec-1")
+	verifier := newFileVerifier(t, rsaKey, ecKey)
+
+	for _, key := range []testKey{rsaKey, ecKey} {
+		principal, err := verifier.Verify(context.Background(), sign(t, key, validClaims()))
+		require.NoError(t, err, key.id)
+
+		assert.Equal(t, domain.UserID("u1"), principal.UserID)
+		assert.Equal(t, []domain.UserRole{domain.RoleLead}, principal.Roles)
+	}
+}
+
+func TestVerifyDefaultsToMember(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	verifier := newFileVerifier(t, key)
+
+	claims := validClaims()
+	delete(claims, "roles")
+
+	principal, err := verifier.Verify(context.Background(), sign(t, key, claims))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UserRole{domain.RoleMember}, principal.Roles)
+}
+
+func TestVerifyNestedClaims(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keySet(t, key), 0o600))
+
+	cfg := oidc.DefaultConfig()
+	cfg.JWKS = path
+	cfg.UserIDClaim = "preferred_username"
+	cfg.RolesClaim = "realm_access.roles"
+	verifier, err := oidc.NewVerifier(context.Background(), http.DefaultClient, cfg)
+	require.NoError(t, err)
+
+	principal, err := verifier.Verify(context.Background(), sign(t, key, map[string]any{
+		"preferred_username": "u2",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"realm_access":       map[string]any{"roles": []string{"ADMIN"}},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Principal{UserID: "u2", Roles: []domain.UserRole{domain.RoleAdmin}}, principal)
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	key, otherKey := newRSAKey(t, "rsa-1"), newRSAKey(t, "rsa-1")
+	verifier := newFileVerifier(t, key)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	notYet := validClaims()
+	notYet["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	otherIssuer := validClaims()
+	otherIssuer["iss"] = "https://evil.example.com"
+
+	otherAudience := validClaims()
+	otherAudience["aud"] = "other-service"
+
+	noSubject := validClaims()
+	delete(noSubject, "sub")
+
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+
+	valid := sign(t, key, validClaims())
+	parts := strings.Split(valid, ".")
+	unsigned := b64([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	tests := map[string]string{
+		"expired":        sign(t, key, expired),
+		"not yet valid":  sign(t, key, notYet),
+		"other issuer":   sign(t, key, otherIssuer),
+		"other audience": sign(t, key, otherAudience),
+		"no subject":     sign(t, key, noSubject),
+		"no expiry":      sign(t, key, noExpiry),
+		"other key":      sign(t, otherKey, validClaims()),
+		"unknown key":    sign(t, newRSAKey(t, "rsa-2"), validClaims()),
+		"alg none":       unsigned,
+		"malformed":      "not-a-jwt",
+		"tampered":       valid[:len(valid)-4] + "AAAA",
+	}
+
+	for name, token := range tests {
+		_, err := verifier.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, domain.ErrUnauthorized, name)
+	}
+}
+
+func TestVerifyRefetchesRotatedKeys(t *testing.T) {
+	oldKey, newKey := newRSAKey(t, "old"), newRSAKey(t, "new")
+
+	var rotated atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if rotated.Load() {
+			w.Write(keySet(t, newKey))
+			return
+		}
+		w.Write(keySet(t, oldKey))
+	}))
+	defer server.Close()
+
+	cfg := oidc.DefaultConfig()
+	cfg.JWKS = server.URL
+	cfg.RefreshInterval = 0
+	verifier, err := oidc.NewVerifier(context.Background(), server.Client(), cfg)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(), sign(t, oldKey, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	rotated.Store(true)
+	principal, err := verifier.Verify(context.Background(), sign(t, newKey, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserID("u1"), principal.UserID)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestVerifyLimitsKeySetFetches(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(keySet(t, key))
+	}))
+	defer server.Close()
+
+	cfg := oidc.DefaultConfig()
+	cfg.JWKS = server.URL
+	verifier, err := oidc.NewVerifier(context.Background(), server.Client(), cfg)
+	require.NoError(t, err)
+
+	for range 3 {
+		_, err = verifier.Verify(context.Background(), sign(t, newRSAKey(t, "unknown"), validClaims()))
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+}
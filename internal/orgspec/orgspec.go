@@ -0,0 +1,71 @@
+// Package orgspec reads the desired org structure from YAML or JSON.
+package orgspec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"pr-reviewer-service/internal/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+type document struct {
+	Teams []teamDocument `yaml:"teams"`
+}
+
+type teamDocument struct {
+	TeamName       string           `yaml:"team_name"`
+	ParentTeamName string           `yaml:"parent_team_name"`
+	Members        []memberDocument `yaml:"members"`
+}
+
+type memberDocument struct {
+	UserID   string `yaml:"user_id"`
+	Username string `yaml:"username"`
+	FullName string `yaml:"full_name"`
+	Email    string `yaml:"email"`
+	Role     string `yaml:"role"`
+	IsActive *bool  `yaml:"is_active"`
+}
+
+// Parse decodes a spec. JSON is a subset of YAML, so both formats are
+// accepted. Members are active unless is_active says otherwise.
+func Parse(data []byte) (domain.OrgSpec, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var doc document
+	if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return domain.OrgSpec{}, fmt.Errorf("%w: %v", domain.ErrInvalidArgument, err)
+	}
+
+	spec := domain.OrgSpec{Teams: make([]domain.TeamSpec, 0, len(doc.Teams))}
+	for _, team := range doc.Teams {
+		members := make([]domain.MemberSpec, 0, len(team.Members))
+		for _, member := range team.Members {
+			isActive := true
+			if member.IsActive != nil {
+				isActive = *member.IsActive
+			}
+
+			members = append(members, domain.MemberSpec{
+				UserID:   domain.UserID(member.UserID),
+				Username: member.Username,
+				FullName: member.FullName,
+				Email:    member.Email,
+				Role:     domain.UserRole(member.Role),
+				IsActive: isActive,
+			})
+		}
+
+		spec.Teams = append(spec.Teams, domain.TeamSpec{
+			Name:       domain.TeamName(team.TeamName),
+			ParentName: domain.TeamName(team.ParentTeamName),
+			Members:    members,
+		})
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,54 @@
+package orgspec_test
+
+import (
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/orgspec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_YAML(t *testing.T) {
+	data := []byte(`
+teams:
+  - team_name: platform
+    members:
+      - user_id: u1
+        username: Alice
+        role: LEAD
+  - team_name: backend
+    parent_team_name: platform
+    members:
+      - user_id: u2
+        username: Bob
+        full_name: Bob Smith
+        is_active: false
+`)
+
+	spec, err := orgspec.Parse(data)
+	require.NoError(t, err)
+
+	require.Len(t, spec.Teams, 2)
+	assert.Equal(t, domain.TeamName("backend"), spec.Teams[1].Name)
+	assert.Equal(t, domain.TeamName("platform"), spec.Teams[1].ParentName)
+	assert.Equal(t, domain.MemberSpec{UserID: "u1", Username: "Alice", Role: domain.RoleLead, IsActive: true}, spec.Teams[0].Members[0])
+	assert.Equal(t, domain.MemberSpec{UserID: "u2", Username: "Bob", FullName: "Bob Smith", IsActive: false}, spec.Teams[1].Members[0])
+}
+
+func TestParse_JSON(t *testing.T) {
+	data := []byte(`{"teams": [{"team_name": "backend", "members": [{"user_id": "u1", "username": "Alice"}]}]}`)
+
+	spec, err := orgspec.Parse(data)
+	require.NoError(t, err)
+
+	require.Len(t, spec.Teams, 1)
+	assert.Equal(t, domain.TeamName("backend"), spec.Teams[0].Name)
+	assert.True(t, spec.Teams[0].Members[0].IsActive)
+}
+
+func TestParse_RejectsUnknownFields(t *testing.T) {
+	_, err := orgspec.Parse([]byte("teams:\n  - name: backend\n"))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
@@ -0,0 +1,47 @@
+package inmemory
+
+import (
+	"context"
+	"maps"
+	"pr-reviewer-service/internal/domain"
+)
+
+type ChatChannelRepo struct {
+	db *InMemoryStorage
+}
+
+func NewChatChannelRepo(db *InMemoryStorage) *ChatChannelRepo {
+	return &ChatChannelRepo{
+		db: db,
+	}
+}
+
+func (cr *ChatChannelRepo) SetChatChannel(_ context.Context, channel domain.ChatChannel) (domain.ChatChannel, error) {
+	if _, exists := cr.db.Teams[channel.TeamName]; !exists {
+		return domain.ChatChannel{}, domain.ErrNotFound
+	}
+
+	channel.Templates = maps.Clone(channel.Templates)
+	cr.db.ChatChannels[channel.TeamName] = channel
+
+	return channel, nil
+}
+
+func (cr *ChatChannelRepo) ChatChannel(_ context.Context, teamName domain.TeamName) (domain.ChatChannel, error) {
+	channel, exists := cr.db.ChatChannels[teamName]
+	if !exists {
+		return domain.ChatChannel{}, domain.ErrNotFound
+	}
+
+	return channel, nil
+}
+
+func (cr *ChatChannelRepo) DeleteChatChannel(_ context.Context, teamName domain.TeamName) error {
+	if _, exists := cr.db.ChatChannels[teamName]; !exists {
+		return domain.ErrNotFound
+	}
+
+	delete(cr.db.ChatChannels, teamName)
+
+	return nil
+}
@@ -1,11 +1,24 @@
 package inmemory
 
-import "pr-reviewer-service/internal/domain"
+import (
+	"pr-reviewer-service/internal/domain"
+	"sync"
+)
 
 type InMemoryStorage struct {
 	Users             map[domain.UserID]domain.User
 	Teams             map[domain.TeamName]domain.Team
 	PRs               map[domain.PullRequestID]domain.PullRequest
+	PREvents          []domain.PREvent
+	Webhooks          map[int64]domain.WebhookSubscription
+	WebhookDeliveries map[int64]domain.WebhookDelivery
+	ChatChannels      map[domain.TeamName]domain.ChatChannel
+	Identities        []domain.UserIdentity
+	APITokens         map[int64]domain.APIToken
+	IdempotencyKeys   map[string]domain.IdempotentRequest
+
+	// txMu runs units of work one at a time.
+	txMu sync.Mutex
 }
 
 func NewStorage() (*InMemoryStorage, error) {
@@ -13,5 +26,10 @@ func NewStorage() (*InMemoryStorage, error) {
 		Users:             map[domain.UserID]domain.User{},
 		Teams:             map[domain.TeamName]domain.Team{},
 		PRs:               map[domain.PullRequestID]domain.PullRequest{},
+		Webhooks:          map[int64]domain.WebhookSubscription{},
+		WebhookDeliveries: map[int64]domain.WebhookDelivery{},
+		ChatChannels:      map[domain.TeamName]domain.ChatChannel{},
+		APITokens:         map[int64]domain.APIToken{},
+		IdempotencyKeys:   map[string]domain.IdempotentRequest{},
 	}, nil
 }
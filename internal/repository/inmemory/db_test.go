@@ -2,8 +2,10 @@ package inmemory_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
 	"pr-reviewer-service/internal/repository/inmemory"
 	"testing"
 	"time"
@@ -130,11 +132,34 @@ func TestFailMergeByIDWhenNotFound(t *testing.T) {
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
+func TestReopenByIDRestoresClosedPR(t *testing.T) {
+	e := setup()
+	e.storage.PRs[prID] = testPR
+
+	_, err := e.prRepo.CloseByID(e.ctx, prID)
+	require.NoError(t, err)
+
+	reopenedPR, err := e.prRepo.ReopenByID(e.ctx, prID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusOpen, reopenedPR.Status)
+	assert.Nil(t, reopenedPR.ClosedAt)
+	assert.Equal(t, testPR.AssignedReviewers, reopenedPR.AssignedReviewers)
+
+	// reopening an open pull request records nothing
+	_, err = e.prRepo.ReopenByID(e.ctx, prID)
+	require.NoError(t, err)
+
+	events, err := e.prRepo.Timeline(e.ctx, prID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.EventReopened, events[1].Kind)
+}
+
 func TestSuccessReassignReviewer(t *testing.T) {
 	e := setup()
 	e.storage.PRs[prID] = testPR
 
-	pr, newReviewer, err := e.prRepo.ReassignReviewer(e.ctx, prID, firstReviewerID, secondReviewerID)
+	pr, newReviewer, err := e.prRepo.ReassignReviewer(e.ctx, prID, firstReviewerID, secondReviewerID, domain.ReassignManual)
 	require.NoError(t, err)
 	assert.Equal(t, secondReviewerID, newReviewer)
 	assert.Len(t, pr.AssignedReviewers, 1)
@@ -148,7 +173,7 @@ func TestFailReassignReviewerOnSameUser(t *testing.T) {
 	e := setup()
 	e.storage.PRs[prID] = testPR
 
-	_, _, err := e.prRepo.ReassignReviewer(e.ctx, prID, firstReviewerID, firstReviewerID)
+	_, _, err := e.prRepo.ReassignReviewer(e.ctx, prID, firstReviewerID, firstReviewerID, domain.ReassignManual)
 	require.Error(t, err)
 	assert.ErrorIs(t, err, domain.ErrNoCandidate)
 }
@@ -157,7 +182,7 @@ func TestFailReassignReviewerWhenOldIsNotAssigned(t *testing.T) {
 	e := setup()
 	e.storage.PRs[prID] = testPR
 
-	_, _, err := e.prRepo.ReassignReviewer(e.ctx, prID, secondReviewerID, authorID)
+	_, _, err := e.prRepo.ReassignReviewer(e.ctx, prID, secondReviewerID, authorID, domain.ReassignManual)
 	fmt.Print(err)
 	require.Error(t, err)
 	assert.ErrorIs(t, err, domain.ErrNotAssigned)
@@ -173,7 +198,7 @@ func TestSucessPullRequestsByReviewer(t *testing.T) {
 	e.storage.PRs[pr3.ID] = pr3
 	e.storage.PRs[pr4.ID] = pr4
 
-	prs, err := e.prRepo.PullRequestsByReviewer(e.ctx, firstReviewerID)
+	prs, err := e.prRepo.PullRequestsByReviewer(e.ctx, firstReviewerID, domain.PullRequestFilter{})
 	require.NoError(t, err)
 	require.Len(t, prs, 2)
 
@@ -182,3 +207,135 @@ func TestSucessPullRequestsByReviewer(t *testing.T) {
 	assert.Contains(t, prIDs, domain.PullRequestID("pr-4"))
 	assert.NotContains(t, prIDs, domain.PullRequestID("pr-3"))
 }
+
+func TestPullRequestsByReviewerFiltersAndPaginates(t *testing.T) {
+	e := setup()
+	now := time.Now()
+	e.storage.PRs["pr-old"] = domain.PullRequest{ID: "pr-old", Status: domain.StatusOpen, CreatedAt: now.Add(-2 * time.Hour), AssignedReviewers: []domain.UserID{firstReviewerID}}
+	e.storage.PRs["pr-mid"] = domain.PullRequest{ID: "pr-mid", Status: domain.StatusOpen, CreatedAt: now.Add(-time.Hour), AssignedReviewers: []domain.UserID{firstReviewerID}}
+	e.storage.PRs["pr-new"] = domain.PullRequest{ID: "pr-new", Status: domain.StatusOpen, CreatedAt: now, AssignedReviewers: []domain.UserID{firstReviewerID}}
+	e.storage.PRs["pr-merged"] = domain.PullRequest{ID: "pr-merged", Status: domain.StatusMerged, CreatedAt: now, AssignedReviewers: []domain.UserID{firstReviewerID}}
+
+	filter := domain.PullRequestFilter{
+		Statuses: []domain.PRStatus{domain.StatusOpen},
+		Order:    domain.SortOldestFirst,
+		Limit:    2,
+	}
+
+	firstPage, err := e.prRepo.PullRequestsByReviewer(e.ctx, firstReviewerID, filter)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	assert.Equal(t, domain.PullRequestID("pr-old"), firstPage[0].ID)
+	assert.Equal(t, domain.PullRequestID("pr-mid"), firstPage[1].ID)
+	assert.Equal(t, domain.ReviewPending, firstPage[0].ReviewState)
+
+	filter.After = &domain.PullRequestCursor{CreatedAt: firstPage[1].CreatedAt, ID: firstPage[1].ID}
+	secondPage, err := e.prRepo.PullRequestsByReviewer(e.ctx, firstReviewerID, filter)
+	require.NoError(t, err)
+	require.Len(t, secondPage, 1)
+	assert.Equal(t, domain.PullRequestID("pr-new"), secondPage[0].ID)
+
+	newest, err := e.prRepo.PullRequestsByReviewer(e.ctx, firstReviewerID, domain.PullRequestFilter{Order: domain.SortNewestFirst, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, newest, 1)
+	assert.Equal(t, domain.PullRequestID("pr-new"), newest[0].ID)
+}
+
+func TestSetReviewStateAndResetOnReassign(t *testing.T) {
+	e := setup()
+	e.storage.PRs[prID] = testPR
+
+	pr, err := e.prRepo.SetReviewState(e.ctx, prID, firstReviewerID, domain.ReviewApproved)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewApproved, pr.ReviewState(firstReviewerID))
+
+	_, err = e.prRepo.SetReviewState(e.ctx, prID, secondReviewerID, domain.ReviewApproved)
+	assert.ErrorIs(t, err, domain.ErrNotAssigned)
+
+	pr, _, err = e.prRepo.ReassignReviewer(e.ctx, prID, firstReviewerID, secondReviewerID, domain.ReassignManual)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewPending, pr.ReviewState(secondReviewerID))
+}
+
+func TestPullRequestsByReviewerFiltersByTeam(t *testing.T) {
+	e := setup()
+	e.storage.PRs["pr-backend"] = domain.PullRequest{ID: "pr-backend", TeamName: "backend", Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{firstReviewerID}}
+	e.storage.PRs["pr-infra"] = domain.PullRequest{ID: "pr-infra", TeamName: "infra", Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{firstReviewerID}}
+
+	prs, err := e.prRepo.PullRequestsByReviewer(e.ctx, firstReviewerID, domain.PullRequestFilter{TeamName: "infra"})
+
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, domain.PullRequestID("pr-infra"), prs[0].ID)
+	assert.Equal(t, domain.TeamName("infra"), prs[0].TeamName)
+}
+
+func TestUseTokenSkipsRevoked(t *testing.T) {
+	e := setup()
+	tokenRepo := inmemory.NewAPITokenRepo(e.storage)
+
+	token, err := tokenRepo.CreateToken(e.ctx, domain.APIToken{Name: "ci", Scopes: []domain.TokenScope{domain.ScopeRead}, Hash: "abc"})
+	require.NoError(t, err)
+
+	usedAt := time.Now()
+	used, err := tokenRepo.UseToken(e.ctx, "abc", usedAt)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, used.ID)
+	require.NotNil(t, used.LastUsedAt)
+	assert.Equal(t, usedAt, *used.LastUsedAt)
+
+	_, err = tokenRepo.RevokeToken(e.ctx, token.ID, time.Now())
+	require.NoError(t, err)
+
+	_, err = tokenRepo.UseToken(e.ctx, "abc", time.Now())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestUnitOfWorkRollsBackOnError(t *testing.T) {
+	e := setup()
+	e.storage.PRs[prID] = testPR
+	failure := errors.New("failure")
+
+	err := inmemory.NewUnitOfWork(e.storage).WithinTx(e.ctx, func(ctx context.Context, tx repository.Tx) error {
+		_, _, err := tx.PullRequests().ReassignReviewer(ctx, prID, firstReviewerID, secondReviewerID, domain.ReassignManual)
+		require.NoError(t, err)
+
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	assert.Equal(t, []domain.UserID{firstReviewerID}, e.storage.PRs[prID].AssignedReviewers)
+	events, err := e.prRepo.Timeline(e.ctx, prID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestUnitOfWorkLocksUsersInIDOrder(t *testing.T) {
+	e := setup()
+	for _, userID := range []domain.UserID{secondReviewerID, firstReviewerID} {
+		e.storage.Users[userID] = domain.User{ID: userID, IsActive: true}
+	}
+
+	err := inmemory.NewUnitOfWork(e.storage).WithinTx(e.ctx, func(ctx context.Context, tx repository.Tx) error {
+		users, err := tx.LockUsers(ctx, []domain.UserID{secondReviewerID, "u-unknown", firstReviewerID, secondReviewerID})
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, firstReviewerID, users[0].ID)
+		assert.Equal(t, secondReviewerID, users[1].ID)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateUserKeepsMembershipsUnique(t *testing.T) {
+	e := setup()
+	userRepo := inmemory.NewUserRepo(e.storage)
+
+	require.NoError(t, userRepo.Create(e.ctx, domain.User{ID: "u1", Username: "u1", TeamName: "backend", IsActive: true}))
+	require.NoError(t, userRepo.Create(e.ctx, domain.User{ID: "u1", Username: "u1", TeamName: "backend", Teams: []domain.TeamName{"backend", "infra"}, IsActive: true}))
+
+	user, err := userRepo.UserByID(e.ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TeamName{"backend", "infra"}, user.Teams)
+}
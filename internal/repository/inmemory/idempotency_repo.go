@@ -0,0 +1,62 @@
+package inmemory
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"slices"
+	"time"
+)
+
+type IdempotencyRepo struct {
+	db *InMemoryStorage
+}
+
+func NewIdempotencyRepo(db *InMemoryStorage) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		db: db,
+	}
+}
+
+func (ir *IdempotencyRepo) ClaimIdempotencyKey(_ context.Context, request domain.IdempotentRequest, now time.Time) (domain.IdempotentRequest, bool, error) {
+	if existing, exists := ir.db.IdempotencyKeys[request.Key]; exists && existing.ExpiresAt.After(now) {
+		return existing, false, nil
+	}
+
+	request.StatusCode = 0
+	request.Body = nil
+	request.CreatedAt = now
+	ir.db.IdempotencyKeys[request.Key] = request
+
+	return request, true, nil
+}
+
+func (ir *IdempotencyRepo) CompleteIdempotentRequest(_ context.Context, key string, statusCode int, body []byte, expiresAt time.Time) error {
+	request, exists := ir.db.IdempotencyKeys[key]
+	if !exists {
+		return domain.ErrNotFound
+	}
+
+	request.StatusCode = statusCode
+	request.Body = slices.Clone(body)
+	request.ExpiresAt = expiresAt
+	ir.db.IdempotencyKeys[key] = request
+
+	return nil
+}
+
+func (ir *IdempotencyRepo) DeleteIdempotentRequest(_ context.Context, key string) error {
+	delete(ir.db.IdempotencyKeys, key)
+	return nil
+}
+
+func (ir *IdempotencyRepo) DeleteExpiredIdempotentRequests(_ context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for key, request := range ir.db.IdempotencyKeys {
+		if !request.ExpiresAt.After(now) {
+			delete(ir.db.IdempotencyKeys, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,71 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"slices"
+)
+
+type IdentityRepo struct {
+	db *InMemoryStorage
+}
+
+func NewIdentityRepo(db *InMemoryStorage) *IdentityRepo {
+	return &IdentityRepo{
+		db: db,
+	}
+}
+
+func (ir *IdentityRepo) LinkIdentity(_ context.Context, identity domain.UserIdentity) (domain.UserIdentity, error) {
+	if _, exists := ir.db.Users[identity.UserID]; !exists {
+		return domain.UserIdentity{}, domain.ErrNotFound
+	}
+
+	for i, linked := range ir.db.Identities {
+		if linked.Provider == identity.Provider && linked.ExternalID == identity.ExternalID {
+			ir.db.Identities[i] = identity
+			return identity, nil
+		}
+	}
+
+	ir.db.Identities = append(ir.db.Identities, identity)
+
+	return identity, nil
+}
+
+func (ir *IdentityRepo) UnlinkIdentity(_ context.Context, provider domain.IdentityProvider, externalID string) error {
+	for i, identity := range ir.db.Identities {
+		if identity.Provider == provider && identity.ExternalID == externalID {
+			ir.db.Identities = slices.Delete(ir.db.Identities, i, i+1)
+			return nil
+		}
+	}
+
+	return domain.ErrNotFound
+}
+
+func (ir *IdentityRepo) UserIDByIdentity(_ context.Context, provider domain.IdentityProvider, externalID string) (domain.UserID, error) {
+	for _, identity := range ir.db.Identities {
+		if identity.Provider == provider && identity.ExternalID == externalID {
+			return identity.UserID, nil
+		}
+	}
+
+	return "", domain.ErrNotFound
+}
+
+func (ir *IdentityRepo) IdentitiesByUserID(_ context.Context, userID domain.UserID) ([]domain.UserIdentity, error) {
+	identities := []domain.UserIdentity{}
+	for _, identity := range ir.db.Identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+
+	slices.SortFunc(identities, func(a, b domain.UserIdentity) int {
+		return cmp.Or(cmp.Compare(a.Provider, b.Provider), cmp.Compare(a.ExternalID, b.ExternalID))
+	})
+
+	return identities, nil
+}
@@ -0,0 +1,153 @@
+package inmemory
+
+import (
+	"context"
+	"maps"
+	"pr-reviewer-service/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+type OrgRepo struct {
+	db *InMemoryStorage
+}
+
+func NewOrgRepo(db *InMemoryStorage) *OrgRepo {
+	return &OrgRepo{
+		db: db,
+	}
+}
+
+func (or *OrgRepo) Snapshot(_ context.Context) (domain.OrgSnapshot, error) {
+	snapshot := domain.OrgSnapshot{
+		Teams: []domain.Team{},
+		Users: []domain.User{},
+	}
+
+	for _, team := range or.db.Teams {
+		team.Members = nil
+		snapshot.Teams = append(snapshot.Teams, team)
+	}
+
+	for _, user := range or.db.Users {
+		if !user.IsDeleted() {
+			snapshot.Users = append(snapshot.Users, user)
+		}
+	}
+
+	slices.SortFunc(snapshot.Teams, func(a, b domain.Team) int {
+		return strings.Compare(string(a.Name), string(b.Name))
+	})
+	slices.SortFunc(snapshot.Users, func(a, b domain.User) int {
+		return strings.Compare(string(a.ID), string(b.ID))
+	})
+
+	return snapshot, nil
+}
+
+// Apply works on copies of the teams and users and only stores them once
+// every change went through.
+func (or *OrgRepo) Apply(_ context.Context, changes []domain.OrgChange) error {
+	teams := maps.Clone(or.db.Teams)
+	users := maps.Clone(or.db.Users)
+
+	for _, change := range changes {
+		if err := applyOrgChange(teams, users, change); err != nil {
+			return err
+		}
+	}
+
+	or.db.Teams = teams
+	or.db.Users = users
+
+	return nil
+}
+
+func applyOrgChange(teams map[domain.TeamName]domain.Team, users map[domain.UserID]domain.User, change domain.OrgChange) error {
+	switch change.Kind {
+	case domain.ChangeCreateTeam:
+		if _, exists := teams[change.TeamName]; exists {
+			return domain.ErrTeamExists
+		}
+		if _, exists := teams[change.ParentName]; change.ParentName != "" && !exists {
+			return domain.ErrNotFound
+		}
+		teams[change.TeamName] = domain.Team{Name: change.TeamName, ParentName: change.ParentName}
+
+	case domain.ChangeSetTeamParent:
+		team, exists := teams[change.TeamName]
+		if !exists {
+			return domain.ErrNotFound
+		}
+		if _, exists := teams[change.ParentName]; change.ParentName != "" && !exists {
+			return domain.ErrNotFound
+		}
+		team.ParentName = change.ParentName
+		teams[change.TeamName] = team
+
+	case domain.ChangeArchiveTeam:
+		team, exists := teams[change.TeamName]
+		if !exists {
+			return domain.ErrNotFound
+		}
+		if team.ArchivedAt == nil {
+			now := time.Now()
+			team.ArchivedAt = &now
+		}
+		teams[change.TeamName] = team
+
+	case domain.ChangeCreateUser:
+		users[change.User.ID] = domain.User{
+			ID:       change.User.ID,
+			Username: change.User.Username,
+			FullName: change.User.FullName,
+			Email:    change.User.Email,
+			Role:     change.User.Role,
+			IsActive: change.User.IsActive,
+		}
+
+	case domain.ChangeUpdateUser:
+		user, exists := users[change.User.ID]
+		if !exists || user.IsDeleted() {
+			return domain.ErrNotFound
+		}
+		user.Username = change.User.Username
+		user.FullName = change.User.FullName
+		user.Email = change.User.Email
+		user.Role = change.User.Role
+		user.IsActive = change.User.IsActive
+		users[change.User.ID] = user
+
+	case domain.ChangeAddMembership:
+		user, exists := users[change.User.ID]
+		if !exists {
+			return domain.ErrNotFound
+		}
+		if _, exists := teams[change.TeamName]; !exists {
+			return domain.ErrNotFound
+		}
+		users[change.User.ID] = withMembership(user, change.TeamName)
+
+	case domain.ChangeSetHomeTeam:
+		user, exists := users[change.User.ID]
+		if !exists {
+			return domain.ErrNotFound
+		}
+		user.TeamName = change.TeamName
+		users[change.User.ID] = withMembership(user, change.TeamName)
+
+	case domain.ChangeRemoveMembership:
+		user, exists := users[change.User.ID]
+		if !exists {
+			return domain.ErrNotFound
+		}
+		user = withoutMembership(user, change.TeamName)
+		if user.TeamName == change.TeamName {
+			user.TeamName = ""
+		}
+		users[change.User.ID] = user
+	}
+
+	return nil
+}
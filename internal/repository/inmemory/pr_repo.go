@@ -2,8 +2,10 @@ package inmemory
 
 import (
 	"context"
+	"maps"
 	"pr-reviewer-service/internal/domain"
 	"slices"
+	"strings"
 	"time"
 )
 
@@ -17,14 +19,46 @@ func NewPullRequestRepo(db *InMemoryStorage) *PullRequestRepo {
 	}
 }
 
-func (prr *PullRequestRepo) Create(_ context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+func (prr *PullRequestRepo) Create(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	return prr.create(ctx, pr, prr.addEvent)
+}
+
+func (prr *PullRequestRepo) Restore(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	return prr.create(ctx, pr, func(ctx context.Context, event domain.PREvent) {
+		prr.recordEvent(ctx, event)
+	})
+}
+
+func (prr *PullRequestRepo) create(ctx context.Context, pr domain.PullRequest, addEvent func(ctx context.Context, event domain.PREvent)) (domain.PullRequest, error) {
 	if _, exists := prr.db.PRs[pr.ID]; exists {
 		return domain.PullRequest{}, domain.ErrPRExists
 	}
 
-	pr.CreatedAt = time.Now()
+	if pr.CreatedAt.IsZero() {
+		pr.CreatedAt = time.Now()
+	}
 	prr.db.PRs[pr.ID] = pr
 
+	addEvent(ctx, domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventCreated, UserID: pr.AuthorID, CreatedAt: pr.CreatedAt})
+	for _, reviewerID := range pr.AssignedReviewers {
+		addEvent(ctx, domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventReviewerAssigned, UserID: reviewerID, CreatedAt: pr.CreatedAt})
+	}
+
+	switch pr.Status {
+	case domain.StatusMerged:
+		event := domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventMerged}
+		if pr.MergedAt != nil {
+			event.CreatedAt = *pr.MergedAt
+		}
+		addEvent(ctx, event)
+	case domain.StatusClosed:
+		event := domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventClosed}
+		if pr.ClosedAt != nil {
+			event.CreatedAt = *pr.ClosedAt
+		}
+		addEvent(ctx, event)
+	}
+
 	return pr, nil
 }
 
@@ -37,12 +71,15 @@ func (prr *PullRequestRepo) PullRequestByID(_ context.Context, pullRequestID dom
 	return pr, nil
 }
 
-func (prr *PullRequestRepo) MergeByID(_ context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+func (prr *PullRequestRepo) MergeByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
 	pr, exists := prr.db.PRs[pullRequestID]
 	if !exists {
 		return domain.PullRequest{}, domain.ErrNotFound
 	}
 
+	if pr.Status != domain.StatusMerged {
+		prr.addEvent(ctx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventMerged})
+	}
 	pr.Status = domain.StatusMerged
 
 	if pr.MergedAt == nil {
@@ -55,7 +92,64 @@ func (prr *PullRequestRepo) MergeByID(_ context.Context, pullRequestID domain.Pu
 	return pr, nil
 }
 
-func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID domain.PullRequestID, oldUserID domain.UserID, newUserID domain.UserID) (domain.PullRequest, domain.UserID, error) {
+func (prr *PullRequestRepo) CloseByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+	pr, exists := prr.db.PRs[pullRequestID]
+	if !exists {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	if pr.Status != domain.StatusClosed {
+		prr.addEvent(ctx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventClosed})
+	}
+	pr.Status = domain.StatusClosed
+
+	if pr.ClosedAt == nil {
+		now := time.Now()
+		pr.ClosedAt = &now
+	}
+
+	prr.db.PRs[pullRequestID] = pr
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) ReopenByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+	pr, exists := prr.db.PRs[pullRequestID]
+	if !exists {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	if pr.Status == domain.StatusClosed {
+		prr.addEvent(ctx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventReopened})
+		pr.Status = domain.StatusOpen
+		pr.ClosedAt = nil
+	}
+
+	prr.db.PRs[pullRequestID] = pr
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) SetAuthor(ctx context.Context, pullRequestID domain.PullRequestID, authorID domain.UserID) (domain.PullRequest, error) {
+	pr, exists := prr.db.PRs[pullRequestID]
+	if !exists {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	if _, exists := prr.db.Users[authorID]; !exists {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	if pr.AuthorID != authorID {
+		prr.addEvent(ctx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventAuthorChanged, UserID: authorID, PreviousUserID: pr.AuthorID})
+	}
+	pr.AuthorID = authorID
+	prr.db.PRs[pullRequestID] = pr
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID domain.PullRequestID, oldUserID domain.UserID, newUserID domain.UserID, reason domain.ReassignReason) (domain.PullRequest, domain.UserID, error) {
 	if oldUserID == newUserID {
 		return domain.PullRequest{}, domain.UserID(""), domain.ErrNoCandidate
 	}
@@ -72,7 +166,20 @@ func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID
 		if reviewer == oldUserID {
 			assignedReviewers[i] = newUserID
 			pr.AssignedReviewers = assignedReviewers
+
+			pr.ReviewStates = maps.Clone(pr.ReviewStates)
+			delete(pr.ReviewStates, oldUserID)
+			delete(pr.ReviewStates, newUserID)
+
 			prr.db.PRs[pullRequestID] = pr
+			prr.addEvent(ctx, domain.PREvent{
+				PullRequestID:  pullRequestID,
+				Kind:           domain.EventReviewerReassigned,
+				UserID:         newUserID,
+				PreviousUserID: oldUserID,
+				Reason:         reason,
+			})
+
 			return pr, newUserID, nil
 		}
 	}
@@ -80,19 +187,177 @@ func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID
 	return domain.PullRequest{}, domain.UserID(""), domain.ErrNotAssigned
 }
 
-func (prr *PullRequestRepo) PullRequestsByReviewer(_ context.Context, userID domain.UserID) ([]domain.PullRequestShort, error) {
+func (prr *PullRequestRepo) RemoveReviewer(ctx context.Context, pullRequestID domain.PullRequestID, userID domain.UserID, reason domain.ReassignReason) (domain.PullRequest, error) {
+	pr, exists := prr.db.PRs[pullRequestID]
+	if !exists {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	if !slices.Contains(pr.AssignedReviewers, userID) {
+		return domain.PullRequest{}, domain.ErrNotAssigned
+	}
+
+	pr.AssignedReviewers = slices.DeleteFunc(slices.Clone(pr.AssignedReviewers), func(id domain.UserID) bool {
+		return id == userID
+	})
+	pr.ReviewStates = maps.Clone(pr.ReviewStates)
+	delete(pr.ReviewStates, userID)
+
+	prr.db.PRs[pullRequestID] = pr
+	prr.addEvent(ctx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventReviewerRemoved, UserID: userID, Reason: reason})
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) SetReviewState(ctx context.Context, pullRequestID domain.PullRequestID, reviewerID domain.UserID, state domain.ReviewState) (domain.PullRequest, error) {
+	pr, exists := prr.db.PRs[pullRequestID]
+	if !exists {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	if !slices.Contains(pr.AssignedReviewers, reviewerID) {
+		return domain.PullRequest{}, domain.ErrNotAssigned
+	}
+
+	reviewStates := maps.Clone(pr.ReviewStates)
+	if reviewStates == nil {
+		reviewStates = map[domain.UserID]domain.ReviewState{}
+	}
+	reviewStates[reviewerID] = state
+
+	pr.ReviewStates = reviewStates
+	prr.db.PRs[pullRequestID] = pr
+	prr.addEvent(ctx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventReviewSubmitted, UserID: reviewerID, ReviewState: state})
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) PullRequestsByReviewer(_ context.Context, userID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequestShort, error) {
 	prs := []domain.PullRequestShort{}
 
 	for _, pr := range prr.db.PRs {
-		if slices.Contains(pr.AssignedReviewers, userID) {
-			prs = append(prs, domain.PullRequestShort{
-				ID:       pr.ID,
-				Name:     pr.Name,
-				AuthorID: pr.AuthorID,
-				Status:   pr.Status,
-			})
+		if !slices.Contains(pr.AssignedReviewers, userID) {
+			continue
+		}
+
+		if !filter.MatchesStatus(pr.Status) || !filter.MatchesTeam(pr.TeamName) || !filter.IsAfterCursor(pr.CreatedAt, pr.ID) {
+			continue
 		}
+
+		prs = append(prs, domain.PullRequestShort{
+			ID:          pr.ID,
+			Name:        pr.Name,
+			AuthorID:    pr.AuthorID,
+			TeamName:    pr.TeamName,
+			Status:      pr.Status,
+			CreatedAt:   pr.CreatedAt,
+			ReviewState: pr.ReviewState(userID),
+		})
+	}
+
+	slices.SortFunc(prs, func(a, b domain.PullRequestShort) int {
+		return comparePullRequests(a.CreatedAt, a.ID, b.CreatedAt, b.ID, filter.Order)
+	})
+
+	if filter.Limit > 0 && len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
 	}
 
 	return prs, nil
 }
+
+func (prr *PullRequestRepo) PullRequestsByAuthor(ctx context.Context, authorID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	return prr.list(filter, func(pr domain.PullRequest) bool {
+		return pr.AuthorID == authorID
+	})
+}
+
+func (prr *PullRequestRepo) List(_ context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	return prr.list(filter, func(domain.PullRequest) bool {
+		return true
+	})
+}
+
+func (prr *PullRequestRepo) list(filter domain.PullRequestFilter, include func(domain.PullRequest) bool) ([]domain.PullRequest, error) {
+	prs := []domain.PullRequest{}
+
+	for _, pr := range prr.db.PRs {
+		if !include(pr) {
+			continue
+		}
+
+		if !filter.MatchesStatus(pr.Status) || !filter.MatchesTeam(pr.TeamName) || !filter.IsAfterCursor(pr.CreatedAt, pr.ID) {
+			continue
+		}
+
+		prs = append(prs, pr)
+	}
+
+	slices.SortFunc(prs, func(a, b domain.PullRequest) int {
+		return comparePullRequests(a.CreatedAt, a.ID, b.CreatedAt, b.ID, filter.Order)
+	})
+
+	if filter.Limit > 0 && len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
+	}
+
+	return prs, nil
+}
+
+func (prr *PullRequestRepo) Timeline(_ context.Context, pullRequestID domain.PullRequestID) ([]domain.PREvent, error) {
+	events := []domain.PREvent{}
+	for _, event := range prr.db.PREvents {
+		if event.PullRequestID == pullRequestID {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// addEvent appends to the timeline and queues the webhook deliveries of the
+// event.
+func (prr *PullRequestRepo) addEvent(ctx context.Context, event domain.PREvent) {
+	event = prr.recordEvent(ctx, event)
+
+	for _, subscription := range prr.db.Webhooks {
+		if !subscription.Wants(event.Kind) {
+			continue
+		}
+
+		id := nextID(prr.db.WebhookDeliveries)
+		prr.db.WebhookDeliveries[id] = domain.WebhookDelivery{
+			ID:            id,
+			Subscription:  subscription,
+			Event:         event,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+	}
+}
+
+// recordEvent appends to the timeline without notifying any subscription,
+// events without a time happen now.
+func (prr *PullRequestRepo) recordEvent(ctx context.Context, event domain.PREvent) domain.PREvent {
+	event.ID = int64(len(prr.db.PREvents) + 1)
+	event.Actor = domain.ActorFromContext(ctx)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	prr.db.PREvents = append(prr.db.PREvents, event)
+
+	return event
+}
+
+func comparePullRequests(aCreatedAt time.Time, aID domain.PullRequestID, bCreatedAt time.Time, bID domain.PullRequestID, order domain.SortOrder) int {
+	cmp := aCreatedAt.Compare(bCreatedAt)
+	if cmp == 0 {
+		cmp = strings.Compare(string(aID), string(bID))
+	}
+
+	if order == domain.SortNewestFirst {
+		return -cmp
+	}
+	return cmp
+}
@@ -3,6 +3,8 @@ package inmemory
 import (
 	"context"
 	"pr-reviewer-service/internal/domain"
+	"slices"
+	"time"
 )
 
 type TeamRepo struct {
@@ -20,15 +22,31 @@ func (tr *TeamRepo) Create(_ context.Context, team domain.Team) error {
 		return domain.ErrTeamExists
 	}
 
+	if _, exists := tr.db.Teams[team.ParentName]; team.ParentName != "" && !exists {
+		return domain.ErrNotFound
+	}
+
 	for _, member := range team.Members {
-		tr.db.Users[member.UserID] = domain.User{
+		user := domain.User{
 			ID:       member.UserID,
 			Username: member.Username,
 			TeamName: team.Name,
 			IsActive: member.IsActive,
 		}
+
+		if existing, exists := tr.db.Users[member.UserID]; exists {
+			user = withProfile(user, existing)
+			user.Teams = withoutMembership(existing, existing.TeamName).Teams
+		}
+
+		if user.Role == "" {
+			user.Role = domain.RoleMember
+		}
+
+		tr.db.Users[member.UserID] = withMembership(user, team.Name)
 	}
 
+	team.SubTeams = nil
 	tr.db.Teams[team.Name] = team
 
 	return nil
@@ -42,7 +60,7 @@ func (tr *TeamRepo) TeamByName(ctx context.Context, teamName domain.TeamName) (d
 
 	members := []domain.TeamMember{}
 	for _, member := range tr.db.Users {
-		if member.TeamName == teamName {
+		if member.IsMemberOf(teamName) {
 			members = append(members, domain.TeamMember{
 				UserID:   member.ID,
 				Username: member.Username,
@@ -55,3 +73,138 @@ func (tr *TeamRepo) TeamByName(ctx context.Context, teamName domain.TeamName) (d
 
 	return team, nil
 }
+
+func (tr *TeamRepo) SubTeamNames(_ context.Context, parentName domain.TeamName) ([]domain.TeamName, error) {
+	names := []domain.TeamName{}
+	for name, team := range tr.db.Teams {
+		if team.ParentName == parentName {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func (tr *TeamRepo) TeamNames(_ context.Context, includeArchived bool) ([]domain.TeamName, error) {
+	names := []domain.TeamName{}
+	for name, team := range tr.db.Teams {
+		if includeArchived || team.ArchivedAt == nil {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func (tr *TeamRepo) SetParent(_ context.Context, teamName domain.TeamName, parentName domain.TeamName) error {
+	team, exists := tr.db.Teams[teamName]
+	if !exists {
+		return domain.ErrNotFound
+	}
+
+	if _, exists := tr.db.Teams[parentName]; parentName != "" && !exists {
+		return domain.ErrNotFound
+	}
+
+	team.ParentName = parentName
+	tr.db.Teams[teamName] = team
+
+	return nil
+}
+
+func (tr *TeamRepo) Rename(_ context.Context, teamName domain.TeamName, newTeamName domain.TeamName) error {
+	team, exists := tr.db.Teams[teamName]
+	if !exists {
+		return domain.ErrNotFound
+	}
+
+	if _, exists := tr.db.Teams[newTeamName]; exists {
+		return domain.ErrTeamExists
+	}
+
+	for id, user := range tr.db.Users {
+		if !user.IsMemberOf(teamName) {
+			continue
+		}
+
+		if user.TeamName == teamName {
+			user.TeamName = newTeamName
+		}
+		user = withMembership(withoutMembership(user, teamName), newTeamName)
+		tr.db.Users[id] = user
+	}
+
+	for id, pr := range tr.db.PRs {
+		if pr.TeamName == teamName {
+			pr.TeamName = newTeamName
+			tr.db.PRs[id] = pr
+		}
+	}
+
+	for name, child := range tr.db.Teams {
+		if child.ParentName == teamName {
+			child.ParentName = newTeamName
+			tr.db.Teams[name] = child
+		}
+	}
+
+	if channel, exists := tr.db.ChatChannels[teamName]; exists {
+		delete(tr.db.ChatChannels, teamName)
+		channel.TeamName = newTeamName
+		tr.db.ChatChannels[newTeamName] = channel
+	}
+
+	delete(tr.db.Teams, teamName)
+	team.Name = newTeamName
+	tr.db.Teams[newTeamName] = team
+
+	return nil
+}
+
+func (tr *TeamRepo) Archive(ctx context.Context, teamName domain.TeamName) (domain.Team, error) {
+	team, exists := tr.db.Teams[teamName]
+	if !exists {
+		return domain.Team{}, domain.ErrNotFound
+	}
+
+	if team.ArchivedAt == nil {
+		now := time.Now()
+		team.ArchivedAt = &now
+		tr.db.Teams[teamName] = team
+	}
+
+	return tr.TeamByName(ctx, teamName)
+}
+
+func (tr *TeamRepo) Delete(_ context.Context, teamName domain.TeamName) error {
+	if _, exists := tr.db.Teams[teamName]; !exists {
+		return domain.ErrNotFound
+	}
+
+	for _, user := range tr.db.Users {
+		if user.IsMemberOf(teamName) {
+			return domain.ErrTeamNotEmpty
+		}
+	}
+
+	for _, pr := range tr.db.PRs {
+		if pr.TeamName == teamName {
+			return domain.ErrTeamNotEmpty
+		}
+	}
+
+	for _, child := range tr.db.Teams {
+		if child.ParentName == teamName {
+			return domain.ErrTeamNotEmpty
+		}
+	}
+
+	delete(tr.db.Teams, teamName)
+	delete(tr.db.ChatChannels, teamName)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"slices"
+	"time"
+)
+
+type APITokenRepo struct {
+	db *InMemoryStorage
+}
+
+func NewAPITokenRepo(db *InMemoryStorage) *APITokenRepo {
+	return &APITokenRepo{
+		db: db,
+	}
+}
+
+func (tr *APITokenRepo) CreateToken(_ context.Context, token domain.APIToken) (domain.APIToken, error) {
+	token.ID = nextID(tr.db.APITokens)
+	token.Scopes = slices.Clone(token.Scopes)
+	token.CreatedAt = time.Now()
+	tr.db.APITokens[token.ID] = token
+
+	return token, nil
+}
+
+func (tr *APITokenRepo) Tokens(_ context.Context) ([]domain.APIToken, error) {
+	tokens := []domain.APIToken{}
+	for _, token := range tr.db.APITokens {
+		tokens = append(tokens, token)
+	}
+
+	slices.SortFunc(tokens, func(a, b domain.APIToken) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return tokens, nil
+}
+
+func (tr *APITokenRepo) UseToken(_ context.Context, hash string, now time.Time) (domain.APIToken, error) {
+	for id, token := range tr.db.APITokens {
+		if token.Hash == hash && token.RevokedAt == nil {
+			token.LastUsedAt = &now
+			tr.db.APITokens[id] = token
+			return token, nil
+		}
+	}
+
+	return domain.APIToken{}, domain.ErrNotFound
+}
+
+func (tr *APITokenRepo) RevokeToken(_ context.Context, tokenID int64, now time.Time) (domain.APIToken, error) {
+	token, exists := tr.db.APITokens[tokenID]
+	if !exists {
+		return domain.APIToken{}, domain.ErrNotFound
+	}
+
+	if token.RevokedAt == nil {
+		token.RevokedAt = &now
+		tr.db.APITokens[tokenID] = token
+	}
+
+	return token, nil
+}
@@ -0,0 +1,102 @@
+package inmemory
+
+import (
+	"context"
+	"maps"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+	"strings"
+)
+
+// UnitOfWork runs one transaction at a time, so a transaction holds every
+// lock it could ask for. Only units of work are serialised, repositories
+// used outside of them are not.
+type UnitOfWork struct {
+	db *InMemoryStorage
+}
+
+func NewUnitOfWork(db *InMemoryStorage) *UnitOfWork {
+	return &UnitOfWork{
+		db: db,
+	}
+}
+
+// WithinTx keeps copies of the teams, users, pull requests and their events
+// and puts them back when fn fails.
+func (uow *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, tx repository.Tx) error) error {
+	uow.db.txMu.Lock()
+	defer uow.db.txMu.Unlock()
+
+	teams := maps.Clone(uow.db.Teams)
+	users := maps.Clone(uow.db.Users)
+	prs := maps.Clone(uow.db.PRs)
+	events := slices.Clone(uow.db.PREvents)
+	deliveries := maps.Clone(uow.db.WebhookDeliveries)
+
+	if err := fn(ctx, unitTx{db: uow.db}); err != nil {
+		uow.db.Teams = teams
+		uow.db.Users = users
+		uow.db.PRs = prs
+		uow.db.PREvents = events
+		uow.db.WebhookDeliveries = deliveries
+
+		return err
+	}
+
+	return nil
+}
+
+// WithinSnapshot keeps units of work from changing the data while fn reads
+// it.
+func (uow *UnitOfWork) WithinSnapshot(ctx context.Context, fn func(ctx context.Context, tx repository.Tx) error) error {
+	uow.db.txMu.Lock()
+	defer uow.db.txMu.Unlock()
+
+	return fn(ctx, unitTx{db: uow.db})
+}
+
+type unitTx struct {
+	db *InMemoryStorage
+}
+
+func (ut unitTx) Teams() repository.TeamRepository {
+	return NewTeamRepo(ut.db)
+}
+
+func (ut unitTx) Users() repository.UserRepository {
+	return NewUserRepo(ut.db)
+}
+
+func (ut unitTx) PullRequests() repository.PullRequestRepository {
+	return NewPullRequestRepo(ut.db)
+}
+
+func (ut unitTx) Org() repository.OrgRepository {
+	return NewOrgRepo(ut.db)
+}
+
+func (ut unitTx) LockPullRequest(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+	return NewPullRequestRepo(ut.db).PullRequestByID(ctx, pullRequestID)
+}
+
+func (ut unitTx) LockUsers(_ context.Context, userIDs []domain.UserID) ([]domain.User, error) {
+	users := []domain.User{}
+	for _, userID := range userIDs {
+		if user, exists := ut.db.Users[userID]; exists {
+			users = append(users, user)
+		}
+	}
+
+	slices.SortFunc(users, func(a, b domain.User) int {
+		return strings.Compare(string(a.ID), string(b.ID))
+	})
+
+	return slices.CompactFunc(users, func(a, b domain.User) bool {
+		return a.ID == b.ID
+	}), nil
+}
+
+func (ut unitTx) LockUsersForUpdate(ctx context.Context, userIDs []domain.UserID) ([]domain.User, error) {
+	return ut.LockUsers(ctx, userIDs)
+}
@@ -3,6 +3,9 @@ package inmemory
 import (
 	"context"
 	"pr-reviewer-service/internal/domain"
+	"slices"
+	"strings"
+	"time"
 )
 
 type UserRepo struct {
@@ -16,12 +19,25 @@ func NewUserRepo(db *InMemoryStorage) *UserRepo {
 }
 
 func (ur *UserRepo) Create(ctx context.Context, user domain.User) error {
-	ur.db.Users[user.ID] = user
+	if existing, exists := ur.db.Users[user.ID]; exists {
+		teams := user.Teams
+		user = withProfile(user, existing)
+		user.Teams = existing.Teams
+		for _, teamName := range teams {
+			user = withMembership(user, teamName)
+		}
+	}
+
+	if user.Role == "" {
+		user.Role = domain.RoleMember
+	}
+
+	ur.db.Users[user.ID] = withMembership(user, user.TeamName)
 	return nil
 }
 
 func (ur *UserRepo) UserByID(ctx context.Context, userID domain.UserID) (domain.User, error) {
-	user, exists := ur.db.Users[userID]
+	user, exists := ur.user(userID)
 	if !exists {
 		return domain.User{}, domain.ErrNotFound
 	}
@@ -29,8 +45,58 @@ func (ur *UserRepo) UserByID(ctx context.Context, userID domain.UserID) (domain.
 	return user, nil
 }
 
+func (ur *UserRepo) List(_ context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	users := []domain.User{}
+	for _, user := range ur.db.Users {
+		if filter.Matches(user) {
+			users = append(users, user)
+		}
+	}
+
+	slices.SortFunc(users, func(a, b domain.User) int {
+		return strings.Compare(string(a.ID), string(b.ID))
+	})
+
+	if filter.Limit > 0 && len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+
+	return users, nil
+}
+
+func (ur *UserRepo) UpdateProfile(_ context.Context, user domain.User) (domain.User, error) {
+	existing, exists := ur.user(user.ID)
+	if !exists {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	existing.Username = user.Username
+	existing.FullName = user.FullName
+	existing.Email = user.Email
+	existing.Role = user.Role
+	ur.db.Users[user.ID] = existing
+
+	return existing, nil
+}
+
+func (ur *UserRepo) Delete(_ context.Context, userID domain.UserID) error {
+	user, exists := ur.user(userID)
+	if !exists {
+		return domain.ErrNotFound
+	}
+
+	now := time.Now()
+	user.DeletedAt = &now
+	user.IsActive = false
+	user.TeamName = ""
+	user.Teams = nil
+	ur.db.Users[userID] = user
+
+	return nil
+}
+
 func (ur *UserRepo) SetIsActiveByID(_ context.Context, userID domain.UserID, isActive bool) (domain.User, error) {
-	user, exists := ur.db.Users[userID]
+	user, exists := ur.user(userID)
 	if !exists {
 		return domain.User{}, domain.ErrNotFound
 	}
@@ -41,14 +107,115 @@ func (ur *UserRepo) SetIsActiveByID(_ context.Context, userID domain.UserID, isA
 	return user, nil
 }
 
+func (ur *UserRepo) SetTeamByID(_ context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error) {
+	user, exists := ur.user(userID)
+	if !exists {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if _, exists := ur.db.Teams[teamName]; teamName != "" && !exists {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	user = withoutMembership(user, user.TeamName)
+	user.TeamName = teamName
+	user = withMembership(user, teamName)
+	ur.db.Users[userID] = user
+
+	return user, nil
+}
+
+func (ur *UserRepo) AddMembership(_ context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error) {
+	user, exists := ur.user(userID)
+	if !exists {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if _, exists := ur.db.Teams[teamName]; !exists {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if user.TeamName == "" {
+		user.TeamName = teamName
+	}
+	user = withMembership(user, teamName)
+	ur.db.Users[userID] = user
+
+	return user, nil
+}
+
+func (ur *UserRepo) RemoveMembership(_ context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error) {
+	user, exists := ur.user(userID)
+	if !exists || !user.IsMemberOf(teamName) {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	user = withoutMembership(user, teamName)
+	if user.TeamName == teamName {
+		user.TeamName = ""
+		if len(user.Teams) > 0 {
+			user.TeamName = slices.Min(user.Teams)
+		}
+	}
+	ur.db.Users[userID] = user
+
+	return user, nil
+}
+
 func (ur *UserRepo) ActiveUsersByTeamName(_ context.Context, teamName domain.TeamName) ([]domain.User, error) {
 	users := []domain.User{}
 
+	if team, exists := ur.db.Teams[teamName]; exists && team.IsArchived() {
+		return users, nil
+	}
+
 	for _, member := range ur.db.Users {
-		if member.TeamName == teamName && member.IsActive {
+		if member.IsMemberOf(teamName) && member.IsActive {
 			users = append(users, member)
 		}
 	}
 
 	return users, nil
 }
+
+// user returns a user that was not deleted.
+func (ur *UserRepo) user(userID domain.UserID) (domain.User, bool) {
+	user, exists := ur.db.Users[userID]
+	if !exists || user.IsDeleted() {
+		return domain.User{}, false
+	}
+
+	return user, true
+}
+
+// withProfile fills profile fields the user was created without from the
+// stored user, so that re-adding someone to a team keeps their profile.
+func withProfile(user domain.User, existing domain.User) domain.User {
+	if user.FullName == "" {
+		user.FullName = existing.FullName
+	}
+	if user.Email == "" {
+		user.Email = existing.Email
+	}
+	if user.Role == "" {
+		user.Role = existing.Role
+	}
+
+	return user
+}
+
+func withMembership(user domain.User, teamName domain.TeamName) domain.User {
+	if teamName != "" && !slices.Contains(user.Teams, teamName) {
+		user.Teams = append(slices.Clone(user.Teams), teamName)
+	}
+
+	return user
+}
+
+func withoutMembership(user domain.User, teamName domain.TeamName) domain.User {
+	user.Teams = slices.DeleteFunc(slices.Clone(user.Teams), func(tn domain.TeamName) bool {
+		return tn == teamName
+	})
+
+	return user
+}
@@ -0,0 +1,141 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"slices"
+	"time"
+)
+
+type WebhookRepo struct {
+	db *InMemoryStorage
+}
+
+func NewWebhookRepo(db *InMemoryStorage) *WebhookRepo {
+	return &WebhookRepo{
+		db: db,
+	}
+}
+
+func (wr *WebhookRepo) CreateSubscription(_ context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	subscription.ID = nextID(wr.db.Webhooks)
+	subscription.EventTypes = slices.Clone(subscription.EventTypes)
+	subscription.CreatedAt = time.Now()
+	wr.db.Webhooks[subscription.ID] = subscription
+
+	return subscription, nil
+}
+
+func (wr *WebhookRepo) Subscriptions(_ context.Context) ([]domain.WebhookSubscription, error) {
+	subscriptions := []domain.WebhookSubscription{}
+	for _, subscription := range wr.db.Webhooks {
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	slices.SortFunc(subscriptions, func(a, b domain.WebhookSubscription) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return subscriptions, nil
+}
+
+func (wr *WebhookRepo) DeleteSubscription(_ context.Context, subscriptionID int64) error {
+	if _, exists := wr.db.Webhooks[subscriptionID]; !exists {
+		return domain.ErrNotFound
+	}
+
+	delete(wr.db.Webhooks, subscriptionID)
+	for id, delivery := range wr.db.WebhookDeliveries {
+		if delivery.Subscription.ID == subscriptionID {
+			delete(wr.db.WebhookDeliveries, id)
+		}
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepo) ClaimDeliveries(_ context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	due := wr.deliveries(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now)
+	})
+
+	slices.SortFunc(due, func(a, b domain.WebhookDelivery) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		wr.db.WebhookDeliveries[due[i].ID] = due[i]
+	}
+
+	return due, nil
+}
+
+func (wr *WebhookRepo) UpdateDelivery(_ context.Context, delivery domain.WebhookDelivery) error {
+	stored, exists := wr.db.WebhookDeliveries[delivery.ID]
+	if !exists {
+		return domain.ErrNotFound
+	}
+
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastError = delivery.LastError
+	stored.DeliveredAt = delivery.DeliveredAt
+	wr.db.WebhookDeliveries[delivery.ID] = stored
+
+	return nil
+}
+
+func (wr *WebhookRepo) DeadDeliveries(_ context.Context, subscriptionID int64) ([]domain.WebhookDelivery, error) {
+	dead := wr.deliveries(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.DeliveryDead && (subscriptionID == 0 || d.Subscription.ID == subscriptionID)
+	})
+
+	slices.SortFunc(dead, func(a, b domain.WebhookDelivery) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return dead, nil
+}
+
+func (wr *WebhookRepo) Redeliver(_ context.Context, deliveryID int64) (domain.WebhookDelivery, error) {
+	delivery, exists := wr.db.WebhookDeliveries[deliveryID]
+	if !exists || delivery.Status != domain.DeliveryDead {
+		return domain.WebhookDelivery{}, fmt.Errorf("%w: dead delivery %d", domain.ErrNotFound, deliveryID)
+	}
+
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	wr.db.WebhookDeliveries[deliveryID] = delivery
+
+	return delivery, nil
+}
+
+func (wr *WebhookRepo) deliveries(include func(domain.WebhookDelivery) bool) []domain.WebhookDelivery {
+	deliveries := []domain.WebhookDelivery{}
+	for _, delivery := range wr.db.WebhookDeliveries {
+		if include(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return deliveries
+}
+
+// nextID returns one above the largest ID in use.
+func nextID[T any](items map[int64]T) int64 {
+	var last int64
+	for id := range items {
+		last = max(last, id)
+	}
+
+	return last + 1
+}
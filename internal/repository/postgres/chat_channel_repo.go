@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ChatChannelRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewChatChannelRepo(db *pgxpool.Pool) *ChatChannelRepo {
+	return &ChatChannelRepo{
+		db: db,
+	}
+}
+
+func (cr *ChatChannelRepo) SetChatChannel(ctx context.Context, channel domain.ChatChannel) (domain.ChatChannel, error) {
+	setQuery := `
+		INSERT INTO team_chat_channels (team_name, webhook_url, templates)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_name) DO UPDATE
+		SET webhook_url = EXCLUDED.webhook_url, templates = EXCLUDED.templates
+	`
+
+	templates := channel.Templates
+	if templates == nil {
+		templates = map[domain.PREventKind]string{}
+	}
+
+	if _, err := cr.db.Exec(ctx, setQuery, channel.TeamName, channel.WebhookURL, templates); err != nil {
+		return domain.ChatChannel{}, mapForeignKeyError(err)
+	}
+
+	return channel, nil
+}
+
+func (cr *ChatChannelRepo) ChatChannel(ctx context.Context, teamName domain.TeamName) (domain.ChatChannel, error) {
+	channelQuery := `
+		SELECT team_name, webhook_url, templates
+		FROM team_chat_channels
+		WHERE team_name = $1
+	`
+
+	var channel domain.ChatChannel
+	err := cr.db.QueryRow(ctx, channelQuery, teamName).Scan(&channel.TeamName, &channel.WebhookURL, &channel.Templates)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ChatChannel{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.ChatChannel{}, err
+	}
+
+	return channel, nil
+}
+
+func (cr *ChatChannelRepo) DeleteChatChannel(ctx context.Context, teamName domain.TeamName) error {
+	deleteQuery := `DELETE FROM team_chat_channels WHERE team_name = $1`
+
+	tag, err := cr.db.Exec(ctx, deleteQuery, teamName)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
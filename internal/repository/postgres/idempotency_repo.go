@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepo(db *pgxpool.Pool) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		db: db,
+	}
+}
+
+// ClaimIdempotencyKey takes over expired keys in the same statement, so that
+// of two concurrent requests with the same key only one gets it.
+func (ir *IdempotencyRepo) ClaimIdempotencyKey(ctx context.Context, request domain.IdempotentRequest, now time.Time) (domain.IdempotentRequest, bool, error) {
+	claimQuery := `
+		INSERT INTO idempotency_keys (idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING idempotency_key
+	`
+
+	existingQuery := `
+		SELECT idempotency_key, request_hash, COALESCE(status_code, 0), response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE idempotency_key = $1
+	`
+
+	// the holder of the key may delete it in between, then claim it again
+	for {
+		var key string
+		err := ir.db.QueryRow(ctx, claimQuery, request.Key, request.RequestHash, now, request.ExpiresAt).Scan(&key)
+		if err == nil {
+			request.StatusCode = 0
+			request.Body = nil
+			request.CreatedAt = now
+			return request, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return domain.IdempotentRequest{}, false, err
+		}
+
+		var existing domain.IdempotentRequest
+		err = ir.db.QueryRow(ctx, existingQuery, request.Key).Scan(
+			&existing.Key,
+			&existing.RequestHash,
+			&existing.StatusCode,
+			&existing.Body,
+			&existing.CreatedAt,
+			&existing.ExpiresAt,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return domain.IdempotentRequest{}, false, err
+		}
+
+		return existing, false, nil
+	}
+}
+
+func (ir *IdempotencyRepo) CompleteIdempotentRequest(ctx context.Context, key string, statusCode int, body []byte, expiresAt time.Time) error {
+	completeQuery := `
+		UPDATE idempotency_keys
+		SET status_code = $2, response_body = $3, expires_at = $4
+		WHERE idempotency_key = $1
+	`
+
+	tag, err := ir.db.Exec(ctx, completeQuery, key, statusCode, body, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (ir *IdempotencyRepo) DeleteIdempotentRequest(ctx context.Context, key string) error {
+	_, err := ir.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = $1`, key)
+	return err
+}
+
+func (ir *IdempotencyRepo) DeleteExpiredIdempotentRequests(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := ir.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdentityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepo(db *pgxpool.Pool) *IdentityRepo {
+	return &IdentityRepo{
+		db: db,
+	}
+}
+
+func (ir *IdentityRepo) LinkIdentity(ctx context.Context, identity domain.UserIdentity) (domain.UserIdentity, error) {
+	linkQuery := `
+		INSERT INTO user_identities (provider, external_id, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, external_id) DO UPDATE
+		SET user_id = EXCLUDED.user_id
+	`
+
+	if _, err := ir.db.Exec(ctx, linkQuery, identity.Provider, identity.ExternalID, identity.UserID); err != nil {
+		return domain.UserIdentity{}, mapForeignKeyError(err)
+	}
+
+	return identity, nil
+}
+
+func (ir *IdentityRepo) UnlinkIdentity(ctx context.Context, provider domain.IdentityProvider, externalID string) error {
+	unlinkQuery := `DELETE FROM user_identities WHERE provider = $1 AND external_id = $2`
+
+	tag, err := ir.db.Exec(ctx, unlinkQuery, provider, externalID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (ir *IdentityRepo) UserIDByIdentity(ctx context.Context, provider domain.IdentityProvider, externalID string) (domain.UserID, error) {
+	userQuery := `
+		SELECT user_id
+		FROM user_identities
+		WHERE provider = $1 AND external_id = $2
+	`
+
+	var userID domain.UserID
+	err := ir.db.QueryRow(ctx, userQuery, provider, externalID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+func (ir *IdentityRepo) IdentitiesByUserID(ctx context.Context, userID domain.UserID) ([]domain.UserIdentity, error) {
+	identitiesQuery := `
+		SELECT user_id, provider, external_id
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY provider, external_id
+	`
+
+	rows, err := ir.db.Query(ctx, identitiesQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []domain.UserIdentity{}
+	for rows.Next() {
+		var identity domain.UserIdentity
+		if err := rows.Scan(&identity.UserID, &identity.Provider, &identity.ExternalID); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
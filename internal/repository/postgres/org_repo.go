@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type OrgRepo struct {
+	db DBTX
+}
+
+func NewOrgRepo(db DBTX) *OrgRepo {
+	return &OrgRepo{
+		db: db,
+	}
+}
+
+func (or *OrgRepo) Snapshot(ctx context.Context) (domain.OrgSnapshot, error) {
+	tx, err := or.beginSnapshot(ctx)
+	if err != nil {
+		return domain.OrgSnapshot{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	teamsQuery := `
+		SELECT team_name, COALESCE(parent_team_name, ''), archived_at
+		FROM teams
+		ORDER BY team_name
+	`
+
+	rows, err := tx.Query(ctx, teamsQuery)
+	if err != nil {
+		return domain.OrgSnapshot{}, err
+	}
+	defer rows.Close()
+
+	snapshot := domain.OrgSnapshot{
+		Teams: []domain.Team{},
+		Users: []domain.User{},
+	}
+
+	for rows.Next() {
+		var team domain.Team
+		if err := rows.Scan(&team.Name, &team.ParentName, &team.ArchivedAt); err != nil {
+			return domain.OrgSnapshot{}, err
+		}
+		snapshot.Teams = append(snapshot.Teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.OrgSnapshot{}, err
+	}
+
+	usersQuery := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE u.deleted_at IS NULL
+		ORDER BY u.user_id
+	`
+
+	rows, err = tx.Query(ctx, usersQuery)
+	if err != nil {
+		return domain.OrgSnapshot{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return domain.OrgSnapshot{}, err
+		}
+		snapshot.Users = append(snapshot.Users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.OrgSnapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// beginSnapshot reads from one snapshot of the database. Within a unit of
+// work the snapshot is the one of the unit of work.
+func (or *OrgRepo) beginSnapshot(ctx context.Context) (pgx.Tx, error) {
+	if db, ok := or.db.(interface {
+		BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	}); ok {
+		return db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	}
+
+	return or.db.Begin(ctx)
+}
+
+func (or *OrgRepo) Apply(ctx context.Context, changes []domain.OrgChange) error {
+	tx, err := or.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, change := range changes {
+		if err := applyOrgChange(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func applyOrgChange(ctx context.Context, tx pgx.Tx, change domain.OrgChange) error {
+	var (
+		query string
+		args  []any
+	)
+
+	switch change.Kind {
+	case domain.ChangeCreateTeam:
+		query = `INSERT INTO teams (team_name, parent_team_name) VALUES ($1, NULLIF($2, ''))`
+		args = []any{change.TeamName, change.ParentName}
+
+	case domain.ChangeSetTeamParent:
+		query = `UPDATE teams SET parent_team_name = NULLIF($2, '') WHERE team_name = $1`
+		args = []any{change.TeamName, change.ParentName}
+
+	case domain.ChangeArchiveTeam:
+		query = `UPDATE teams SET archived_at = COALESCE(archived_at, NOW()) WHERE team_name = $1`
+		args = []any{change.TeamName}
+
+	case domain.ChangeCreateUser:
+		query = `
+			INSERT INTO users (user_id, username, full_name, role, is_active, email)
+			VALUES ($1, $2, $3, $4::user_role, $5, $6)
+			ON CONFLICT (user_id) DO UPDATE
+			SET
+				username = EXCLUDED.username,
+				full_name = EXCLUDED.full_name,
+				email = EXCLUDED.email,
+				role = EXCLUDED.role,
+				is_active = EXCLUDED.is_active,
+				team_name = NULL,
+				deleted_at = NULL
+		`
+		args = []any{change.User.ID, change.User.Username, change.User.FullName, change.User.Role, change.User.IsActive, change.User.Email}
+
+	case domain.ChangeUpdateUser:
+		query = `
+			UPDATE users
+			SET username = $2, full_name = $3, role = $4::user_role, is_active = $5, email = $6
+			WHERE user_id = $1 AND deleted_at IS NULL
+		`
+		args = []any{change.User.ID, change.User.Username, change.User.FullName, change.User.Role, change.User.IsActive, change.User.Email}
+
+	case domain.ChangeAddMembership:
+		return addMembership(ctx, tx, change.User.ID, change.TeamName)
+
+	case domain.ChangeSetHomeTeam:
+		if change.TeamName != "" {
+			if err := addMembership(ctx, tx, change.User.ID, change.TeamName); err != nil {
+				return err
+			}
+		}
+		query = `UPDATE users SET team_name = NULLIF($2, '') WHERE user_id = $1`
+		args = []any{change.User.ID, change.TeamName}
+
+	case domain.ChangeRemoveMembership:
+		if _, err := tx.Exec(ctx, `DELETE FROM team_memberships WHERE user_id = $1 AND team_name = $2`, change.User.ID, change.TeamName); err != nil {
+			return err
+		}
+		query = `UPDATE users SET team_name = NULL WHERE user_id = $1 AND team_name = $2`
+		args = []any{change.User.ID, change.TeamName}
+
+	default:
+		return nil
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrTeamExists
+		}
+
+		return mapForeignKeyError(err)
+	}
+
+	if tag.RowsAffected() == 0 && change.Kind != domain.ChangeRemoveMembership {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
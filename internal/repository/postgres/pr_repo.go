@@ -3,40 +3,71 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"pr-reviewer-service/internal/domain"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// pullRequestColumns selects a pull request with its reviewers, the query
+// must alias pull_requests as pr, LEFT JOIN pull_request_reviewers as prr and
+// group by pr.pull_request_id.
+const pullRequestColumns = `
+	pr.pull_request_id,
+	pr.pull_request_name,
+	pr.author_id,
+	COALESCE(pr.team_name, ''),
+	pr.status,
+	pr.created_at,
+	pr.merged_at,
+	pr.closed_at,
+	COALESCE(ARRAY_AGG(prr.user_id) FILTER (WHERE prr.user_id IS NOT NULL), '{}') AS assigned_reviewers,
+	COALESCE(JSON_OBJECT_AGG(prr.user_id, prr.review_state) FILTER (WHERE prr.user_id IS NOT NULL), '{}') AS review_states
+`
+
 type PullRequestRepo struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewPullRequestRepo(db *pgxpool.Pool) *PullRequestRepo {
+func NewPullRequestRepo(db DBTX) *PullRequestRepo {
 	return &PullRequestRepo{
 		db: db,
 	}
 }
 
 func (prr *PullRequestRepo) Create(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	return prr.create(ctx, pr, insertEvent)
+}
+
+func (prr *PullRequestRepo) Restore(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	return prr.create(ctx, pr, recordEvent)
+}
+
+// create stores the pull request with its reviewers and hands the events of
+// its creation, and of its merge or close when it is no longer open, to
+// addEvent.
+func (prr *PullRequestRepo) create(ctx context.Context, pr domain.PullRequest, addEvent func(ctx context.Context, tx pgx.Tx, event domain.PREvent) error) (domain.PullRequest, error) {
 	tx, err := prr.db.Begin(ctx)
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
 	defer tx.Rollback(ctx)
 
+	var createdAt *time.Time
+	if !pr.CreatedAt.IsZero() {
+		createdAt = &pr.CreatedAt
+	}
+
 	createPRQuery := `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status, created_at, merged_at, closed_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, COALESCE($6, NOW()), $7, $8)
 		RETURNING created_at
 	`
 
-	var createdAt time.Time
-	err = tx.QueryRow(ctx, createPRQuery, pr.ID, pr.Name, pr.AuthorID, pr.Status).
-		Scan(&createdAt)
+	err = tx.QueryRow(ctx, createPRQuery, pr.ID, pr.Name, pr.AuthorID, pr.TeamName, pr.Status, createdAt, pr.MergedAt, pr.ClosedAt).
+		Scan(&pr.CreatedAt)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -46,17 +77,15 @@ func (prr *PullRequestRepo) Create(ctx context.Context, pr domain.PullRequest) (
 		return domain.PullRequest{}, err
 	}
 
-	pr.CreatedAt = createdAt
-
 	if len(pr.AssignedReviewers) > 0 {
 		insertReviewersQuery := `
-			INSERT INTO pull_request_reviewers (pull_request_id, user_id)
-			VALUES ($1, $2)
+			INSERT INTO pull_request_reviewers (pull_request_id, user_id, review_state)
+			VALUES ($1, $2, COALESCE(NULLIF($3, '')::review_state, 'PENDING'))
 		`
 
 		batch := &pgx.Batch{}
 		for _, reviewerID := range pr.AssignedReviewers {
-			batch.Queue(insertReviewersQuery, pr.ID, reviewerID)
+			batch.Queue(insertReviewersQuery, pr.ID, reviewerID, pr.ReviewStates[reviewerID])
 		}
 
 		batchRes := tx.SendBatch(ctx, batch)
@@ -65,6 +94,37 @@ func (prr *PullRequestRepo) Create(ctx context.Context, pr domain.PullRequest) (
 		}
 	}
 
+	err = addEvent(ctx, tx, domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventCreated, UserID: pr.AuthorID, CreatedAt: pr.CreatedAt})
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		err := addEvent(ctx, tx, domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventReviewerAssigned, UserID: reviewerID, CreatedAt: pr.CreatedAt})
+		if err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
+	var finished *domain.PREvent
+	switch pr.Status {
+	case domain.StatusMerged:
+		finished = &domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventMerged}
+		if pr.MergedAt != nil {
+			finished.CreatedAt = *pr.MergedAt
+		}
+	case domain.StatusClosed:
+		finished = &domain.PREvent{PullRequestID: pr.ID, Kind: domain.EventClosed}
+		if pr.ClosedAt != nil {
+			finished.CreatedAt = *pr.ClosedAt
+		}
+	}
+	if finished != nil {
+		if err := addEvent(ctx, tx, *finished); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return domain.PullRequest{}, err
 	}
@@ -88,14 +148,58 @@ func (prr *PullRequestRepo) MergeByID(ctx context.Context, pullRequestID domain.
 		SET
 			status = 'MERGED',
 			merged_at = COALESCE(merged_at, NOW())
-		WHERE pull_request_id = $1
+		WHERE pull_request_id = $1 AND status <> 'MERGED'
+	`
+
+	tag, err := tx.Exec(ctx, mergeQuery, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if tag.RowsAffected() > 0 {
+		if err := insertEvent(ctx, tx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventMerged}); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
+	pullRequest, err := prr.pullRequestByID(ctx, tx, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pullRequest, nil
+}
+
+func (prr *PullRequestRepo) CloseByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+	tx, err := prr.db.Begin(ctx)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	closeQuery := `
+		UPDATE pull_requests
+		SET
+			status = 'CLOSED',
+			closed_at = COALESCE(closed_at, NOW())
+		WHERE pull_request_id = $1 AND status <> 'CLOSED'
 	`
 
-	_, err = tx.Exec(ctx, mergeQuery, pullRequestID)
+	tag, err := tx.Exec(ctx, closeQuery, pullRequestID)
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
 
+	if tag.RowsAffected() > 0 {
+		if err := insertEvent(ctx, tx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventClosed}); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
 	pullRequest, err := prr.pullRequestByID(ctx, tx, pullRequestID)
 	if err != nil {
 		return domain.PullRequest{}, err
@@ -108,7 +212,93 @@ func (prr *PullRequestRepo) MergeByID(ctx context.Context, pullRequestID domain.
 	return pullRequest, nil
 }
 
-func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID domain.PullRequestID, oldUserID domain.UserID, newUserID domain.UserID) (domain.PullRequest, domain.UserID, error) {
+func (prr *PullRequestRepo) ReopenByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+	tx, err := prr.db.Begin(ctx)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	reopenQuery := `
+		UPDATE pull_requests
+		SET
+			status = 'OPEN',
+			closed_at = NULL
+		WHERE pull_request_id = $1 AND status = 'CLOSED'
+	`
+
+	tag, err := tx.Exec(ctx, reopenQuery, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if tag.RowsAffected() > 0 {
+		if err := insertEvent(ctx, tx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventReopened}); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
+	pullRequest, err := prr.pullRequestByID(ctx, tx, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pullRequest, nil
+}
+
+func (prr *PullRequestRepo) SetAuthor(ctx context.Context, pullRequestID domain.PullRequestID, authorID domain.UserID) (domain.PullRequest, error) {
+	tx, err := prr.db.Begin(ctx)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The joined row still holds the author from before the update.
+	setAuthorQuery := `
+		UPDATE pull_requests pr
+		SET author_id = $2
+		FROM pull_requests previous
+		WHERE pr.pull_request_id = $1 AND previous.pull_request_id = $1
+		RETURNING previous.author_id
+	`
+
+	var previousAuthorID domain.UserID
+	if err := tx.QueryRow(ctx, setAuthorQuery, pullRequestID, authorID).Scan(&previousAuthorID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PullRequest{}, domain.ErrNotFound
+		}
+		return domain.PullRequest{}, mapForeignKeyError(err)
+	}
+
+	if previousAuthorID != authorID {
+		err := insertEvent(ctx, tx, domain.PREvent{
+			PullRequestID:  pullRequestID,
+			Kind:           domain.EventAuthorChanged,
+			UserID:         authorID,
+			PreviousUserID: previousAuthorID,
+		})
+		if err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
+	pullRequest, err := prr.pullRequestByID(ctx, tx, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pullRequest, nil
+}
+
+func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID domain.PullRequestID, oldUserID domain.UserID, newUserID domain.UserID, reason domain.ReassignReason) (domain.PullRequest, domain.UserID, error) {
 	if oldUserID == newUserID {
 		return domain.PullRequest{}, domain.UserID(""), domain.ErrNoCandidate
 	}
@@ -121,7 +311,7 @@ func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID
 
 	sqlReassign := `
 		UPDATE pull_request_reviewers
-		SET user_id = $1
+		SET user_id = $1, review_state = 'PENDING'
 		WHERE pull_request_id = $2 AND user_id = $3
 	`
 	tag, err := tx.Exec(ctx, sqlReassign, newUserID, pullRequestID, oldUserID)
@@ -147,6 +337,17 @@ func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID
 		return domain.PullRequest{}, domain.UserID(""), domain.ErrNotAssigned
 	}
 
+	err = insertEvent(ctx, tx, domain.PREvent{
+		PullRequestID:  pullRequestID,
+		Kind:           domain.EventReviewerReassigned,
+		UserID:         newUserID,
+		PreviousUserID: oldUserID,
+		Reason:         reason,
+	})
+	if err != nil {
+		return domain.PullRequest{}, domain.UserID(""), err
+	}
+
 	pr, err := prr.pullRequestByID(ctx, tx, pullRequestID)
 	if err != nil {
 		return domain.PullRequest{}, domain.UserID(""), err
@@ -159,29 +360,169 @@ func (prr *PullRequestRepo) ReassignReviewer(ctx context.Context, pullRequestID
 	return pr, newUserID, nil
 }
 
-func (prr *PullRequestRepo) PullRequestsByReviewer(ctx context.Context, userID domain.UserID) ([]domain.PullRequestShort, error) {
-	prByReviewerQuery := `
+func (prr *PullRequestRepo) RemoveReviewer(ctx context.Context, pullRequestID domain.PullRequestID, userID domain.UserID, reason domain.ReassignReason) (domain.PullRequest, error) {
+	tx, err := prr.db.Begin(ctx)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	removeReviewerQuery := `
+		DELETE FROM pull_request_reviewers
+		WHERE pull_request_id = $1 AND user_id = $2
+	`
+
+	tag, err := tx.Exec(ctx, removeReviewerQuery, pullRequestID, userID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	pr, err := prr.pullRequestByID(ctx, tx, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.PullRequest{}, domain.ErrNotAssigned
+	}
+
+	err = insertEvent(ctx, tx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventReviewerRemoved, UserID: userID, Reason: reason})
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) SetReviewState(ctx context.Context, pullRequestID domain.PullRequestID, reviewerID domain.UserID, state domain.ReviewState) (domain.PullRequest, error) {
+	tx, err := prr.db.Begin(ctx)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	setReviewStateQuery := `
+		UPDATE pull_request_reviewers
+		SET review_state = $3
+		WHERE pull_request_id = $1 AND user_id = $2
+	`
+
+	tag, err := tx.Exec(ctx, setReviewStateQuery, pullRequestID, reviewerID, state)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	pr, err := prr.pullRequestByID(ctx, tx, pullRequestID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.PullRequest{}, domain.ErrNotAssigned
+	}
+
+	err = insertEvent(ctx, tx, domain.PREvent{PullRequestID: pullRequestID, Kind: domain.EventReviewSubmitted, UserID: reviewerID, ReviewState: state})
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pr, nil
+}
+
+func (prr *PullRequestRepo) PullRequestsByReviewer(ctx context.Context, userID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequestShort, error) {
+	cursorOp, direction := ">", "ASC"
+	if filter.Order == domain.SortNewestFirst {
+		cursorOp, direction = "<", "DESC"
+	}
+
+	prByReviewerQuery := fmt.Sprintf(`
 		SELECT 
 			pr.pull_request_id,
 			pr.pull_request_name,
 			pr.author_id,
-			pr.status
+			COALESCE(pr.team_name, ''),
+			pr.status,
+			pr.created_at,
+			prr.review_state
 		FROM pull_requests pr
 		JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
 		WHERE prr.user_id = $1
-		ORDER BY pr.created_at DESC
-	`
-
-	rows, err := prr.db.Query(ctx, prByReviewerQuery, userID)
+			AND (CARDINALITY($2::text[]) = 0 OR pr.status::text = ANY($2::text[]))
+			AND ($3::timestamptz IS NULL OR (pr.created_at, pr.pull_request_id) %s ($3::timestamptz, $4::text))
+			AND ($6::text IS NULL OR pr.team_name = $6::text)
+		ORDER BY pr.created_at %s, pr.pull_request_id %s
+		LIMIT $5
+	`, cursorOp, direction, direction)
+
+	rows, err := prr.db.Query(ctx, prByReviewerQuery, filterArgs(userID, filter)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prs []domain.PullRequestShort
+	prs := []domain.PullRequestShort{}
 	for rows.Next() {
 		var pr domain.PullRequestShort
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status); err != nil {
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, &pr.Status, &pr.CreatedAt, &pr.ReviewState); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+func (prr *PullRequestRepo) PullRequestsByAuthor(ctx context.Context, authorID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	return prr.list(ctx, authorID, filter)
+}
+
+func (prr *PullRequestRepo) List(ctx context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	return prr.list(ctx, "", filter)
+}
+
+// list returns the pull requests matching the filter, an empty authorID
+// matches every author.
+func (prr *PullRequestRepo) list(ctx context.Context, authorID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	cursorOp, direction := ">", "ASC"
+	if filter.Order == domain.SortNewestFirst {
+		cursorOp, direction = "<", "DESC"
+	}
+
+	prByAuthorQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM pull_requests pr
+		LEFT JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE ($1 = '' OR pr.author_id = $1)
+			AND (CARDINALITY($2::text[]) = 0 OR pr.status::text = ANY($2::text[]))
+			AND ($3::timestamptz IS NULL OR (pr.created_at, pr.pull_request_id) %s ($3::timestamptz, $4::text))
+			AND ($6::text IS NULL OR pr.team_name = $6::text)
+		GROUP BY pr.pull_request_id
+		ORDER BY pr.created_at %s, pr.pull_request_id %s
+		LIMIT $5
+	`, pullRequestColumns, cursorOp, direction, direction)
+
+	rows, err := prr.db.Query(ctx, prByAuthorQuery, filterArgs(authorID, filter)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []domain.PullRequest{}
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
 			return nil, err
 		}
 		prs = append(prs, pr)
@@ -194,43 +535,176 @@ func (prr *PullRequestRepo) PullRequestsByReviewer(ctx context.Context, userID d
 	return prs, nil
 }
 
+func (prr *PullRequestRepo) Timeline(ctx context.Context, pullRequestID domain.PullRequestID) ([]domain.PREvent, error) {
+	timelineQuery := `
+		SELECT
+			event_id,
+			pull_request_id,
+			kind,
+			COALESCE(user_id, ''),
+			COALESCE(previous_user_id, ''),
+			COALESCE(review_state::text, ''),
+			COALESCE(reason, ''),
+			COALESCE(actor, ''),
+			created_at
+		FROM pr_events
+		WHERE pull_request_id = $1
+		ORDER BY event_id
+	`
+
+	rows, err := prr.db.Query(ctx, timelineQuery, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.PREvent{}
+	for rows.Next() {
+		var event domain.PREvent
+		err := rows.Scan(
+			&event.ID,
+			&event.PullRequestID,
+			&event.Kind,
+			&event.UserID,
+			&event.PreviousUserID,
+			&event.ReviewState,
+			&event.Reason,
+			&event.Actor,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// insertPREventQuery appends an event to the timeline, the actor is taken
+// from the context and a zero time means now.
+const insertPREventQuery = `
+	INSERT INTO pr_events (pull_request_id, kind, user_id, previous_user_id, review_state, reason, actor, created_at)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, '')::review_state, NULLIF($6, ''), NULLIF($7, ''), COALESCE($8, NOW()))
+`
+
+// insertEvent appends to the timeline within the transaction of the change
+// and queues the webhook deliveries of the event in the outbox.
+func insertEvent(ctx context.Context, tx pgx.Tx, event domain.PREvent) error {
+	insertEventQuery := `
+		WITH event AS (` + insertPREventQuery + `
+			RETURNING event_id, kind
+		)
+		INSERT INTO webhook_deliveries (subscription_id, event_id)
+		SELECT ws.subscription_id, event.event_id
+		FROM event
+		JOIN webhook_subscriptions ws ON event.kind = ANY(ws.event_types)
+	`
+
+	_, err := tx.Exec(ctx, insertEventQuery, eventArgs(ctx, event)...)
+	return err
+}
+
+// recordEvent appends to the timeline without notifying any subscription.
+func recordEvent(ctx context.Context, tx pgx.Tx, event domain.PREvent) error {
+	_, err := tx.Exec(ctx, insertPREventQuery, eventArgs(ctx, event)...)
+	return err
+}
+
+func eventArgs(ctx context.Context, event domain.PREvent) []any {
+	var createdAt *time.Time
+	if !event.CreatedAt.IsZero() {
+		createdAt = &event.CreatedAt
+	}
+
+	return []any{
+		event.PullRequestID,
+		event.Kind,
+		event.UserID,
+		event.PreviousUserID,
+		event.ReviewState,
+		event.Reason,
+		domain.ActorFromContext(ctx),
+		createdAt,
+	}
+}
+
+func filterArgs(userID domain.UserID, filter domain.PullRequestFilter) []any {
+	statuses := make([]string, len(filter.Statuses))
+	for i, status := range filter.Statuses {
+		statuses[i] = string(status)
+	}
+
+	var (
+		afterCreatedAt *time.Time
+		afterID        *string
+		limit          *int
+		teamName       *string
+	)
+
+	if filter.After != nil {
+		afterCreatedAt = &filter.After.CreatedAt
+		id := string(filter.After.ID)
+		afterID = &id
+	}
+
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	if filter.TeamName != "" {
+		tn := string(filter.TeamName)
+		teamName = &tn
+	}
+
+	return []any{userID, statuses, afterCreatedAt, afterID, limit, teamName}
+}
+
 type RowQuerier interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 func (prr *PullRequestRepo) pullRequestByID(ctx context.Context, rq RowQuerier, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
 	prByIDQuery := `
-		SELECT
-			pr.pull_request_id,
-			pr.pull_request_name,
-			pr.author_id,
-			pr.status,
-			pr.created_at,
-			pr.merged_at,
-			COALESCE(ARRAY_AGG(prr.user_id) FILTER (WHERE prr.user_id IS NOT NULL), '{}') AS assigned_reviewers
+		SELECT ` + pullRequestColumns + `
 		FROM pull_requests pr
 		LEFT JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
 		WHERE pr.pull_request_id = $1
 		GROUP BY pr.pull_request_id
 	`
 
+	pr, err := scanPullRequest(rq.QueryRow(ctx, prByIDQuery, pullRequestID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PullRequest{}, domain.ErrNotFound
+		}
+		return domain.PullRequest{}, err
+	}
+
+	return pr, nil
+}
+
+func scanPullRequest(row pgx.Row) (domain.PullRequest, error) {
 	var pr domain.PullRequest
 	var reviewers []domain.UserID
 
-	err := rq.QueryRow(ctx, prByIDQuery, pullRequestID).Scan(
+	err := row.Scan(
 		&pr.ID,
 		&pr.Name,
 		&pr.AuthorID,
+		&pr.TeamName,
 		&pr.Status,
 		&pr.CreatedAt,
 		&pr.MergedAt,
+		&pr.ClosedAt,
 		&reviewers,
+		&pr.ReviewStates,
 	)
-
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.PullRequest{}, domain.ErrNotFound
-		}
 		return domain.PullRequest{}, err
 	}
 
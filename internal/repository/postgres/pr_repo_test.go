@@ -0,0 +1,67 @@
+package postgres_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreQueuesNoWebhookDeliveries(t *testing.T) {
+	pool := setupDB(t)
+	ctx := context.Background()
+	createTeam(t, pool, "author", "reviewer")
+
+	_, err := postgres.NewWebhookRepo(pool).CreateSubscription(ctx, domain.WebhookSubscription{
+		URL: "https://example.com/hook", Secret: "secret",
+		EventTypes: []domain.PREventKind{domain.EventCreated, domain.EventReviewerAssigned},
+	})
+	require.NoError(t, err)
+
+	prRepo := postgres.NewPullRequestRepo(pool)
+	_, err = prRepo.Restore(ctx, domain.PullRequest{
+		ID: "pr-1", Name: "Test PR", AuthorID: "author", TeamName: "backend", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{"reviewer"},
+	})
+	require.NoError(t, err)
+
+	timeline, err := prRepo.Timeline(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Len(t, timeline, 2)
+
+	var deliveries int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_deliveries`).Scan(&deliveries))
+	assert.Zero(t, deliveries)
+
+	_, err = prRepo.Create(ctx, domain.PullRequest{
+		ID: "pr-2", Name: "Test PR", AuthorID: "author", TeamName: "backend", Status: domain.StatusOpen,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_deliveries`).Scan(&deliveries))
+	assert.Equal(t, 1, deliveries)
+}
+
+func TestRestoreRecordsHowThePullRequestEnded(t *testing.T) {
+	pool := setupDB(t)
+	ctx := context.Background()
+	createTeam(t, pool, "author", "reviewer")
+
+	closedAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	prRepo := postgres.NewPullRequestRepo(pool)
+	_, err := prRepo.Restore(ctx, domain.PullRequest{
+		ID: "pr-1", Name: "Test PR", AuthorID: "author", TeamName: "backend", Status: domain.StatusClosed,
+		CreatedAt: closedAt.Add(-time.Hour), ClosedAt: &closedAt,
+	})
+	require.NoError(t, err)
+
+	timeline, err := prRepo.Timeline(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	assert.Equal(t, domain.EventClosed, timeline[1].Kind)
+	assert.True(t, closedAt.Equal(timeline[1].CreatedAt))
+}
@@ -4,17 +4,17 @@ import (
 	"context"
 	"errors"
 	"pr-reviewer-service/internal/domain"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type TeamRepo struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewTeamRepo(db *pgxpool.Pool) *TeamRepo {
+func NewTeamRepo(db DBTX) *TeamRepo {
 	return &TeamRepo{
 		db: db,
 	}
@@ -27,24 +27,42 @@ func (tr *TeamRepo) Create(ctx context.Context, team domain.Team) error {
 	}
 	defer tx.Rollback(ctx)
 
-	createTeamQuery := `INSERT INTO teams (team_name) VALUES ($1)`
-	if _, err := tx.Exec(ctx, createTeamQuery, team.Name); err != nil {
+	createTeamQuery := `INSERT INTO teams (team_name, parent_team_name, archived_at) VALUES ($1, NULLIF($2, ''), $3)`
+	if _, err := tx.Exec(ctx, createTeamQuery, team.Name, team.ParentName, team.ArchivedAt); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			return domain.ErrTeamExists
 		}
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrNotFound
+		}
 
 		return err
 	}
 
+	memberIDs := make([]domain.UserID, len(team.Members))
+	for i, member := range team.Members {
+		memberIDs[i] = member.UserID
+	}
+
+	dropHomeMembershipsQuery := `
+		DELETE FROM team_memberships tm
+		USING users u
+		WHERE u.user_id = tm.user_id AND tm.team_name = u.team_name AND u.user_id = ANY($1)
+	`
+	if _, err := tx.Exec(ctx, dropHomeMembershipsQuery, memberIDs); err != nil {
+		return err
+	}
+
 	createUserQuery := `
 		INSERT INTO users(user_id, username, team_name, is_active)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
 		ON CONFLICT (user_id) DO UPDATE
 		SET
 			username = EXCLUDED.username,
 			team_name = EXCLUDED.team_name,
-			is_active = EXCLUDED.is_active
+			is_active = EXCLUDED.is_active,
+			deleted_at = NULL
 	`
 
 	batch := &pgx.Batch{}
@@ -57,14 +75,24 @@ func (tr *TeamRepo) Create(ctx context.Context, team domain.Team) error {
 		return err
 	}
 
+	addMembershipsQuery := `
+		INSERT INTO team_memberships (user_id, team_name)
+		SELECT UNNEST($1::text[]), $2
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, addMembershipsQuery, memberIDs, team.Name); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
 func (tr *TeamRepo) TeamByName(ctx context.Context, teamName domain.TeamName) (domain.Team, error) {
 	teamQuery := `
-		SELECT t.team_name, u.user_id, u.username, u.is_active
+		SELECT t.team_name, COALESCE(t.parent_team_name, ''), t.archived_at, u.user_id, u.username, u.is_active
 		FROM teams t
-		LEFT JOIN users u ON t.team_name = u.team_name
+		LEFT JOIN team_memberships tm ON tm.team_name = t.team_name
+		LEFT JOIN users u ON u.user_id = tm.user_id
 		WHERE t.team_name = $1
 	`
 
@@ -79,25 +107,29 @@ func (tr *TeamRepo) TeamByName(ctx context.Context, teamName domain.TeamName) (d
 
 	for rows.Next() {
 		var (
-			member   domain.TeamMember
-			tn       domain.TeamName
-			uid      domain.UserID
-			username string
-			isActive bool
+			member     domain.TeamMember
+			tn         domain.TeamName
+			parentName domain.TeamName
+			archivedAt *time.Time
+			uid        *domain.UserID
+			username   *string
+			isActive   *bool
 		)
 
-		if err := rows.Scan(&tn, &uid, &username, &isActive); err != nil {
+		if err := rows.Scan(&tn, &parentName, &archivedAt, &uid, &username, &isActive); err != nil {
 			return domain.Team{}, err
 		}
 
 		if team.Name == "" {
 			team.Name = tn
+			team.ParentName = parentName
+			team.ArchivedAt = archivedAt
 		}
 
-		if uid != "" {
-			member.UserID = uid
-			member.Username = username
-			member.IsActive = isActive
+		if uid != nil {
+			member.UserID = *uid
+			member.Username = *username
+			member.IsActive = *isActive
 			members = append(members, member)
 		}
 	}
@@ -114,3 +146,124 @@ func (tr *TeamRepo) TeamByName(ctx context.Context, teamName domain.TeamName) (d
 
 	return team, nil
 }
+
+func (tr *TeamRepo) SubTeamNames(ctx context.Context, parentName domain.TeamName) ([]domain.TeamName, error) {
+	subTeamsQuery := `SELECT team_name FROM teams WHERE parent_team_name = $1 ORDER BY team_name`
+
+	rows, err := tr.db.Query(ctx, subTeamsQuery, parentName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []domain.TeamName{}
+	for rows.Next() {
+		var name domain.TeamName
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+func (tr *TeamRepo) TeamNames(ctx context.Context, includeArchived bool) ([]domain.TeamName, error) {
+	teamNamesQuery := `SELECT team_name FROM teams WHERE $1 OR archived_at IS NULL ORDER BY team_name`
+
+	rows, err := tr.db.Query(ctx, teamNamesQuery, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []domain.TeamName{}
+	for rows.Next() {
+		var name domain.TeamName
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+func (tr *TeamRepo) SetParent(ctx context.Context, teamName domain.TeamName, parentName domain.TeamName) error {
+	setParentQuery := `UPDATE teams SET parent_team_name = NULLIF($2, '') WHERE team_name = $1`
+
+	tag, err := tr.db.Exec(ctx, setParentQuery, teamName, parentName)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrNotFound
+		}
+
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (tr *TeamRepo) Rename(ctx context.Context, teamName domain.TeamName, newTeamName domain.TeamName) error {
+	renameQuery := `UPDATE teams SET team_name = $2 WHERE team_name = $1`
+
+	tag, err := tr.db.Exec(ctx, renameQuery, teamName, newTeamName)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrTeamExists
+		}
+
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (tr *TeamRepo) Archive(ctx context.Context, teamName domain.TeamName) (domain.Team, error) {
+	archiveQuery := `
+		UPDATE teams
+		SET archived_at = COALESCE(archived_at, NOW())
+		WHERE team_name = $1
+	`
+
+	tag, err := tr.db.Exec(ctx, archiveQuery, teamName)
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.Team{}, domain.ErrNotFound
+	}
+
+	return tr.TeamByName(ctx, teamName)
+}
+
+func (tr *TeamRepo) Delete(ctx context.Context, teamName domain.TeamName) error {
+	deleteQuery := `DELETE FROM teams WHERE team_name = $1`
+
+	tag, err := tr.db.Exec(ctx, deleteQuery, teamName)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrTeamNotEmpty
+		}
+
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const tokenColumns = `token_id, name, scopes, token_hash, created_at, last_used_at, revoked_at`
+
+type APITokenRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAPITokenRepo(db *pgxpool.Pool) *APITokenRepo {
+	return &APITokenRepo{
+		db: db,
+	}
+}
+
+func (tr *APITokenRepo) CreateToken(ctx context.Context, token domain.APIToken) (domain.APIToken, error) {
+	createQuery := `
+		INSERT INTO api_tokens (name, token_hash, scopes)
+		VALUES ($1, $2, $3)
+		RETURNING token_id, created_at
+	`
+
+	err := tr.db.QueryRow(ctx, createQuery, token.Name, token.Hash, tokenScopesToStrings(token.Scopes)).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return domain.APIToken{}, err
+	}
+
+	return token, nil
+}
+
+func (tr *APITokenRepo) Tokens(ctx context.Context) ([]domain.APIToken, error) {
+	tokensQuery := `SELECT ` + tokenColumns + ` FROM api_tokens ORDER BY token_id`
+
+	rows, err := tr.db.Query(ctx, tokensQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []domain.APIToken{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (tr *APITokenRepo) UseToken(ctx context.Context, hash string, now time.Time) (domain.APIToken, error) {
+	useQuery := `
+		UPDATE api_tokens
+		SET last_used_at = $2
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING ` + tokenColumns
+
+	token, err := scanToken(tr.db.QueryRow(ctx, useQuery, hash, now))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.APIToken{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.APIToken{}, err
+	}
+
+	return token, nil
+}
+
+func (tr *APITokenRepo) RevokeToken(ctx context.Context, tokenID int64, now time.Time) (domain.APIToken, error) {
+	revokeQuery := `
+		UPDATE api_tokens
+		SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE token_id = $1
+		RETURNING ` + tokenColumns
+
+	token, err := scanToken(tr.db.QueryRow(ctx, revokeQuery, tokenID, now))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.APIToken{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.APIToken{}, err
+	}
+
+	return token, nil
+}
+
+func scanToken(row pgx.Row) (domain.APIToken, error) {
+	var (
+		token  domain.APIToken
+		scopes []string
+	)
+
+	err := row.Scan(&token.ID, &token.Name, &scopes, &token.Hash, &token.CreatedAt, &token.LastUsedAt, &token.RevokedAt)
+	if err != nil {
+		return domain.APIToken{}, err
+	}
+
+	token.Scopes = make([]domain.TokenScope, len(scopes))
+	for i, scope := range scopes {
+		token.Scopes[i] = domain.TokenScope(scope)
+	}
+
+	return token, nil
+}
+
+func tokenScopesToStrings(scopes []domain.TokenScope) []string {
+	out := make([]string, len(scopes))
+	for i, scope := range scopes {
+		out[i] = string(scope)
+	}
+	return out
+}
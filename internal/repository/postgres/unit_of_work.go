@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UnitOfWork struct {
+	db *pgxpool.Pool
+}
+
+func NewUnitOfWork(db *pgxpool.Pool) *UnitOfWork {
+	return &UnitOfWork{
+		db: db,
+	}
+}
+
+func (uow *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, tx repository.Tx) error) error {
+	tx, err := uow.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(ctx, &unitTx{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (uow *UnitOfWork) WithinSnapshot(ctx context.Context, fn func(ctx context.Context, tx repository.Tx) error) error {
+	tx, err := uow.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(ctx, &unitTx{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type unitTx struct {
+	tx pgx.Tx
+}
+
+func (ut *unitTx) Teams() repository.TeamRepository {
+	return NewTeamRepo(ut.tx)
+}
+
+func (ut *unitTx) Users() repository.UserRepository {
+	return NewUserRepo(ut.tx)
+}
+
+func (ut *unitTx) PullRequests() repository.PullRequestRepository {
+	return NewPullRequestRepo(ut.tx)
+}
+
+func (ut *unitTx) Org() repository.OrgRepository {
+	return NewOrgRepo(ut.tx)
+}
+
+// LockPullRequest locks the row before reading the pull request, the read
+// groups its reviewers and cannot lock rows itself.
+func (ut *unitTx) LockPullRequest(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error) {
+	lockQuery := `
+		SELECT pull_request_id
+		FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`
+
+	var lockedID domain.PullRequestID
+	if err := ut.tx.QueryRow(ctx, lockQuery, pullRequestID).Scan(&lockedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PullRequest{}, domain.ErrNotFound
+		}
+		return domain.PullRequest{}, err
+	}
+
+	return NewPullRequestRepo(ut.tx).pullRequestByID(ctx, ut.tx, pullRequestID)
+}
+
+// LockUsers takes share locks: concurrent transactions may lock the same
+// users, while changes such as deactivation wait for the transaction to end.
+func (ut *unitTx) LockUsers(ctx context.Context, userIDs []domain.UserID) ([]domain.User, error) {
+	return ut.lockUsers(ctx, userIDs, "FOR SHARE OF u")
+}
+
+// LockUsersForUpdate takes exclusive locks, share locks upgraded by two
+// transactions at once would deadlock.
+func (ut *unitTx) LockUsersForUpdate(ctx context.Context, userIDs []domain.UserID) ([]domain.User, error) {
+	return ut.lockUsers(ctx, userIDs, "FOR UPDATE OF u")
+}
+
+// lockUsers locks rows in ID order so that transactions never deadlock on
+// them.
+func (ut *unitTx) lockUsers(ctx context.Context, userIDs []domain.UserID, lockClause string) ([]domain.User, error) {
+	lockUsersQuery := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE u.user_id = ANY($1)
+		ORDER BY u.user_id
+		` + lockClause
+
+	rows, err := ut.tx.Query(ctx, lockUsersQuery, userIDs)
+	if err != nil {
+		return []domain.User{}, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return []domain.User{}, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []domain.User{}, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,250 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"pr-reviewer-service/internal/repository/postgres"
+	"pr-reviewer-service/internal/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres" // driver
+	_ "github.com/golang-migrate/migrate/v4/source/file"       // driver
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockWait is how long a blocked transaction is given to prove it waits.
+const lockWait = 200 * time.Millisecond
+
+// setupDB migrates the database of TEST_DATABASE_DSN from scratch, all of
+// its data is lost.
+func setupDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	m, err := migrate.New("file://../../../migrations", dsn)
+	require.NoError(t, err)
+	if err := m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.Up())
+	sourceErr, dbErr := m.Close()
+	require.NoError(t, sourceErr)
+	require.NoError(t, dbErr)
+
+	pool, err := postgres.NewPsqlConnection(postgres.Config{DSN: dsn})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func createTeam(t *testing.T, pool *pgxpool.Pool, memberIDs ...domain.UserID) {
+	t.Helper()
+
+	team := domain.Team{Name: "backend"}
+	for _, memberID := range memberIDs {
+		team.Members = append(team.Members, domain.TeamMember{UserID: memberID, Username: string(memberID), IsActive: true})
+	}
+
+	require.NoError(t, postgres.NewTeamRepo(pool).Create(context.Background(), team))
+}
+
+// holdLock runs lock in a unit of work and keeps it open until release is
+// closed. It returns once lock went through.
+func holdLock(t *testing.T, uow *postgres.UnitOfWork, lock func(ctx context.Context, tx repository.Tx) error) (release func(), done <-chan error) {
+	t.Helper()
+
+	locked := make(chan struct{})
+	released := make(chan struct{})
+	result := make(chan error, 1)
+
+	go func() {
+		result <- uow.WithinTx(context.Background(), func(ctx context.Context, tx repository.Tx) error {
+			if err := lock(ctx, tx); err != nil {
+				return err
+			}
+			close(locked)
+			<-released
+
+			return nil
+		})
+	}()
+
+	select {
+	case <-locked:
+	case err := <-result:
+		require.NoError(t, err)
+		t.Fatal("unit of work ended before taking the lock")
+	}
+
+	return func() { close(released) }, result
+}
+
+// requireBlocked checks that the work started in the background does not
+// finish before release is called, and finishes right after.
+func requireBlocked(t *testing.T, release func(), done <-chan error, work func() error) {
+	t.Helper()
+
+	finished := make(chan error, 1)
+	go func() { finished <- work() }()
+
+	select {
+	case err := <-finished:
+		t.Fatalf("work went through while the lock was held: %v", err)
+	case <-time.After(lockWait):
+	}
+
+	release()
+	require.NoError(t, <-done)
+	require.NoError(t, <-finished)
+}
+
+func TestLockPullRequestBlocksOtherLocks(t *testing.T) {
+	pool := setupDB(t)
+	ctx := context.Background()
+	createTeam(t, pool, "author", "reviewer")
+	_, err := postgres.NewPullRequestRepo(pool).Create(ctx, domain.PullRequest{
+		ID: "pr-1", Name: "Test PR", AuthorID: "author", TeamName: "backend", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{"reviewer"},
+	})
+	require.NoError(t, err)
+
+	uow := postgres.NewUnitOfWork(pool)
+	release, done := holdLock(t, uow, func(ctx context.Context, tx repository.Tx) error {
+		pr, err := tx.LockPullRequest(ctx, "pr-1")
+		assert.Equal(t, []domain.UserID{"reviewer"}, pr.AssignedReviewers)
+		return err
+	})
+
+	requireBlocked(t, release, done, func() error {
+		return uow.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+			_, err := tx.LockPullRequest(ctx, "pr-1")
+			return err
+		})
+	})
+}
+
+func TestLockUsersBlocksChangesButNotOtherLocks(t *testing.T) {
+	pool := setupDB(t)
+	ctx := context.Background()
+	createTeam(t, pool, "first", "second")
+
+	uow := postgres.NewUnitOfWork(pool)
+	release, done := holdLock(t, uow, func(ctx context.Context, tx repository.Tx) error {
+		users, err := tx.LockUsers(ctx, []domain.UserID{"second", "unknown", "first"})
+		if assert.Len(t, users, 2) {
+			assert.Equal(t, domain.UserID("first"), users[0].ID)
+		}
+		return err
+	})
+
+	// share locks do not wait for each other
+	err := uow.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+		_, err := tx.LockUsers(ctx, []domain.UserID{"first"})
+		return err
+	})
+	require.NoError(t, err)
+
+	requireBlocked(t, release, done, func() error {
+		_, err := postgres.NewUserRepo(pool).SetIsActiveByID(ctx, "first", false)
+		return err
+	})
+}
+
+func TestLockUsersForUpdateBlocksOtherLocks(t *testing.T) {
+	pool := setupDB(t)
+	ctx := context.Background()
+	createTeam(t, pool, "first")
+
+	uow := postgres.NewUnitOfWork(pool)
+	release, done := holdLock(t, uow, func(ctx context.Context, tx repository.Tx) error {
+		_, err := tx.LockUsersForUpdate(ctx, []domain.UserID{"first"})
+		return err
+	})
+
+	requireBlocked(t, release, done, func() error {
+		return uow.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+			_, err := tx.LockUsers(ctx, []domain.UserID{"first"})
+			return err
+		})
+	})
+}
+
+// TestConcurrentReassignsAndDeactivation replaces both reviewers of every
+// pull request at once while the only spare member is deactivated. No pull
+// request may end up with the same reviewer twice or with the deactivated
+// member.
+func TestConcurrentReassignsAndDeactivation(t *testing.T) {
+	pool := setupDB(t)
+	ctx := context.Background()
+	createTeam(t, pool, "author", "rev1", "rev2", "spare")
+
+	userRepo := postgres.NewUserRepo(pool)
+	teamRepo := postgres.NewTeamRepo(pool)
+	prRepo := postgres.NewPullRequestRepo(pool)
+	uow := postgres.NewUnitOfWork(pool)
+	prService := service.NewPullRequestService(prRepo, userRepo, teamRepo, uow, nil)
+	userService := service.NewUserService(userRepo, prRepo, uow, nil)
+
+	const prCount = 20
+	for i := range prCount {
+		_, err := prRepo.Create(ctx, domain.PullRequest{
+			ID: domain.PullRequestID(fmt.Sprintf("pr-%d", i)), Name: "Test PR", AuthorID: "author", TeamName: "backend",
+			Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{"rev1", "rev2"},
+		})
+		require.NoError(t, err)
+	}
+
+	start := make(chan struct{})
+	errs := make(chan error, 2*prCount+1)
+	var wg sync.WaitGroup
+	for i := range prCount {
+		for _, reviewerID := range []domain.UserID{"rev1", "rev2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, _, err := prService.ReassignReviewer(ctx, domain.PullRequestID(fmt.Sprintf("pr-%d", i)), reviewerID)
+				if errors.Is(err, domain.ErrNoCandidate) {
+					err = nil
+				}
+				errs <- err
+			}()
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		_, _, err := userService.DeactivateUser(ctx, "spare")
+		errs <- err
+	}()
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	for i := range prCount {
+		pr, err := prRepo.PullRequestByID(ctx, domain.PullRequestID(fmt.Sprintf("pr-%d", i)))
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+		assert.NotEqual(t, pr.AssignedReviewers[0], pr.AssignedReviewers[1])
+		assert.NotContains(t, pr.AssignedReviewers, domain.UserID("spare"))
+	}
+}
@@ -6,44 +6,125 @@ import (
 	"pr-reviewer-service/internal/domain"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// userColumns selects a user together with all of its team memberships,
+// the query must alias the users table as u.
+const userColumns = `
+	u.user_id,
+	u.username,
+	u.full_name,
+	u.email,
+	u.role,
+	COALESCE(u.team_name, ''),
+	u.is_active,
+	u.deleted_at,
+	ARRAY(SELECT m.team_name FROM team_memberships m WHERE m.user_id = u.user_id ORDER BY m.team_name)
+`
+
 type UserRepo struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewUserRepo(db *pgxpool.Pool) *UserRepo {
+func NewUserRepo(db DBTX) *UserRepo {
 	return &UserRepo{
 		db: db,
 	}
 }
 
 func (ur *UserRepo) Create(ctx context.Context, user domain.User) error {
+	tx, err := ur.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	createUserQuery := `
-		INSERT INTO users (user_id, username, team_name, is_active)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (user_id, username, team_name, is_active, full_name, role, deleted_at, email)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, COALESCE(NULLIF($6, '')::user_role, 'MEMBER'), $7, $8)
 		ON CONFLICT (user_id) DO UPDATE
 		SET
 			username = EXCLUDED.username,
 			team_name = EXCLUDED.team_name,
-			is_active = EXCLUDED.is_active
+			is_active = EXCLUDED.is_active,
+			full_name = COALESCE(NULLIF($5, ''), users.full_name),
+			role = COALESCE(NULLIF($6, '')::user_role, users.role),
+			deleted_at = EXCLUDED.deleted_at,
+			email = COALESCE(NULLIF($8, ''), users.email)
 	`
 
-	_, err := ur.db.Exec(ctx, createUserQuery, user.ID, user.Username, user.TeamName, user.IsActive)
-	return err
+	if _, err := tx.Exec(ctx, createUserQuery, user.ID, user.Username, user.TeamName, user.IsActive, user.FullName, user.Role, user.DeletedAt, user.Email); err != nil {
+		return err
+	}
+
+	if user.TeamName != "" {
+		if err := addMembership(ctx, tx, user.ID, user.TeamName); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (ur *UserRepo) UserByID(ctx context.Context, userID domain.UserID) (domain.User, error) {
-	userByIDQuery := `
-		SELECT user_id, username, team_name, is_active
-		FROM users
-		WHERE user_id = $1
+	return userByID(ctx, ur.db, userID)
+}
+
+func (ur *UserRepo) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	listQuery := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE ($7 OR u.deleted_at IS NULL)
+			AND ($1 = '' OR EXISTS (SELECT 1 FROM team_memberships m WHERE m.user_id = u.user_id AND m.team_name = $1))
+			AND ($2::boolean IS NULL OR u.is_active = $2)
+			AND ($3 = '' OR u.role::text = $3)
+			AND u.user_id > $4
+			AND ($6 = '' OR u.username = $6)
+		ORDER BY u.user_id
+		LIMIT $5
 	`
 
-	var user domain.User
-	err := ur.db.QueryRow(ctx, userByIDQuery, userID).
-		Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+	rows, err := ur.db.Query(ctx, listQuery, filter.TeamName, filter.IsActive, filter.Role, filter.After, limit, filter.Username, filter.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (ur *UserRepo) UpdateProfile(ctx context.Context, user domain.User) (domain.User, error) {
+	updateProfileQuery := `
+		WITH u AS (
+			UPDATE users
+			SET username = $2, full_name = $3, role = $4, email = $5
+			WHERE user_id = $1 AND deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT ` + userColumns + ` FROM u
+	`
+
+	updated, err := scanUser(ur.db.QueryRow(ctx, updateProfileQuery, user.ID, user.Username, user.FullName, user.Role, user.Email))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, domain.ErrNotFound
@@ -51,20 +132,52 @@ func (ur *UserRepo) UserByID(ctx context.Context, userID domain.UserID) (domain.
 		return domain.User{}, err
 	}
 
-	return user, nil
+	return updated, nil
+}
+
+// Delete hides the user from the directory and drops all memberships. The
+// row is kept so that pull requests still point at their author and reviewers.
+func (ur *UserRepo) Delete(ctx context.Context, userID domain.UserID) error {
+	tx, err := ur.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleteUserQuery := `
+		UPDATE users
+		SET deleted_at = NOW(), is_active = FALSE, team_name = NULL
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := tx.Exec(ctx, deleteUserQuery, userID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM team_memberships WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (ur *UserRepo) SetIsActiveByID(ctx context.Context, userID domain.UserID, isActive bool) (domain.User, error) {
 	setIsActiveQuery := `
-		UPDATE users
-		SET is_active = $2
-		WHERE user_id = $1
-		RETURNING user_id, username, team_name, is_active
+		WITH u AS (
+			UPDATE users
+			SET is_active = $2
+			WHERE user_id = $1 AND deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT ` + userColumns + ` FROM u
 	`
 
-	var user domain.User
-	err := ur.db.QueryRow(ctx, setIsActiveQuery, userID, isActive).
-		Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+	user, err := scanUser(ur.db.QueryRow(ctx, setIsActiveQuery, userID, isActive))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, domain.ErrNotFound
@@ -75,11 +188,126 @@ func (ur *UserRepo) SetIsActiveByID(ctx context.Context, userID domain.UserID, i
 	return user, nil
 }
 
+// SetTeamByID moves the user's home team, the membership in the previous
+// home team is dropped. An empty team name leaves the user without a home team.
+func (ur *UserRepo) SetTeamByID(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error) {
+	tx, err := ur.db.Begin(ctx)
+	if err != nil {
+		return domain.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var previousTeam domain.TeamName
+	err = tx.QueryRow(ctx, `SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, userID).
+		Scan(&previousTeam)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+
+	dropMembershipQuery := `DELETE FROM team_memberships WHERE user_id = $1 AND team_name = $2`
+	if _, err := tx.Exec(ctx, dropMembershipQuery, userID, previousTeam); err != nil {
+		return domain.User{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET team_name = NULLIF($2, '') WHERE user_id = $1`, userID, teamName); err != nil {
+		return domain.User{}, mapForeignKeyError(err)
+	}
+
+	if teamName != "" {
+		if err := addMembership(ctx, tx, userID, teamName); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	user, err := userByID(ctx, tx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (ur *UserRepo) AddMembership(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error) {
+	tx, err := ur.db.Begin(ctx)
+	if err != nil {
+		return domain.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addMembership(ctx, tx, userID, teamName); err != nil {
+		return domain.User{}, err
+	}
+
+	setHomeTeamQuery := `UPDATE users SET team_name = $2 WHERE user_id = $1 AND team_name IS NULL`
+	if _, err := tx.Exec(ctx, setHomeTeamQuery, userID, teamName); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := userByID(ctx, tx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// RemoveMembership drops the membership. When it was the user's home team,
+// another membership becomes the home team.
+func (ur *UserRepo) RemoveMembership(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error) {
+	tx, err := ur.db.Begin(ctx)
+	if err != nil {
+		return domain.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM team_memberships WHERE user_id = $1 AND team_name = $2`, userID, teamName)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	setHomeTeamQuery := `
+		UPDATE users
+		SET team_name = (SELECT MIN(m.team_name) FROM team_memberships m WHERE m.user_id = $1)
+		WHERE user_id = $1 AND team_name = $2
+	`
+	if _, err := tx.Exec(ctx, setHomeTeamQuery, userID, teamName); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := userByID(ctx, tx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
 func (ur *UserRepo) ActiveUsersByTeamName(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
 	activeUsersQuery := `
-		SELECT user_id, username, team_name, is_active
-		FROM users
-		WHERE team_name = $1 AND is_active = TRUE
+		SELECT ` + userColumns + `
+		FROM users u
+		JOIN team_memberships tm ON tm.user_id = u.user_id
+		JOIN teams t ON t.team_name = tm.team_name
+		WHERE tm.team_name = $1 AND u.is_active = TRUE AND t.archived_at IS NULL
 	`
 
 	rows, err := ur.db.Query(ctx, activeUsersQuery, teamName)
@@ -90,8 +318,8 @@ func (ur *UserRepo) ActiveUsersByTeamName(ctx context.Context, teamName domain.T
 
 	var users []domain.User
 	for rows.Next() {
-		var user domain.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return []domain.User{}, err
 		}
 
@@ -104,3 +332,58 @@ func (ur *UserRepo) ActiveUsersByTeamName(ctx context.Context, teamName domain.T
 
 	return users, nil
 }
+
+func userByID(ctx context.Context, rq RowQuerier, userID domain.UserID) (domain.User, error) {
+	userByIDQuery := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE u.user_id = $1 AND u.deleted_at IS NULL
+	`
+
+	user, err := scanUser(rq.QueryRow(ctx, userByIDQuery, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func scanUser(row pgx.Row) (domain.User, error) {
+	var user domain.User
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.FullName,
+		&user.Email,
+		&user.Role,
+		&user.TeamName,
+		&user.IsActive,
+		&user.DeletedAt,
+		&user.Teams,
+	)
+	return user, err
+}
+
+func addMembership(ctx context.Context, tx pgx.Tx, userID domain.UserID, teamName domain.TeamName) error {
+	addMembershipQuery := `
+		INSERT INTO team_memberships (user_id, team_name)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := tx.Exec(ctx, addMembershipQuery, userID, teamName)
+	return mapForeignKeyError(err)
+}
+
+// mapForeignKeyError reports a reference to a missing user or team as not found.
+func mapForeignKeyError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return domain.ErrNotFound
+	}
+
+	return err
+}
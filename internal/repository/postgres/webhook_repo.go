@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// deliveryColumns selects a delivery with its subscription and event, the
+// query must alias webhook_deliveries as d and join webhook_subscriptions as
+// ws and pr_events as e.
+const deliveryColumns = `
+	d.delivery_id,
+	d.status,
+	d.attempts,
+	d.next_attempt_at,
+	COALESCE(d.last_error, ''),
+	d.delivered_at,
+	ws.subscription_id,
+	ws.url,
+	ws.event_types::text[],
+	ws.secret,
+	ws.created_at,
+	e.event_id,
+	e.pull_request_id,
+	e.kind,
+	COALESCE(e.user_id, ''),
+	COALESCE(e.previous_user_id, ''),
+	COALESCE(e.review_state::text, ''),
+	COALESCE(e.reason, ''),
+	COALESCE(e.actor, ''),
+	e.created_at
+`
+
+const deliveryJoins = `
+	JOIN webhook_subscriptions ws ON ws.subscription_id = d.subscription_id
+	JOIN pr_events e ON e.event_id = d.event_id
+`
+
+type WebhookRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepo(db *pgxpool.Pool) *WebhookRepo {
+	return &WebhookRepo{
+		db: db,
+	}
+}
+
+func (wr *WebhookRepo) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	createQuery := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret)
+		VALUES ($1, $2::text[]::pr_event_kind[], $3)
+		RETURNING subscription_id, created_at
+	`
+
+	err := wr.db.QueryRow(ctx, createQuery, subscription.URL, eventKindsToStrings(subscription.EventTypes), subscription.Secret).
+		Scan(&subscription.ID, &subscription.CreatedAt)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (wr *WebhookRepo) Subscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	subscriptionsQuery := `
+		SELECT subscription_id, url, event_types::text[], secret, created_at
+		FROM webhook_subscriptions
+		ORDER BY subscription_id
+	`
+
+	rows, err := wr.db.Query(ctx, subscriptionsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for rows.Next() {
+		var (
+			subscription domain.WebhookSubscription
+			eventTypes   []string
+		)
+
+		if err := rows.Scan(&subscription.ID, &subscription.URL, &eventTypes, &subscription.Secret, &subscription.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		subscription.EventTypes = stringsToEventKinds(eventTypes)
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (wr *WebhookRepo) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	tag, err := wr.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE subscription_id = $1`, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepo) ClaimDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	claimQuery := `
+		WITH d AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = $1::timestamptz + make_interval(secs => $3)
+			WHERE delivery_id IN (
+				SELECT delivery_id
+				FROM webhook_deliveries
+				WHERE status = 'PENDING' AND next_attempt_at <= $1
+				ORDER BY next_attempt_at, delivery_id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + deliveryColumns + `
+		FROM d
+		` + deliveryJoins + `
+		ORDER BY d.next_attempt_at, d.delivery_id
+	`
+
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+
+	return wr.queryDeliveries(ctx, claimQuery, now, limitArg, lease.Seconds())
+}
+
+func (wr *WebhookRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	updateQuery := `
+		UPDATE webhook_deliveries
+		SET
+			status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_error = NULLIF($5, ''),
+			delivered_at = $6
+		WHERE delivery_id = $1
+	`
+
+	tag, err := wr.db.Exec(ctx, updateQuery,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepo) DeadDeliveries(ctx context.Context, subscriptionID int64) ([]domain.WebhookDelivery, error) {
+	deadQuery := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		` + deliveryJoins + `
+		WHERE d.status = 'DEAD' AND ($1 = 0 OR d.subscription_id = $1)
+		ORDER BY d.delivery_id
+	`
+
+	return wr.queryDeliveries(ctx, deadQuery, subscriptionID)
+}
+
+func (wr *WebhookRepo) Redeliver(ctx context.Context, deliveryID int64) (domain.WebhookDelivery, error) {
+	redeliverQuery := `
+		WITH d AS (
+			UPDATE webhook_deliveries
+			SET status = 'PENDING', attempts = 0, next_attempt_at = NOW()
+			WHERE delivery_id = $1 AND status = 'DEAD'
+			RETURNING *
+		)
+		SELECT ` + deliveryColumns + `
+		FROM d
+		` + deliveryJoins
+
+	delivery, err := scanDelivery(wr.db.QueryRow(ctx, redeliverQuery, deliveryID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.WebhookDelivery{}, fmt.Errorf("%w: dead delivery %d", domain.ErrNotFound, deliveryID)
+		}
+		return domain.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func (wr *WebhookRepo) queryDeliveries(ctx context.Context, query string, args ...any) ([]domain.WebhookDelivery, error) {
+	rows, err := wr.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func scanDelivery(row pgx.Row) (domain.WebhookDelivery, error) {
+	var (
+		delivery   domain.WebhookDelivery
+		eventTypes []string
+	)
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.Subscription.ID,
+		&delivery.Subscription.URL,
+		&eventTypes,
+		&delivery.Subscription.Secret,
+		&delivery.Subscription.CreatedAt,
+		&delivery.Event.ID,
+		&delivery.Event.PullRequestID,
+		&delivery.Event.Kind,
+		&delivery.Event.UserID,
+		&delivery.Event.PreviousUserID,
+		&delivery.Event.ReviewState,
+		&delivery.Event.Reason,
+		&delivery.Event.Actor,
+		&delivery.Event.CreatedAt,
+	)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery.Subscription.EventTypes = stringsToEventKinds(eventTypes)
+
+	return delivery, nil
+}
+
+func eventKindsToStrings(kinds []domain.PREventKind) []string {
+	out := make([]string, len(kinds))
+	for i, kind := range kinds {
+		out[i] = string(kind)
+	}
+
+	return out
+}
+
+func stringsToEventKinds(values []string) []domain.PREventKind {
+	out := make([]domain.PREventKind, len(values))
+	for i, value := range values {
+		out[i] = domain.PREventKind(value)
+	}
+
+	return out
+}
@@ -3,24 +3,144 @@ package repository
 import (
 	"context"
 	"pr-reviewer-service/internal/domain"
+	"time"
 )
 
 type TeamRepository interface {
 	Create(ctx context.Context, team domain.Team) error
 	TeamByName(ctx context.Context, teamName domain.TeamName) (domain.Team, error)
+	SubTeamNames(ctx context.Context, parentName domain.TeamName) ([]domain.TeamName, error)
+	TeamNames(ctx context.Context, includeArchived bool) ([]domain.TeamName, error)
+	SetParent(ctx context.Context, teamName domain.TeamName, parentName domain.TeamName) error
+	Rename(ctx context.Context, teamName domain.TeamName, newTeamName domain.TeamName) error
+	Archive(ctx context.Context, teamName domain.TeamName) (domain.Team, error)
+	Delete(ctx context.Context, teamName domain.TeamName) error
 }
 
 type UserRepository interface {
 	Create(ctx context.Context, user domain.User) error
 	UserByID(ctx context.Context, userID domain.UserID) (domain.User, error)
+	List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+	UpdateProfile(ctx context.Context, user domain.User) (domain.User, error)
+	Delete(ctx context.Context, userID domain.UserID) error
 	SetIsActiveByID(ctx context.Context, userID domain.UserID, isActive bool) (domain.User, error)
+	SetTeamByID(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error)
+	AddMembership(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error)
+	RemoveMembership(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (domain.User, error)
 	ActiveUsersByTeamName(ctx context.Context, teamName domain.TeamName) ([]domain.User, error)
 }
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pullRequest domain.PullRequest) (domain.PullRequest, error)
+	// Restore stores a pull request brought back from an export. Its events
+	// are recorded like on Create, but no webhook deliveries are queued.
+	Restore(ctx context.Context, pullRequest domain.PullRequest) (domain.PullRequest, error)
 	PullRequestByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error)
 	MergeByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error)
-	ReassignReviewer(ctx context.Context, pullRequestID domain.PullRequestID, oldUserID domain.UserID, newUserID domain.UserID) (domain.PullRequest, domain.UserID, error)
-	PullRequestsByReviewer(ctx context.Context, userID domain.UserID) ([]domain.PullRequestShort, error)
+	CloseByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error)
+	// ReopenByID opens a closed pull request again with the same reviewers.
+	ReopenByID(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error)
+	SetAuthor(ctx context.Context, pullRequestID domain.PullRequestID, authorID domain.UserID) (domain.PullRequest, error)
+	ReassignReviewer(ctx context.Context, pullRequestID domain.PullRequestID, oldUserID domain.UserID, newUserID domain.UserID, reason domain.ReassignReason) (domain.PullRequest, domain.UserID, error)
+	RemoveReviewer(ctx context.Context, pullRequestID domain.PullRequestID, userID domain.UserID, reason domain.ReassignReason) (domain.PullRequest, error)
+	SetReviewState(ctx context.Context, pullRequestID domain.PullRequestID, reviewerID domain.UserID, state domain.ReviewState) (domain.PullRequest, error)
+	PullRequestsByReviewer(ctx context.Context, userID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequestShort, error)
+	PullRequestsByAuthor(ctx context.Context, authorID domain.UserID, filter domain.PullRequestFilter) ([]domain.PullRequest, error)
+	List(ctx context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error)
+	// Timeline returns the events of the pull request, oldest first.
+	Timeline(ctx context.Context, pullRequestID domain.PullRequestID) ([]domain.PREvent, error)
+}
+
+// OrgRepository reads and rewrites the whole organisation at once.
+type OrgRepository interface {
+	Snapshot(ctx context.Context) (domain.OrgSnapshot, error)
+	// Apply runs all changes in order, either all of them take effect or none.
+	Apply(ctx context.Context, changes []domain.OrgChange) error
+}
+
+// WebhookRepository stores subscriptions and the outbox of deliveries. The
+// outbox is filled by the pull request repository together with the events.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	Subscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID int64) error
+	// ClaimDeliveries returns up to limit pending deliveries due at now and
+	// postpones them by lease, so that other dispatchers skip them meanwhile.
+	ClaimDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	DeadDeliveries(ctx context.Context, subscriptionID int64) ([]domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) (domain.WebhookDelivery, error)
+}
+
+// ChatChannelRepository stores the chat channel of each team, a channel goes
+// away together with its team.
+type ChatChannelRepository interface {
+	SetChatChannel(ctx context.Context, channel domain.ChatChannel) (domain.ChatChannel, error)
+	ChatChannel(ctx context.Context, teamName domain.TeamName) (domain.ChatChannel, error)
+	DeleteChatChannel(ctx context.Context, teamName domain.TeamName) error
+}
+
+// IdentityRepository links users to their accounts at external providers,
+// an external ID belongs to at most one user.
+type IdentityRepository interface {
+	// LinkIdentity links the external ID to the user, taking it over from
+	// another user if it was linked before.
+	LinkIdentity(ctx context.Context, identity domain.UserIdentity) (domain.UserIdentity, error)
+	UnlinkIdentity(ctx context.Context, provider domain.IdentityProvider, externalID string) error
+	UserIDByIdentity(ctx context.Context, provider domain.IdentityProvider, externalID string) (domain.UserID, error)
+	// IdentitiesByUserID returns the identities of the user ordered by
+	// provider and external ID.
+	IdentitiesByUserID(ctx context.Context, userID domain.UserID) ([]domain.UserIdentity, error)
+}
+
+// APITokenRepository stores API tokens by the hash of their secret.
+type APITokenRepository interface {
+	CreateToken(ctx context.Context, token domain.APIToken) (domain.APIToken, error)
+	Tokens(ctx context.Context) ([]domain.APIToken, error)
+	// UseToken returns the token with the hash unless it is revoked and
+	// records that it was used at now.
+	UseToken(ctx context.Context, hash string, now time.Time) (domain.APIToken, error)
+	// RevokeToken revokes the token at now, a revoked token keeps the time
+	// it was first revoked at.
+	RevokeToken(ctx context.Context, tokenID int64, now time.Time) (domain.APIToken, error)
+}
+
+// IdempotencyRepository stores requests made with an idempotency key
+// together with their responses.
+type IdempotencyRepository interface {
+	// ClaimIdempotencyKey stores the request unless its key is taken by a
+	// request that has not expired at now. False means the key is taken and
+	// the request holding it is returned instead.
+	ClaimIdempotencyKey(ctx context.Context, request domain.IdempotentRequest, now time.Time) (domain.IdempotentRequest, bool, error)
+	CompleteIdempotentRequest(ctx context.Context, key string, statusCode int, body []byte, expiresAt time.Time) error
+	DeleteIdempotentRequest(ctx context.Context, key string) error
+	DeleteExpiredIdempotentRequests(ctx context.Context, now time.Time) (int64, error)
+}
+
+// UnitOfWork runs work on users and pull requests in one transaction.
+type UnitOfWork interface {
+	// WithinTx commits everything fn did through tx when it returns nil and
+	// rolls it back otherwise.
+	WithinTx(ctx context.Context, fn func(ctx context.Context, tx Tx) error) error
+	// WithinSnapshot runs fn in a read-only transaction, everything read
+	// through tx comes from one snapshot of the data.
+	WithinSnapshot(ctx context.Context, fn func(ctx context.Context, tx Tx) error) error
+}
+
+// Tx gives the repositories bound to a transaction. Rows locked through it
+// stay locked until the transaction ends.
+type Tx interface {
+	Teams() TeamRepository
+	Users() UserRepository
+	PullRequests() PullRequestRepository
+	Org() OrgRepository
+	// LockPullRequest returns the pull request and keeps other transactions
+	// from changing or locking it.
+	LockPullRequest(ctx context.Context, pullRequestID domain.PullRequestID) (domain.PullRequest, error)
+	// LockUsers returns the users with the IDs ordered by ID and keeps other
+	// transactions from changing them, unknown IDs are skipped.
+	LockUsers(ctx context.Context, userIDs []domain.UserID) ([]domain.User, error)
+	// LockUsersForUpdate is LockUsers for users the transaction is about to
+	// change, other transactions can not lock them either.
+	LockUsersForUpdate(ctx context.Context, userIDs []domain.UserID) ([]domain.User, error)
 }
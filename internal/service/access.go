@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+)
+
+// The checks below decide what the signed-in user of a request may do.
+// Requests without a principal are made by the service itself, by Git
+// hosts, or with an API token whose scopes the transport checked already,
+// and may do anything but admin work, which needs a token with the admin
+// scope.
+
+// authorizeAdmin lets only admins and admin tokens through.
+func authorizeAdmin(ctx context.Context) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		if token, ok := domain.APITokenFromContext(ctx); ok && !token.Allows(domain.ScopeAdmin) {
+			return fmt.Errorf("%w: the %q scope is required", domain.ErrForbidden, domain.ScopeAdmin)
+		}
+		return nil
+	}
+	if principal.HasRole(domain.RoleAdmin) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: admin role required", domain.ErrForbidden)
+}
+
+// authorizeTeamLead lets admins and the leads of the team through, a lead
+// leads their home team.
+func authorizeTeamLead(ctx context.Context, userRepo repository.UserRepository, teamName domain.TeamName) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.HasRole(domain.RoleAdmin) {
+		return nil
+	}
+
+	if principal.HasRole(domain.RoleLead) && teamName != "" {
+		lead, err := userRepo.UserByID(ctx, principal.UserID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		if err == nil && !lead.IsDeleted() && lead.TeamName == teamName {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: only admins and leads of team %s may do this", domain.ErrForbidden, teamName)
+}
+
+// authorizeSelfOrTeamLead lets the user themselves through, as well as
+// admins and the leads of the given team.
+func authorizeSelfOrTeamLead(ctx context.Context, userRepo repository.UserRepository, userID domain.UserID, teamName domain.TeamName) error {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok && principal.UserID == userID {
+		return nil
+	}
+
+	return authorizeTeamLead(ctx, userRepo, teamName)
+}
+
+// authorizeSelf lets the user themselves and admins through.
+func authorizeSelf(ctx context.Context, userID domain.UserID) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.UserID == userID {
+		return nil
+	}
+
+	return authorizeAdmin(ctx)
+}
+
+// authorizeSelfOrTheirLead lets the user themselves through, as well as
+// admins and the leads of the home team of the user.
+func authorizeSelfOrTheirLead(ctx context.Context, userRepo repository.UserRepository, userID domain.UserID) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.UserID == userID || principal.HasRole(domain.RoleAdmin) {
+		return nil
+	}
+
+	user, err := userRepo.UserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return authorizeTeamLead(ctx, userRepo, user.TeamName)
+}
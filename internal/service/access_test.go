@@ -0,0 +1,207 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func as(ctx context.Context, userID domain.UserID, roles ...domain.UserRole) context.Context {
+	return domain.WithPrincipal(ctx, domain.Principal{UserID: userID, Roles: roles})
+}
+
+func TestLeadManagesOnlyOwnTeam(t *testing.T) {
+	e := setupMembershipTest(t)
+	ctx := as(e.ctx, firstUserID, domain.RoleLead)
+
+	_, err := e.teamService.AddMember(ctx, teamPlatformName, domain.TeamMember{UserID: "new-user-id", Username: "New", IsActive: true})
+	require.NoError(t, err)
+
+	_, err = e.teamService.RemoveMember(ctx, teamPlatformName, "new-user-id")
+	require.NoError(t, err)
+
+	_, err = e.teamService.AddMember(ctx, teamBackendName, domain.TeamMember{UserID: "other-user-id", Username: "Other", IsActive: true})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = e.teamService.RemoveMember(ctx, teamBackendName, thirdUserID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	// moving needs both teams
+	_, err = e.teamService.MoveMember(ctx, thirdUserID, teamPlatformName)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = e.teamService.MoveMember(ctx, secondUserID, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = e.teamService.RenameTeam(ctx, teamPlatformName, "platform-2")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	chatService := service.NewChatService(inmemory.NewChatChannelRepo(e.storage), e.teamRepo, e.userRepo, inmemory.NewIdentityRepo(e.storage))
+	_, err = chatService.SetChannel(ctx, domain.ChatChannel{TeamName: teamPlatformName, WebhookURL: "https://chat.example.com/hook"})
+	require.NoError(t, err)
+	_, err = chatService.SetChannel(ctx, domain.ChatChannel{TeamName: teamBackendName, WebhookURL: "https://chat.example.com/hook"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestMemberTogglesOnlyOwnAvailability(t *testing.T) {
+	e := setupMembershipTest(t)
+	userService := service.NewUserService(e.userRepo, e.prRepo, inmemory.NewUnitOfWork(e.storage), nil)
+
+	member := as(e.ctx, secondUserID, domain.RoleMember)
+	user, err := userService.SetIsActive(member, secondUserID, true)
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+
+	_, err = userService.SetIsActive(member, firstUserID, false)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = e.teamService.AddMember(member, teamPlatformName, domain.TeamMember{UserID: "new-user-id", Username: "New"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	lead := as(e.ctx, firstUserID, domain.RoleLead)
+	_, err = userService.SetIsActive(lead, secondUserID, false)
+	require.NoError(t, err)
+
+	_, _, err = userService.DeactivateUser(lead, thirdUserID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.True(t, e.storage.Users[thirdUserID].IsActive)
+}
+
+func TestAdminCanDoAnything(t *testing.T) {
+	e := setupMembershipTest(t)
+	ctx := as(e.ctx, "admin-user-id", domain.RoleAdmin)
+
+	_, err := e.teamService.MoveMember(ctx, thirdUserID, teamPlatformName)
+	require.NoError(t, err)
+
+	_, err = e.teamService.RenameTeam(ctx, teamBackendName, "backend-2")
+	require.NoError(t, err)
+
+	userService := service.NewUserService(e.userRepo, e.prRepo, inmemory.NewUnitOfWork(e.storage), nil)
+	_, err = userService.SetIsActive(ctx, firstUserID, false)
+	require.NoError(t, err)
+}
+
+func TestOnlyAdminsManageTheOrganization(t *testing.T) {
+	e := setupMembershipTest(t)
+	ctx := as(e.ctx, firstUserID, domain.RoleLead)
+
+	_, err := e.teamService.CreateTeam(ctx, domain.Team{Name: "new-team"}, false)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	userService := service.NewUserService(e.userRepo, e.prRepo, inmemory.NewUnitOfWork(e.storage), nil)
+	role := domain.RoleAdmin
+	_, err = userService.UpdateUser(ctx, firstUserID, service.UserUpdate{Role: &role})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	webhookService := service.NewWebhookService(inmemory.NewWebhookRepo(e.storage))
+	_, err = webhookService.Subscriptions(ctx)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestTokensNeedTheAdminScopeForAdminWork(t *testing.T) {
+	e := setupMembershipTest(t)
+	userService := service.NewUserService(e.userRepo, e.prRepo, inmemory.NewUnitOfWork(e.storage), nil)
+
+	teamsToken := domain.WithAPIToken(e.ctx, domain.APIToken{Name: "ci", Scopes: []domain.TokenScope{domain.ScopeTeamsWrite}})
+	_, err := userService.DeleteUser(teamsToken, thirdUserID, true)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = userService.Offboard(teamsToken, thirdUserID, "")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	role := domain.RoleAdmin
+	_, err = userService.UpdateUser(teamsToken, thirdUserID, service.UserUpdate{Role: &role})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	// lead work is what teams:write is for
+	_, err = userService.SetIsActive(teamsToken, thirdUserID, false)
+	require.NoError(t, err)
+
+	adminToken := domain.WithAPIToken(e.ctx, domain.APIToken{Name: "ops", Scopes: []domain.TokenScope{domain.ScopeAdmin}})
+	_, err = userService.DeleteUser(adminToken, thirdUserID, true)
+	require.NoError(t, err)
+}
+
+func TestPrincipalHasTheStoredRole(t *testing.T) {
+	e := setupMembershipTest(t)
+	userService := service.NewUserService(e.userRepo, e.prRepo, inmemory.NewUnitOfWork(e.storage), nil)
+
+	principal, err := userService.Principal(e.ctx, domain.Principal{UserID: firstUserID, Roles: []domain.UserRole{domain.RoleAdmin}})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UserRole{domain.RoleMember}, principal.Roles)
+
+	role := domain.RoleLead
+	_, err = userService.UpdateUser(e.ctx, firstUserID, service.UserUpdate{Role: &role})
+	require.NoError(t, err)
+
+	principal, err = userService.Principal(e.ctx, domain.Principal{UserID: firstUserID})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UserRole{domain.RoleLead}, principal.Roles)
+
+	// users the directory doesn't know keep the roles of their token
+	principal, err = userService.Principal(e.ctx, domain.Principal{UserID: "admin-user-id", Roles: []domain.UserRole{domain.RoleAdmin}})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UserRole{domain.RoleAdmin}, principal.Roles)
+}
+
+func TestReviewerDeclinesOnlyOwnReview(t *testing.T) {
+	e, pr := setupReassignTest(t)
+
+	_, _, err := e.prService.ReassignReviewer(as(e.ctx, secondReviewerID, domain.RoleMember), pr.ID, firstReviewerID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, _, err = e.prService.ReassignReviewer(as(e.ctx, "other-lead-id", domain.RoleLead), pr.ID, firstReviewerID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = e.prService.SubmitReview(as(e.ctx, secondReviewerID, domain.RoleMember), pr.ID, firstReviewerID, domain.ReviewApproved)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, newReviewerID, err := e.prService.ReassignReviewer(as(e.ctx, firstReviewerID, domain.RoleMember), pr.ID, firstReviewerID)
+	require.NoError(t, err)
+	assert.Equal(t, secondReviewerID, newReviewerID)
+}
+
+func TestLeadReassignsWithinOwnTeam(t *testing.T) {
+	e, pr := setupReassignTest(t)
+
+	_, newReviewerID, err := e.prService.ReassignReviewer(as(e.ctx, authorID, domain.RoleLead), pr.ID, firstReviewerID)
+	require.NoError(t, err)
+	assert.Equal(t, secondReviewerID, newReviewerID)
+}
+
+func TestOnlyLeadsManagePullRequestsOfTheirTeam(t *testing.T) {
+	e, pr := setupReassignTest(t)
+	member := as(e.ctx, firstReviewerID, domain.RoleMember)
+	otherLead := as(e.ctx, "other-lead-id", domain.RoleLead)
+
+	for _, ctx := range []context.Context{member, otherLead} {
+		_, err := e.prService.CreatePR(ctx, "pr-2", "Test PR 2", authorID, "")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+
+		_, err = e.prService.MergePR(ctx, pr.ID)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+
+		_, err = e.prService.ClosePR(ctx, pr.ID)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+
+		_, err = e.prService.ReopenPR(ctx, pr.ID)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	}
+	assert.Equal(t, domain.StatusOpen, e.storage.PRs[pr.ID].Status)
+	assert.NotContains(t, e.storage.PRs, domain.PullRequestID("pr-2"))
+
+	lead := as(e.ctx, authorID, domain.RoleLead)
+	_, err := e.prService.CreatePR(lead, "pr-2", "Test PR 2", authorID, "")
+	require.NoError(t, err)
+
+	_, err = e.prService.ClosePR(lead, pr.ID)
+	require.NoError(t, err)
+	_, err = e.prService.ReopenPR(lead, pr.ID)
+	require.NoError(t, err)
+	merged, err := e.prService.MergePR(lead, pr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusMerged, merged.Status)
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// defaultChatTemplates mention reviewers with their Slack member ID when it
+// is linked and with @username otherwise, which Mattermost resolves.
+var defaultChatTemplates = map[domain.PREventKind]string{
+	domain.EventReviewerAssigned: `{{range .Reviewers}}{{mention .}} {{end}}please review "{{.PullRequest.Name}}" ({{.PullRequest.ID}}) by {{.Author.Username}}`,
+	domain.EventReviewerReassigned: `{{range .Reviewers}}{{mention .}} {{end}}please review "{{.PullRequest.Name}}" ({{.PullRequest.ID}}) ` +
+		`by {{.Author.Username}}, it was handed over from {{.PreviousReviewer.Username}}`,
+	domain.EventMerged: `"{{.PullRequest.Name}}" ({{.PullRequest.ID}}) by {{.Author.Username}} was merged`,
+}
+
+type ChatService struct {
+	channelRepo  repository.ChatChannelRepository
+	teamRepo     repository.TeamRepository
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+}
+
+// ChatMessage is a rendered notification together with the incoming webhook
+// it is posted to.
+type ChatMessage struct {
+	WebhookURL string
+	Text       string
+}
+
+// ChatMessageData is what the chat templates are executed with. Users that
+// cannot be found are represented by their ID. Templates can call
+// {{mention .}} on a user to ping them.
+type ChatMessageData struct {
+	Event            domain.PREventKind
+	PullRequest      domain.PullRequest
+	Author           domain.User
+	Reviewers        []domain.User
+	PreviousReviewer domain.User
+	Reason           domain.ReassignReason
+	Actor            string
+
+	slackIDs map[domain.UserID]string
+}
+
+// mention renders <@MEMBER_ID> for users with a linked Slack member ID and
+// @username for the others.
+func (d ChatMessageData) mention(user domain.User) string {
+	if slackID, ok := d.slackIDs[user.ID]; ok {
+		return "<@" + slackID + ">"
+	}
+	return "@" + user.Username
+}
+
+func NewChatService(cr repository.ChatChannelRepository, tr repository.TeamRepository, ur repository.UserRepository, ir repository.IdentityRepository) *ChatService {
+	return &ChatService{
+		channelRepo:  cr,
+		teamRepo:     tr,
+		userRepo:     ur,
+		identityRepo: ir,
+	}
+}
+
+// SetChannel routes the notifications of the team and of its sub-teams
+// without a channel of their own to the webhook. Missing or empty templates
+// fall back to the defaults.
+func (s *ChatService) SetChannel(ctx context.Context, channel domain.ChatChannel) (domain.ChatChannel, error) {
+	if err := authorizeTeamLead(ctx, s.userRepo, channel.TeamName); err != nil {
+		return domain.ChatChannel{}, err
+	}
+
+	endpoint, err := url.Parse(channel.WebhookURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return domain.ChatChannel{}, fmt.Errorf("%w: webhook_url must be an absolute http(s) url", domain.ErrInvalidArgument)
+	}
+
+	templates := make(map[domain.PREventKind]string, len(channel.Templates))
+	for kind, text := range channel.Templates {
+		if !slices.Contains(domain.ChatEventKinds, kind) {
+			return domain.ChatChannel{}, fmt.Errorf("%w: no chat messages are sent for %q", domain.ErrInvalidArgument, kind)
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if _, err := renderChatMessage(kind, text, sampleChatMessageData(kind)); err != nil {
+			return domain.ChatChannel{}, fmt.Errorf("%w: template for %s: %v", domain.ErrInvalidArgument, kind, err)
+		}
+		templates[kind] = text
+	}
+	channel.Templates = templates
+
+	return s.channelRepo.SetChatChannel(ctx, channel)
+}
+
+func (s *ChatService) Channel(ctx context.Context, teamName domain.TeamName) (domain.ChatChannel, error) {
+	return s.channelRepo.ChatChannel(ctx, teamName)
+}
+
+func (s *ChatService) RemoveChannel(ctx context.Context, teamName domain.TeamName) error {
+	if err := authorizeTeamLead(ctx, s.userRepo, teamName); err != nil {
+		return err
+	}
+
+	return s.channelRepo.DeleteChatChannel(ctx, teamName)
+}
+
+// Message renders the notification for the channel of the team reviewing the
+// pull request, or of its closest parent with a channel. It reports false
+// when no team has a channel, the event is not posted to chats, or the
+// template rendered blank text.
+func (s *ChatService) Message(ctx context.Context, n Notification) (ChatMessage, bool, error) {
+	if !slices.Contains(domain.ChatEventKinds, n.Kind) {
+		return ChatMessage{}, false, nil
+	}
+
+	author := s.chatUser(ctx, n.PullRequest.AuthorID)
+
+	teamName := n.PullRequest.TeamName
+	if teamName == "" {
+		teamName = author.TeamName
+	}
+
+	channel, ok, err := s.channelOf(ctx, teamName)
+	if err != nil || !ok {
+		return ChatMessage{}, false, err
+	}
+
+	data := ChatMessageData{
+		Event:       n.Kind,
+		PullRequest: n.PullRequest,
+		Author:      author,
+		Reviewers:   make([]domain.User, len(n.Reviewers)),
+		Reason:      n.Reason,
+		Actor:       n.Actor,
+	}
+	for i, reviewerID := range n.Reviewers {
+		data.Reviewers[i] = s.chatUser(ctx, reviewerID)
+	}
+	if n.PreviousReviewer != "" {
+		data.PreviousReviewer = s.chatUser(ctx, n.PreviousReviewer)
+	}
+
+	data.slackIDs, err = s.slackIDs(ctx, append([]domain.User{author, data.PreviousReviewer}, data.Reviewers...))
+	if err != nil {
+		return ChatMessage{}, false, err
+	}
+
+	text, ok := channel.Templates[n.Kind]
+	if !ok {
+		text = defaultChatTemplates[n.Kind]
+	}
+
+	rendered, err := renderChatMessage(n.Kind, text, data)
+	if err != nil {
+		return ChatMessage{}, false, err
+	}
+
+	if rendered == "" {
+		return ChatMessage{}, false, nil
+	}
+
+	return ChatMessage{WebhookURL: channel.WebhookURL, Text: rendered}, true, nil
+}
+
+// channelOf walks up the team hierarchy until it finds a team with a channel.
+func (s *ChatService) channelOf(ctx context.Context, teamName domain.TeamName) (domain.ChatChannel, bool, error) {
+	visited := map[domain.TeamName]struct{}{}
+
+	for teamName != "" {
+		if _, ok := visited[teamName]; ok {
+			break
+		}
+		visited[teamName] = struct{}{}
+
+		channel, err := s.channelRepo.ChatChannel(ctx, teamName)
+		if err == nil {
+			return channel, true, nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return domain.ChatChannel{}, false, err
+		}
+
+		team, err := s.teamRepo.TeamByName(ctx, teamName)
+		if errors.Is(err, domain.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return domain.ChatChannel{}, false, err
+		}
+
+		teamName = team.ParentName
+	}
+
+	return domain.ChatChannel{}, false, nil
+}
+
+func (s *ChatService) chatUser(ctx context.Context, userID domain.UserID) domain.User {
+	user, err := s.userRepo.UserByID(ctx, userID)
+	if err != nil {
+		return domain.User{ID: userID, Username: string(userID)}
+	}
+
+	return user
+}
+
+func (s *ChatService) slackIDs(ctx context.Context, users []domain.User) (map[domain.UserID]string, error) {
+	slackIDs := map[domain.UserID]string{}
+	for _, user := range users {
+		if user.ID == "" {
+			continue
+		}
+
+		identities, err := s.identityRepo.IdentitiesByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, identity := range identities {
+			if identity.Provider == domain.IdentitySlack {
+				slackIDs[user.ID] = identity.ExternalID
+				break
+			}
+		}
+	}
+
+	return slackIDs, nil
+}
+
+func renderChatMessage(kind domain.PREventKind, text string, data ChatMessageData) (string, error) {
+	tmpl, err := template.New(string(kind)).Funcs(template.FuncMap{"mention": data.mention}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(rendered.String()), nil
+}
+
+// sampleChatMessageData is used to try templates out before they are stored.
+func sampleChatMessageData(kind domain.PREventKind) ChatMessageData {
+	author := domain.User{ID: "u1", Username: "author"}
+	reviewer := domain.User{ID: "u2", Username: "reviewer"}
+
+	return ChatMessageData{
+		Event: kind,
+		PullRequest: domain.PullRequest{
+			ID:                "pr-1",
+			Name:              "Sample",
+			AuthorID:          author.ID,
+			Status:            domain.StatusOpen,
+			AssignedReviewers: []domain.UserID{reviewer.ID},
+		},
+		Author:           author,
+		Reviewers:        []domain.User{reviewer},
+		PreviousReviewer: domain.User{ID: "u3", Username: "previous"},
+		Reason:           domain.ReassignManual,
+	}
+}
@@ -0,0 +1,177 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testChatEnviroment struct {
+	ctx     context.Context
+	storage *inmemory.InMemoryStorage
+
+	teamRepo    *inmemory.TeamRepo
+	userRepo    *inmemory.UserRepo
+	chatService *service.ChatService
+}
+
+func setupChatTest(t *testing.T) testChatEnviroment {
+	t.Helper()
+
+	storage, _ := inmemory.NewStorage()
+	teamRepo := inmemory.NewTeamRepo(storage)
+	userRepo := inmemory.NewUserRepo(storage)
+
+	e := testChatEnviroment{
+		ctx:         context.Background(),
+		storage:     storage,
+		teamRepo:    teamRepo,
+		userRepo:    userRepo,
+		chatService: service.NewChatService(inmemory.NewChatChannelRepo(storage), teamRepo, userRepo, inmemory.NewIdentityRepo(storage)),
+	}
+
+	require.NoError(t, teamRepo.Create(e.ctx, domain.Team{Name: "platform"}))
+	require.NoError(t, teamRepo.Create(e.ctx, domain.Team{Name: "backend", ParentName: "platform"}))
+	require.NoError(t, userRepo.Create(e.ctx, domain.User{ID: "u1", Username: "alice", TeamName: "backend", IsActive: true}))
+	require.NoError(t, userRepo.Create(e.ctx, domain.User{ID: "u2", Username: "bob", TeamName: "backend", IsActive: true}))
+
+	return e
+}
+
+var chatTestPR = domain.PullRequest{
+	ID:                "pr-1",
+	Name:              "Add search",
+	AuthorID:          "u1",
+	TeamName:          "backend",
+	Status:            domain.StatusOpen,
+	AssignedReviewers: []domain.UserID{"u2"},
+}
+
+func TestChatMessageUsesTeamTemplate(t *testing.T) {
+	e := setupChatTest(t)
+
+	_, err := e.chatService.SetChannel(e.ctx, domain.ChatChannel{
+		TeamName:   "backend",
+		WebhookURL: "https://chat.example.com/hooks/backend",
+		Templates: map[domain.PREventKind]string{
+			domain.EventReviewerAssigned: `{{range .Reviewers}}<@{{.ID}}> {{end}}review {{.PullRequest.ID}} please`,
+		},
+	})
+	require.NoError(t, err)
+
+	msg, ok, err := e.chatService.Message(e.ctx, service.Notification{
+		Kind:        domain.EventReviewerAssigned,
+		PullRequest: chatTestPR,
+		Reviewers:   chatTestPR.AssignedReviewers,
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, service.ChatMessage{
+		WebhookURL: "https://chat.example.com/hooks/backend",
+		Text:       "<@u2> review pr-1 please",
+	}, msg)
+
+	msg, ok, err = e.chatService.Message(e.ctx, service.Notification{
+		Kind:        domain.EventMerged,
+		PullRequest: chatTestPR,
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, `"Add search" (pr-1) by alice was merged`, msg.Text)
+}
+
+func TestChatMessageFallsBackToParentChannel(t *testing.T) {
+	e := setupChatTest(t)
+
+	_, err := e.chatService.SetChannel(e.ctx, domain.ChatChannel{TeamName: "platform", WebhookURL: "https://chat.example.com/hooks/platform"})
+	require.NoError(t, err)
+
+	msg, ok, err := e.chatService.Message(e.ctx, service.Notification{
+		Kind:             domain.EventReviewerReassigned,
+		PullRequest:      chatTestPR,
+		Reviewers:        []domain.UserID{"u2"},
+		PreviousReviewer: "u-gone",
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "https://chat.example.com/hooks/platform", msg.WebhookURL)
+	assert.Equal(t, `@bob please review "Add search" (pr-1) by alice, it was handed over from u-gone`, msg.Text)
+}
+
+func TestChatMessageMentionsLinkedSlackMembers(t *testing.T) {
+	e := setupChatTest(t)
+	identityService := service.NewIdentityService(inmemory.NewIdentityRepo(e.storage), e.userRepo)
+	_, err := identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: "u2", Provider: domain.IdentitySlack, ExternalID: "U012AB3CD"})
+	require.NoError(t, err)
+
+	_, err = e.chatService.SetChannel(e.ctx, domain.ChatChannel{TeamName: "backend", WebhookURL: "https://chat.example.com/hooks/backend"})
+	require.NoError(t, err)
+
+	msg, ok, err := e.chatService.Message(e.ctx, service.Notification{
+		Kind:        domain.EventReviewerAssigned,
+		PullRequest: chatTestPR,
+		Reviewers:   []domain.UserID{"u2", "u1"},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, `<@U012AB3CD> @alice please review "Add search" (pr-1) by alice`, msg.Text)
+}
+
+func TestChatMessageWithoutChannel(t *testing.T) {
+	e := setupChatTest(t)
+
+	_, ok, err := e.chatService.Message(e.ctx, service.Notification{
+		Kind:        domain.EventReviewerAssigned,
+		PullRequest: chatTestPR,
+		Reviewers:   chatTestPR.AssignedReviewers,
+	})
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestChatChannelFollowsTeamRename(t *testing.T) {
+	e := setupChatTest(t)
+
+	_, err := e.chatService.SetChannel(e.ctx, domain.ChatChannel{TeamName: "backend", WebhookURL: "https://chat.example.com/hooks/backend"})
+	require.NoError(t, err)
+	require.NoError(t, e.teamRepo.Rename(e.ctx, "backend", "core"))
+
+	channel, err := e.chatService.Channel(e.ctx, "core")
+	require.NoError(t, err)
+	assert.Equal(t, domain.TeamName("core"), channel.TeamName)
+
+	_, err = e.chatService.Channel(e.ctx, "backend")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestSetChatChannelValidatesInput(t *testing.T) {
+	e := setupChatTest(t)
+
+	tests := []domain.ChatChannel{
+		{TeamName: "backend", WebhookURL: "chat.example.com/hooks"},
+		{TeamName: "backend", WebhookURL: "https://chat.example.com/hooks", Templates: map[domain.PREventKind]string{
+			domain.EventReviewSubmitted: "reviewed",
+		}},
+		{TeamName: "backend", WebhookURL: "https://chat.example.com/hooks", Templates: map[domain.PREventKind]string{
+			domain.EventMerged: "{{.PullRequest.Name",
+		}},
+		{TeamName: "backend", WebhookURL: "https://chat.example.com/hooks", Templates: map[domain.PREventKind]string{
+			domain.EventMerged: "{{.PullRequest.Title}} was merged",
+		}},
+	}
+
+	for _, channel := range tests {
+		_, err := e.chatService.SetChannel(e.ctx, channel)
+		assert.ErrorIs(t, err, domain.ErrInvalidArgument, "%+v", channel)
+	}
+
+	_, err := e.chatService.SetChannel(e.ctx, domain.ChatChannel{TeamName: "frontend", WebhookURL: "https://chat.example.com/hooks"})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Empty(t, e.storage.ChatChannels)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"time"
+)
+
+// idempotencyLease is how long a key stays taken by a request in progress.
+// It outlives any request, so the key is only freed early when the service
+// stopped before the request completed.
+const idempotencyLease = time.Minute
+
+// IdempotencyService makes retries of a request with the same idempotency
+// key get the response of the first one instead of being served again.
+type IdempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepository
+	ttl             time.Duration
+}
+
+// NewIdempotencyService keeps responses for ttl after the request completed.
+func NewIdempotencyService(ir repository.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: ir,
+		ttl:             ttl,
+	}
+}
+
+// Begin claims the key for the request with the given hash. True means the
+// request was completed before and its stored response is returned, false
+// means it has to be served and then passed to Complete or Release.
+func (s *IdempotencyService) Begin(ctx context.Context, key, requestHash string) (domain.IdempotentRequest, bool, error) {
+	now := time.Now()
+	request, claimed, err := s.idempotencyRepo.ClaimIdempotencyKey(ctx, domain.IdempotentRequest{
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(idempotencyLease),
+	}, now)
+	if err != nil {
+		return domain.IdempotentRequest{}, false, err
+	}
+
+	if claimed {
+		return domain.IdempotentRequest{}, false, nil
+	}
+
+	if request.RequestHash != requestHash {
+		return domain.IdempotentRequest{}, false, domain.ErrKeyReused
+	}
+
+	if !request.Completed() {
+		return domain.IdempotentRequest{}, false, domain.ErrKeyInProgress
+	}
+
+	return request, true, nil
+}
+
+// Complete stores the response of the request. Server errors are not
+// stored, so that a retry gets served again.
+func (s *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	if statusCode >= 500 {
+		return s.Release(ctx, key)
+	}
+
+	return s.idempotencyRepo.CompleteIdempotentRequest(ctx, key, statusCode, body, time.Now().Add(s.ttl))
+}
+
+// Release frees the key of a request that did not complete.
+func (s *IdempotencyService) Release(ctx context.Context, key string) error {
+	return s.idempotencyRepo.DeleteIdempotentRequest(ctx, key)
+}
+
+// Purge deletes the expired requests and returns how many there were.
+func (s *IdempotencyService) Purge(ctx context.Context) (int64, error) {
+	return s.idempotencyRepo.DeleteExpiredIdempotentRequests(ctx, time.Now())
+}
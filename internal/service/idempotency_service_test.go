@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotencyTest(ttl time.Duration) (context.Context, *inmemory.InMemoryStorage, *service.IdempotencyService) {
+	storage, _ := inmemory.NewStorage()
+
+	return context.Background(), storage, service.NewIdempotencyService(inmemory.NewIdempotencyRepo(storage), ttl)
+}
+
+func TestIdempotentRequestIsReplayed(t *testing.T) {
+	ctx, _, idempotencyService := setupIdempotencyTest(time.Hour)
+
+	_, replay, err := idempotencyService.Begin(ctx, "key-1", "hash-1")
+	require.NoError(t, err)
+	require.False(t, replay)
+
+	require.NoError(t, idempotencyService.Complete(ctx, "key-1", 201, []byte(`{"pr":{}}`)))
+
+	stored, replay, err := idempotencyService.Begin(ctx, "key-1", "hash-1")
+	require.NoError(t, err)
+	require.True(t, replay)
+	assert.Equal(t, 201, stored.StatusCode)
+	assert.Equal(t, `{"pr":{}}`, string(stored.Body))
+}
+
+func TestIdempotencyKeyConflicts(t *testing.T) {
+	ctx, _, idempotencyService := setupIdempotencyTest(time.Hour)
+
+	_, _, err := idempotencyService.Begin(ctx, "key-1", "hash-1")
+	require.NoError(t, err)
+
+	_, _, err = idempotencyService.Begin(ctx, "key-1", "hash-1")
+	assert.ErrorIs(t, err, domain.ErrKeyInProgress)
+
+	require.NoError(t, idempotencyService.Complete(ctx, "key-1", 200, nil))
+
+	_, _, err = idempotencyService.Begin(ctx, "key-1", "hash-2")
+	assert.ErrorIs(t, err, domain.ErrKeyReused)
+}
+
+func TestServerErrorsAreNotStored(t *testing.T) {
+	ctx, storage, idempotencyService := setupIdempotencyTest(time.Hour)
+
+	_, _, err := idempotencyService.Begin(ctx, "key-1", "hash-1")
+	require.NoError(t, err)
+	require.NoError(t, idempotencyService.Complete(ctx, "key-1", 500, []byte(`{}`)))
+	assert.Empty(t, storage.IdempotencyKeys)
+
+	_, replay, err := idempotencyService.Begin(ctx, "key-1", "hash-2")
+	require.NoError(t, err)
+	assert.False(t, replay)
+}
+
+func TestExpiredIdempotencyKeyIsReused(t *testing.T) {
+	ctx, storage, idempotencyService := setupIdempotencyTest(time.Hour)
+
+	_, _, err := idempotencyService.Begin(ctx, "key-1", "hash-1")
+	require.NoError(t, err)
+	require.NoError(t, idempotencyService.Complete(ctx, "key-1", 200, nil))
+
+	request := storage.IdempotencyKeys["key-1"]
+	request.ExpiresAt = time.Now().Add(-time.Second)
+	storage.IdempotencyKeys["key-1"] = request
+
+	_, replay, err := idempotencyService.Begin(ctx, "key-1", "hash-2")
+	require.NoError(t, err)
+	assert.False(t, replay)
+	assert.Equal(t, "hash-2", storage.IdempotencyKeys["key-1"].RequestHash)
+
+	request = storage.IdempotencyKeys["key-1"]
+	request.ExpiresAt = time.Now().Add(-time.Second)
+	storage.IdempotencyKeys["key-1"] = request
+
+	purged, err := idempotencyService.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Empty(t, storage.IdempotencyKeys)
+}
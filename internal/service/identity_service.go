@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+)
+
+type IdentityService struct {
+	identityRepo repository.IdentityRepository
+	userRepo     repository.UserRepository
+}
+
+func NewIdentityService(ir repository.IdentityRepository, ur repository.UserRepository) *IdentityService {
+	return &IdentityService{
+		identityRepo: ir,
+		userRepo:     ur,
+	}
+}
+
+// LinkIdentity links the user to their account at the provider, an account
+// linked to another user before is moved over.
+func (s *IdentityService) LinkIdentity(ctx context.Context, identity domain.UserIdentity) (domain.UserIdentity, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.UserIdentity{}, err
+	}
+
+	externalID, err := normalizeIdentity(identity.Provider, identity.ExternalID)
+	if err != nil {
+		return domain.UserIdentity{}, err
+	}
+	identity.ExternalID = externalID
+
+	return s.identityRepo.LinkIdentity(ctx, identity)
+}
+
+func (s *IdentityService) UnlinkIdentity(ctx context.Context, provider domain.IdentityProvider, externalID string) error {
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	externalID, err := normalizeIdentity(provider, externalID)
+	if err != nil {
+		return err
+	}
+
+	return s.identityRepo.UnlinkIdentity(ctx, provider, externalID)
+}
+
+func (s *IdentityService) Identities(ctx context.Context, userID domain.UserID) ([]domain.UserIdentity, error) {
+	if _, err := s.userRepo.UserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.identityRepo.IdentitiesByUserID(ctx, userID)
+}
+
+// UserByIdentity finds the user by their account at the provider.
+func (s *IdentityService) UserByIdentity(ctx context.Context, provider domain.IdentityProvider, externalID string) (domain.User, error) {
+	externalID, err := normalizeIdentity(provider, externalID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	userID, err := s.identityRepo.UserIDByIdentity(ctx, provider, externalID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return s.userRepo.UserByID(ctx, userID)
+}
+
+func normalizeIdentity(provider domain.IdentityProvider, externalID string) (string, error) {
+	if !slices.Contains(domain.IdentityProviders, provider) {
+		return "", fmt.Errorf("%w: unknown identity provider %q", domain.ErrInvalidArgument, provider)
+	}
+
+	externalID = domain.NormalizeExternalID(provider, externalID)
+	if externalID == "" {
+		return "", fmt.Errorf("%w: external_id must not be empty", domain.ErrInvalidArgument)
+	}
+
+	if provider == domain.IdentityEmail && !isValidEmail(externalID) {
+		return "", fmt.Errorf("%w: external_id must be an email address", domain.ErrInvalidArgument)
+	}
+
+	return externalID, nil
+}
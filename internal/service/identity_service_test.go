@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"pr-reviewer-service/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityLookupAndUnlink(t *testing.T) {
+	e := setupVCSTest(t)
+	for _, identity := range []domain.UserIdentity{
+		{UserID: authorID, Provider: domain.IdentitySlack, ExternalID: "U012AB3CD"},
+		{UserID: authorID, Provider: domain.IdentityEmail, ExternalID: "Author@Example.com"},
+	} {
+		_, err := e.identityService.LinkIdentity(e.ctx, identity)
+		require.NoError(t, err)
+	}
+
+	identities, err := e.identityService.Identities(e.ctx, authorID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UserIdentity{
+		{UserID: authorID, Provider: domain.IdentityEmail, ExternalID: "author@example.com"},
+		{UserID: authorID, Provider: domain.IdentityGitHub, ExternalID: "octo-author"},
+		{UserID: authorID, Provider: domain.IdentitySlack, ExternalID: "U012AB3CD"},
+	}, identities)
+
+	user, err := e.identityService.UserByIdentity(e.ctx, domain.IdentityEmail, "AUTHOR@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, authorID, user.ID)
+
+	require.NoError(t, e.identityService.UnlinkIdentity(e.ctx, domain.IdentityGitHub, "Octo-Author"))
+	_, err = e.identityService.UserByIdentity(e.ctx, domain.IdentityGitHub, "octo-author")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, e.identityService.UnlinkIdentity(e.ctx, domain.IdentityGitHub, "octo-author"), domain.ErrNotFound)
+
+	_, err = e.identityService.Identities(e.ctx, "u-unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestLinkIdentityValidates(t *testing.T) {
+	e := setupVCSTest(t)
+
+	_, err := e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: authorID, Provider: "bitbucket", ExternalID: "author"})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, err = e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: authorID, Provider: domain.IdentityGitHub, ExternalID: " "})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, err = e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: authorID, Provider: domain.IdentityEmail, ExternalID: "Author <author@example.com>"})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, err = e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: "u-unknown", Provider: domain.IdentityGitHub, ExternalID: "someone"})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// linking a login again moves it to the new user
+	_, err = e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: firstReviewerID, Provider: domain.IdentityGitHub, ExternalID: "octo-author"})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UserIdentity{{UserID: firstReviewerID, Provider: domain.IdentityGitHub, ExternalID: "octo-author"}}, e.storage.Identities)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+)
+
+// Notification tells people about a change of a pull request. Reviewers are
+// the ones to ping: the assigned reviewers, or the new one on reassignment.
+type Notification struct {
+	Kind             domain.PREventKind
+	PullRequest      domain.PullRequest
+	Reviewers        []domain.UserID
+	PreviousReviewer domain.UserID
+	Reason           domain.ReassignReason
+	Actor            string
+}
+
+// Notifier delivers notifications. Notify is called once the change is
+// stored and must not block the request, slow deliveries belong in a queue.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification)
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, Notification) {}
+
+func notifierOrNop(n Notifier) Notifier {
+	if n == nil {
+		return nopNotifier{}
+	}
+
+	return n
+}
+
+// heldNotifications holds notifications back while the change they are about
+// is not committed yet.
+type heldNotifications struct {
+	notifications []Notification
+}
+
+func (h *heldNotifications) Notify(_ context.Context, n Notification) {
+	h.notifications = append(h.notifications, n)
+}
+
+func (h *heldNotifications) sendTo(ctx context.Context, n Notifier) {
+	for _, notification := range h.notifications {
+		n.Notify(ctx, notification)
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+	"strings"
+)
+
+type OrgSyncService struct {
+	orgRepo  repository.OrgRepository
+	uow      repository.UnitOfWork
+	notifier Notifier
+}
+
+// OrgSync reports the changes needed to reach the desired org structure and,
+// once applied, the reviews handed over by removed or deactivated members.
+type OrgSync struct {
+	Changes         []domain.OrgChange
+	Applied         bool
+	ReleasedReviews []ReleasedReview
+}
+
+// desiredUser is a user of the spec together with the teams listing them, in
+// the order of the spec.
+type desiredUser struct {
+	user  domain.User
+	teams []domain.TeamName
+}
+
+func NewOrgSyncService(or repository.OrgRepository, uow repository.UnitOfWork, n Notifier) *OrgSyncService {
+	return &OrgSyncService{
+		orgRepo:  or,
+		uow:      uow,
+		notifier: n,
+	}
+}
+
+// Sync brings the teams and users in line with the spec. Teams missing from
+// the spec are archived, users missing from it are deactivated and lose their
+// memberships. With plan set nothing is changed and only the diff is returned.
+func (s *OrgSyncService) Sync(ctx context.Context, spec domain.OrgSpec, plan bool) (OrgSync, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return OrgSync{}, err
+	}
+
+	users, err := desiredUsers(spec)
+	if err != nil {
+		return OrgSync{}, err
+	}
+
+	if plan {
+		_, changes, err := planSync(ctx, s.orgRepo, spec, users)
+		if err != nil {
+			return OrgSync{}, err
+		}
+
+		return OrgSync{Changes: changes, ReleasedReviews: []ReleasedReview{}}, nil
+	}
+
+	var result OrgSync
+	err = withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		snapshot, changes, err := planSync(ctx, tx.Org(), spec, users)
+		if err != nil {
+			return err
+		}
+
+		result = OrgSync{Changes: changes, ReleasedReviews: []ReleasedReview{}}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if err := tx.Org().Apply(ctx, changes); err != nil {
+			return err
+		}
+		result.Applied = true
+
+		result.ReleasedReviews, err = releaseSyncedReviews(ctx, rr, snapshot, changes)
+		return err
+	})
+	if err != nil {
+		return OrgSync{}, err
+	}
+
+	return result, nil
+}
+
+// planSync returns the current org structure along with the changes needed
+// to reach the spec.
+func planSync(ctx context.Context, orgRepo repository.OrgRepository, spec domain.OrgSpec, users []desiredUser) (domain.OrgSnapshot, []domain.OrgChange, error) {
+	snapshot, err := orgRepo.Snapshot(ctx)
+	if err != nil {
+		return domain.OrgSnapshot{}, nil, err
+	}
+
+	changes, err := planOrgChanges(spec, users, snapshot)
+	if err != nil {
+		return domain.OrgSnapshot{}, nil, err
+	}
+
+	return snapshot, changes, nil
+}
+
+// releaseSyncedReviews hands over the open reviews of users who were
+// deactivated or left a team with the sync.
+func releaseSyncedReviews(ctx context.Context, rr reviewReleaser, snapshot domain.OrgSnapshot, changes []domain.OrgChange) ([]ReleasedReview, error) {
+	before := make(map[domain.UserID]domain.User, len(snapshot.Users))
+	for _, user := range snapshot.Users {
+		before[user.ID] = user
+	}
+
+	released := []ReleasedReview{}
+	deactivated := map[domain.UserID]struct{}{}
+
+	for _, change := range changes {
+		if change.Kind != domain.ChangeUpdateUser || change.User.IsActive {
+			continue
+		}
+
+		user := before[change.User.ID]
+		if !user.IsActive {
+			continue
+		}
+		deactivated[user.ID] = struct{}{}
+
+		rel, err := rr.releaseAllOpenReviews(ctx, user, domain.ReassignDeactivated)
+		if err != nil {
+			return nil, err
+		}
+		released = append(released, rel...)
+	}
+
+	for _, change := range changes {
+		if change.Kind != domain.ChangeRemoveMembership {
+			continue
+		}
+		if _, ok := deactivated[change.User.ID]; ok {
+			continue
+		}
+
+		rel, err := rr.releaseOpenReviews(ctx, change.User.ID, change.TeamName, domain.ReassignLeftTeam)
+		if err != nil {
+			return nil, err
+		}
+		released = append(released, rel...)
+	}
+
+	return released, nil
+}
+
+// desiredUsers validates the members of the spec and merges the entries of
+// users listed in several teams.
+func desiredUsers(spec domain.OrgSpec) ([]desiredUser, error) {
+	users := []desiredUser{}
+	index := map[domain.UserID]int{}
+
+	for _, team := range spec.Teams {
+		for _, member := range team.Members {
+			if member.UserID == "" || member.Username == "" {
+				return nil, fmt.Errorf("%w: team %s has a member without user_id or username", domain.ErrInvalidArgument, team.Name)
+			}
+
+			role := member.Role
+			if role == "" {
+				role = domain.RoleMember
+			}
+			if !isKnownRole(role) {
+				return nil, fmt.Errorf("%w: unknown role %s of user %s", domain.ErrInvalidArgument, role, member.UserID)
+			}
+			if !isValidEmail(member.Email) {
+				return nil, fmt.Errorf("%w: invalid email %s of user %s", domain.ErrInvalidArgument, member.Email, member.UserID)
+			}
+
+			user := domain.User{
+				ID:       member.UserID,
+				Username: member.Username,
+				FullName: member.FullName,
+				Email:    member.Email,
+				Role:     role,
+				IsActive: member.IsActive,
+			}
+
+			i, ok := index[member.UserID]
+			if !ok {
+				index[member.UserID] = len(users)
+				users = append(users, desiredUser{user: user, teams: []domain.TeamName{team.Name}})
+				continue
+			}
+
+			if !sameProfile(users[i].user, user) {
+				return nil, fmt.Errorf("%w: user %s is described differently in several teams", domain.ErrInvalidArgument, member.UserID)
+			}
+			if slices.Contains(users[i].teams, team.Name) {
+				return nil, fmt.Errorf("%w: user %s is listed twice in team %s", domain.ErrInvalidArgument, member.UserID, team.Name)
+			}
+			users[i].teams = append(users[i].teams, team.Name)
+		}
+	}
+
+	slices.SortFunc(users, func(a, b desiredUser) int {
+		return strings.Compare(string(a.user.ID), string(b.user.ID))
+	})
+
+	return users, nil
+}
+
+// planOrgChanges lists the changes turning the snapshot into the spec. Teams
+// are created parents first, memberships are added before the home team is
+// switched and removed only afterwards, and teams are archived last.
+func planOrgChanges(spec domain.OrgSpec, users []desiredUser, snapshot domain.OrgSnapshot) ([]domain.OrgChange, error) {
+	specTeams := make(map[domain.TeamName]domain.TeamSpec, len(spec.Teams))
+	for _, team := range spec.Teams {
+		if team.Name == "" {
+			return nil, fmt.Errorf("%w: team without team_name", domain.ErrInvalidArgument)
+		}
+		if _, ok := specTeams[team.Name]; ok {
+			return nil, fmt.Errorf("%w: team %s is listed twice", domain.ErrInvalidArgument, team.Name)
+		}
+		specTeams[team.Name] = team
+	}
+
+	for _, team := range spec.Teams {
+		if team.ParentName == "" {
+			continue
+		}
+		if _, ok := specTeams[team.ParentName]; !ok {
+			return nil, fmt.Errorf("%w: parent %s of team %s is not part of the spec", domain.ErrInvalidArgument, team.ParentName, team.Name)
+		}
+	}
+
+	currentTeams := make(map[domain.TeamName]domain.Team, len(snapshot.Teams))
+	for _, team := range snapshot.Teams {
+		currentTeams[team.Name] = team
+	}
+
+	for _, team := range spec.Teams {
+		if current, ok := currentTeams[team.Name]; ok && current.ArchivedAt != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrTeamArchived, team.Name)
+		}
+	}
+
+	creates, err := teamCreations(spec, specTeams, currentTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := creates
+	for _, team := range spec.Teams {
+		current, ok := currentTeams[team.Name]
+		if ok && current.ParentName != team.ParentName {
+			changes = append(changes, domain.OrgChange{Kind: domain.ChangeSetTeamParent, TeamName: team.Name, ParentName: team.ParentName})
+		}
+	}
+
+	currentUsers := make(map[domain.UserID]domain.User, len(snapshot.Users))
+	for _, user := range snapshot.Users {
+		currentUsers[user.ID] = user
+	}
+
+	var additions, homes, removals []domain.OrgChange
+	listed := make(map[domain.UserID]struct{}, len(users))
+
+	for _, desired := range users {
+		listed[desired.user.ID] = struct{}{}
+
+		current, ok := currentUsers[desired.user.ID]
+		if !ok {
+			changes = append(changes, domain.OrgChange{Kind: domain.ChangeCreateUser, User: desired.user})
+		} else if !sameProfile(current, desired.user) {
+			changes = append(changes, domain.OrgChange{Kind: domain.ChangeUpdateUser, User: desired.user})
+		}
+
+		for _, teamName := range desired.teams {
+			if !current.IsMemberOf(teamName) {
+				additions = append(additions, domain.OrgChange{Kind: domain.ChangeAddMembership, TeamName: teamName, User: desired.user})
+			}
+		}
+
+		if current.TeamName != desired.teams[0] {
+			homes = append(homes, domain.OrgChange{Kind: domain.ChangeSetHomeTeam, TeamName: desired.teams[0], User: desired.user})
+		}
+
+		for _, teamName := range current.Teams {
+			if !slices.Contains(desired.teams, teamName) {
+				removals = append(removals, domain.OrgChange{Kind: domain.ChangeRemoveMembership, TeamName: teamName, User: desired.user})
+			}
+		}
+	}
+
+	for _, current := range snapshot.Users {
+		if _, ok := listed[current.ID]; ok {
+			continue
+		}
+
+		if current.IsActive {
+			deactivated := current
+			deactivated.IsActive = false
+			changes = append(changes, domain.OrgChange{Kind: domain.ChangeUpdateUser, User: deactivated})
+		}
+		if current.TeamName != "" {
+			homes = append(homes, domain.OrgChange{Kind: domain.ChangeSetHomeTeam, User: current})
+		}
+		for _, teamName := range current.Teams {
+			removals = append(removals, domain.OrgChange{Kind: domain.ChangeRemoveMembership, TeamName: teamName, User: current})
+		}
+	}
+
+	changes = append(changes, additions...)
+	changes = append(changes, homes...)
+	changes = append(changes, removals...)
+
+	for _, team := range snapshot.Teams {
+		if _, ok := specTeams[team.Name]; !ok && team.ArchivedAt == nil {
+			changes = append(changes, domain.OrgChange{Kind: domain.ChangeArchiveTeam, TeamName: team.Name})
+		}
+	}
+
+	return changes, nil
+}
+
+// teamCreations returns the teams of the spec that do not exist yet, each
+// one after its parent. A cycle of parents in the spec is rejected.
+func teamCreations(spec domain.OrgSpec, specTeams map[domain.TeamName]domain.TeamSpec, currentTeams map[domain.TeamName]domain.Team) ([]domain.OrgChange, error) {
+	creates := []domain.OrgChange{}
+	done := map[domain.TeamName]struct{}{}
+
+	for _, team := range spec.Teams {
+		chain := []domain.TeamSpec{}
+		onChain := map[domain.TeamName]struct{}{}
+
+		for name := team.Name; name != ""; name = specTeams[name].ParentName {
+			if _, ok := done[name]; ok {
+				break
+			}
+			if _, ok := onChain[name]; ok {
+				return nil, fmt.Errorf("%w: team %s is part of a parent cycle", domain.ErrInvalidArgument, name)
+			}
+			onChain[name] = struct{}{}
+			chain = append(chain, specTeams[name])
+		}
+
+		for i := len(chain) - 1; i >= 0; i-- {
+			done[chain[i].Name] = struct{}{}
+			if _, exists := currentTeams[chain[i].Name]; !exists {
+				creates = append(creates, domain.OrgChange{Kind: domain.ChangeCreateTeam, TeamName: chain[i].Name, ParentName: chain[i].ParentName})
+			}
+		}
+	}
+
+	return creates, nil
+}
+
+func sameProfile(a, b domain.User) bool {
+	return a.Username == b.Username && a.FullName == b.FullName && a.Email == b.Email && a.Role == b.Role && a.IsActive == b.IsActive
+}
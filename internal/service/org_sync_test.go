@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOrgSyncEnviroment struct {
+	ctx     context.Context
+	storage *inmemory.InMemoryStorage
+
+	orgSyncService *service.OrgSyncService
+}
+
+func setupOrgSyncTest() testOrgSyncEnviroment {
+	storage, _ := inmemory.NewStorage()
+
+	orgSyncService := service.NewOrgSyncService(
+		inmemory.NewOrgRepo(storage),
+		inmemory.NewUnitOfWork(storage),
+		nil,
+	)
+
+	return testOrgSyncEnviroment{
+		ctx:            context.Background(),
+		storage:        storage,
+		orgSyncService: orgSyncService,
+	}
+}
+
+var orgSpec = domain.OrgSpec{
+	Teams: []domain.TeamSpec{
+		{
+			Name:       "backend",
+			ParentName: "platform",
+			Members: []domain.MemberSpec{
+				{UserID: "u-1", Username: "Alice", Role: domain.RoleLead, IsActive: true},
+				{UserID: "u-2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			Name: "platform",
+			Members: []domain.MemberSpec{
+				{UserID: "u-3", Username: "Carol", IsActive: true},
+				{UserID: "u-1", Username: "Alice", Role: domain.RoleLead, IsActive: true},
+			},
+		},
+	},
+}
+
+func TestOrgSyncPlanDoesNotChangeAnything(t *testing.T) {
+	e := setupOrgSyncTest()
+
+	sync, err := e.orgSyncService.Sync(e.ctx, orgSpec, true)
+
+	require.NoError(t, err)
+	assert.False(t, sync.Applied)
+	require.NotEmpty(t, sync.Changes)
+	assert.Equal(t, domain.OrgChange{Kind: domain.ChangeCreateTeam, TeamName: "platform"}, sync.Changes[0])
+	assert.Equal(t, domain.OrgChange{Kind: domain.ChangeCreateTeam, TeamName: "backend", ParentName: "platform"}, sync.Changes[1])
+	assert.Empty(t, e.storage.Teams)
+	assert.Empty(t, e.storage.Users)
+}
+
+func TestOrgSyncAppliesSpec(t *testing.T) {
+	e := setupOrgSyncTest()
+
+	sync, err := e.orgSyncService.Sync(e.ctx, orgSpec, false)
+
+	require.NoError(t, err)
+	assert.True(t, sync.Applied)
+	assert.Equal(t, domain.TeamName("platform"), e.storage.Teams["backend"].ParentName)
+
+	alice := e.storage.Users["u-1"]
+	assert.Equal(t, domain.TeamName("backend"), alice.TeamName)
+	assert.ElementsMatch(t, []domain.TeamName{"backend", "platform"}, alice.Teams)
+	assert.Equal(t, domain.RoleLead, alice.Role)
+	assert.Equal(t, domain.RoleMember, e.storage.Users["u-2"].Role)
+
+	again, err := e.orgSyncService.Sync(e.ctx, orgSpec, false)
+	require.NoError(t, err)
+	assert.Empty(t, again.Changes)
+	assert.False(t, again.Applied)
+}
+
+func TestOrgSyncDeactivatesUsersAndArchivesTeamsMissingFromSpec(t *testing.T) {
+	e := setupOrgSyncTest()
+	_, err := e.orgSyncService.Sync(e.ctx, orgSpec, false)
+	require.NoError(t, err)
+
+	e.storage.Teams["legacy"] = domain.Team{Name: "legacy"}
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "PR 1",
+		AuthorID:          "u-1",
+		TeamName:          "backend",
+		Status:            domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{"u-2"},
+		CreatedAt:         time.Now(),
+	}
+
+	spec := domain.OrgSpec{Teams: []domain.TeamSpec{
+		{Name: "backend", ParentName: "platform", Members: []domain.MemberSpec{
+			{UserID: "u-1", Username: "Alice", Role: domain.RoleLead, IsActive: true},
+		}},
+		{Name: "platform", Members: []domain.MemberSpec{
+			{UserID: "u-3", Username: "Carol", IsActive: true},
+		}},
+	}}
+
+	sync, err := e.orgSyncService.Sync(e.ctx, spec, false)
+
+	require.NoError(t, err)
+	assert.Contains(t, sync.Changes, domain.OrgChange{Kind: domain.ChangeArchiveTeam, TeamName: "legacy"})
+	assert.NotNil(t, e.storage.Teams["legacy"].ArchivedAt)
+
+	bob := e.storage.Users["u-2"]
+	assert.False(t, bob.IsActive)
+	assert.Empty(t, bob.Teams)
+	assert.Empty(t, bob.TeamName)
+
+	alice := e.storage.Users["u-1"]
+	assert.Equal(t, []domain.TeamName{"backend"}, alice.Teams)
+
+	assert.Equal(t, []service.ReleasedReview{{PullRequestID: "pr-1", ReplacedBy: "u-3"}}, sync.ReleasedReviews)
+	assert.Equal(t, []domain.UserID{"u-3"}, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+func TestOrgSyncRollsBackWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupOrgSyncTest()
+	_, err := e.orgSyncService.Sync(e.ctx, orgSpec, false)
+	require.NoError(t, err)
+
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: "u-1", TeamName: "backend", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{"u-2"},
+	}
+	failingService := service.NewOrgSyncService(
+		inmemory.NewOrgRepo(e.storage),
+		failingReviewUnitOfWork{inmemory.NewUnitOfWork(e.storage)},
+		nil,
+	)
+
+	spec := domain.OrgSpec{Teams: []domain.TeamSpec{
+		{Name: "backend", ParentName: "platform", Members: []domain.MemberSpec{
+			{UserID: "u-1", Username: "Alice", Role: domain.RoleLead, IsActive: true},
+		}},
+		{Name: "platform", Members: []domain.MemberSpec{
+			{UserID: "u-3", Username: "Carol", IsActive: true},
+		}},
+	}}
+
+	_, err = failingService.Sync(e.ctx, spec, false)
+
+	require.ErrorIs(t, err, errReviewerChange)
+	bob := e.storage.Users["u-2"]
+	assert.True(t, bob.IsActive)
+	assert.Equal(t, domain.TeamName("backend"), bob.TeamName)
+	assert.Equal(t, []domain.UserID{"u-2"}, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+func TestOrgSyncRejectsInvalidSpec(t *testing.T) {
+	e := setupOrgSyncTest()
+
+	cycle := domain.OrgSpec{Teams: []domain.TeamSpec{
+		{Name: "a", ParentName: "b"},
+		{Name: "b", ParentName: "a"},
+	}}
+	_, err := e.orgSyncService.Sync(e.ctx, cycle, true)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	conflicting := domain.OrgSpec{Teams: []domain.TeamSpec{
+		{Name: "a", Members: []domain.MemberSpec{{UserID: "u-1", Username: "Alice", IsActive: true}}},
+		{Name: "b", Members: []domain.MemberSpec{{UserID: "u-1", Username: "Alice", IsActive: false}}},
+	}}
+	_, err = e.orgSyncService.Sync(e.ctx, conflicting, true)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	unknownParent := domain.OrgSpec{Teams: []domain.TeamSpec{{Name: "a", ParentName: "missing"}}}
+	_, err = e.orgSyncService.Sync(e.ctx, unknownParent, true)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestOrgSyncRejectsArchivedTeam(t *testing.T) {
+	e := setupOrgSyncTest()
+	archivedAt := time.Now()
+	e.storage.Teams["backend"] = domain.Team{Name: "backend", ArchivedAt: &archivedAt}
+
+	_, err := e.orgSyncService.Sync(e.ctx, domain.OrgSpec{Teams: []domain.TeamSpec{{Name: "backend"}}}, false)
+
+	assert.ErrorIs(t, err, domain.ErrTeamArchived)
+}
@@ -2,119 +2,299 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/repository"
-	"time"
 )
 
+const maxReviewers = 2
+
 type PullRequestService struct {
-	prRepo     repository.PullRequestRepository
-	userRepo   repository.UserRepository
-	randomizer *rand.Rand
+	prRepo   repository.PullRequestRepository
+	userRepo repository.UserRepository
+	uow      repository.UnitOfWork
+	pool     reviewerPool
+	notifier Notifier
 }
 
-func NewPullRequestService(prr repository.PullRequestRepository, ur repository.UserRepository) *PullRequestService {
-	randomizer := rand.New(rand.NewSource(time.Now().UnixNano()))
+// NewPullRequestService creates the service, with a nil notifier nobody is
+// notified about assignments and merges.
+func NewPullRequestService(prr repository.PullRequestRepository, ur repository.UserRepository, tr repository.TeamRepository, uow repository.UnitOfWork, n Notifier) *PullRequestService {
 	return &PullRequestService{
-		prRepo:     prr,
-		userRepo:   ur,
-		randomizer: randomizer,
+		prRepo:   prr,
+		userRepo: ur,
+		uow:      uow,
+		pool:     reviewerPool{teamRepo: tr, userRepo: ur},
+		notifier: notifierOrNop(n),
 	}
 }
 
-func (s *PullRequestService) CreatePR(ctx context.Context, prID domain.PullRequestID, prName string, authorID domain.UserID) (domain.PullRequest, error) {
+// CreatePR opens a pull request reviewed by the given team, an empty team
+// name falls back to the home team of the author. Reviewers missing in the
+// team are taken from its parent teams.
+func (s *PullRequestService) CreatePR(ctx context.Context, prID domain.PullRequestID, prName string, authorID domain.UserID, teamName domain.TeamName) (domain.PullRequest, error) {
 	author, err := s.userRepo.UserByID(ctx, authorID)
 	if err != nil {
 		return domain.PullRequest{}, domain.ErrNotFound
 	}
 
-	activeMembers, err := s.userRepo.ActiveUsersByTeamName(ctx, author.TeamName)
-	if err != nil {
+	if teamName == "" {
+		teamName = author.TeamName
+	} else if !author.IsMemberOf(teamName) {
+		return domain.PullRequest{}, fmt.Errorf("%w: author %q is not a member of team %q", domain.ErrInvalidArgument, authorID, teamName)
+	}
+
+	if err := authorizeTeamLead(ctx, s.userRepo, teamName); err != nil {
 		return domain.PullRequest{}, err
 	}
 
-	candidates := make([]domain.UserID, 0, len(activeMembers)-1)
-	for _, member := range activeMembers {
-		if member.ID != authorID {
-			candidates = append(candidates, member.ID)
-		}
+	excluded := map[domain.UserID]struct{}{authorID: {}}
+	levels, err := s.pool.candidateLevels(ctx, teamName, excluded, maxReviewers)
+	if err != nil {
+		return domain.PullRequest{}, err
 	}
 
-	reviewers := s.chooseReviewers(candidates)
+	reviewers := []domain.UserID{}
+	for _, level := range levels {
+		reviewers = append(reviewers, s.chooseReviewers(level, maxReviewers-len(reviewers))...)
+	}
 
 	pr := domain.PullRequest{
 		ID:                prID,
 		Name:              prName,
 		AuthorID:          authorID,
+		TeamName:          teamName,
 		Status:            domain.StatusOpen,
 		AssignedReviewers: reviewers,
 	}
 
-	return s.prRepo.Create(ctx, pr)
+	created, err := s.prRepo.Create(ctx, pr)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if len(created.AssignedReviewers) > 0 {
+		s.notifier.Notify(ctx, Notification{
+			Kind:        domain.EventReviewerAssigned,
+			PullRequest: created,
+			Reviewers:   created.AssignedReviewers,
+			Actor:       domain.ActorFromContext(ctx),
+		})
+	}
+
+	return created, nil
 }
 
 func (s *PullRequestService) MergePR(ctx context.Context, prID domain.PullRequestID) (domain.PullRequest, error) {
-	return s.prRepo.MergeByID(ctx, prID)
+	pr, err := s.prRepo.PullRequestByID(ctx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := s.authorizePullRequest(ctx, pr); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if pr.Status == domain.StatusClosed {
+		return domain.PullRequest{}, domain.ErrPRClosed
+	}
+
+	merged, err := s.prRepo.MergeByID(ctx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	// Merging is idempotent, only the first merge is announced.
+	if pr.Status != domain.StatusMerged {
+		s.notifier.Notify(ctx, Notification{
+			Kind:        domain.EventMerged,
+			PullRequest: merged,
+			Reviewers:   merged.AssignedReviewers,
+			Actor:       domain.ActorFromContext(ctx),
+		})
+	}
+
+	return merged, nil
 }
 
-func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID domain.PullRequestID, oldUserID domain.UserID) (domain.PullRequest, domain.UserID, error) {
+// ClosePR closes the pull request without merging it, closing is
+// idempotent.
+func (s *PullRequestService) ClosePR(ctx context.Context, prID domain.PullRequestID) (domain.PullRequest, error) {
 	pr, err := s.prRepo.PullRequestByID(ctx, prID)
 	if err != nil {
-		return domain.PullRequest{}, domain.UserID(""), err
+		return domain.PullRequest{}, err
+	}
+
+	if err := s.authorizePullRequest(ctx, pr); err != nil {
+		return domain.PullRequest{}, err
 	}
 
 	if pr.Status == domain.StatusMerged {
-		return domain.PullRequest{}, domain.UserID(""), domain.ErrPRMerged
+		return domain.PullRequest{}, domain.ErrPRMerged
 	}
 
-	assigned := false
-	for _, memberID := range pr.AssignedReviewers {
-		if memberID == oldUserID {
-			assigned = true
-			break
-		}
+	return s.prRepo.CloseByID(ctx, prID)
+}
+
+// ReopenPR opens a closed pull request again, the reviewers stay assigned.
+func (s *PullRequestService) ReopenPR(ctx context.Context, prID domain.PullRequestID) (domain.PullRequest, error) {
+	pr, err := s.prRepo.PullRequestByID(ctx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
 	}
-	if !assigned {
-		return domain.PullRequest{}, domain.UserID(""), domain.ErrNotAssigned
+
+	if err := s.authorizePullRequest(ctx, pr); err != nil {
+		return domain.PullRequest{}, err
 	}
 
-	oldReviewer, err := s.userRepo.UserByID(ctx, oldUserID)
-	if err != nil {
-		return domain.PullRequest{}, domain.UserID(""), domain.ErrNotFound
+	if pr.Status == domain.StatusMerged {
+		return domain.PullRequest{}, domain.ErrPRMerged
 	}
 
-	activeTeamMembers, err := s.userRepo.ActiveUsersByTeamName(ctx, oldReviewer.TeamName)
+	return s.prRepo.ReopenByID(ctx, prID)
+}
+
+// authorizePullRequest lets admins and the leads of the team reviewing the
+// pull request through. Pull requests created before teams were recorded per
+// pull request belong to the home team of their author.
+func (s *PullRequestService) authorizePullRequest(ctx context.Context, pr domain.PullRequest) error {
+	if _, ok := domain.PrincipalFromContext(ctx); !ok {
+		return nil
+	}
+
+	teamName := pr.TeamName
+	if teamName == "" {
+		author, err := s.userRepo.UserByID(ctx, pr.AuthorID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		teamName = author.TeamName
+	}
+
+	return authorizeTeamLead(ctx, s.userRepo, teamName)
+}
+
+// ReassignReviewer replaces the reviewer within one unit of work: the pull
+// request and the chosen reviewer stay locked from the checks to the write,
+// so concurrent reassignments neither pick the same reviewer nor someone who
+// is being deactivated.
+func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID domain.PullRequestID, oldUserID domain.UserID) (domain.PullRequest, domain.UserID, error) {
+	var reassigned domain.PullRequest
+	var newReviewerID domain.UserID
+
+	err := s.uow.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+		pr, err := tx.LockPullRequest(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.StatusMerged {
+			return domain.ErrPRMerged
+		}
+
+		if pr.Status == domain.StatusClosed {
+			return domain.ErrPRClosed
+		}
+
+		assigned := false
+		for _, memberID := range pr.AssignedReviewers {
+			if memberID == oldUserID {
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			return domain.ErrNotAssigned
+		}
+
+		oldReviewer, err := tx.Users().UserByID(ctx, oldUserID)
+		if err != nil {
+			return domain.ErrNotFound
+		}
+
+		// Pull requests created before teams were recorded per pull request fall
+		// back to the home team of the replaced reviewer.
+		poolTeam := pr.TeamName
+		if poolTeam == "" {
+			poolTeam = oldReviewer.TeamName
+		}
+
+		// reviewers may decline their own reviews, leads reassign within their
+		// team
+		if err := authorizeSelfOrTeamLead(ctx, tx.Users(), oldUserID, poolTeam); err != nil {
+			return err
+		}
+
+		pool := reviewerPool{teamRepo: tx.Teams(), userRepo: tx.Users()}
+		candidates, err := pool.replacementCandidates(ctx, pr, poolTeam)
+		if err != nil {
+			return err
+		}
+
+		newReviewerID, err = lockCandidate(ctx, tx, candidates)
+		if err != nil {
+			return err
+		}
+
+		reassigned, newReviewerID, err = tx.PullRequests().ReassignReviewer(ctx, prID, oldUserID, newReviewerID, domain.ReassignManual)
+		return err
+	})
 	if err != nil {
 		return domain.PullRequest{}, domain.UserID(""), err
 	}
 
-	blacklistedMembers := make(map[domain.UserID]struct{})
+	s.notifier.Notify(ctx, Notification{
+		Kind:             domain.EventReviewerReassigned,
+		PullRequest:      reassigned,
+		Reviewers:        []domain.UserID{newReviewerID},
+		PreviousReviewer: oldUserID,
+		Reason:           domain.ReassignManual,
+		Actor:            domain.ActorFromContext(ctx),
+	})
+
+	return reassigned, newReviewerID, nil
+}
 
-	blacklistedMembers[pr.AuthorID] = struct{}{}
-	for _, reviewerID := range pr.AssignedReviewers {
-		blacklistedMembers[reviewerID] = struct{}{}
+func (s *PullRequestService) SubmitReview(ctx context.Context, prID domain.PullRequestID, reviewerID domain.UserID, state domain.ReviewState) (domain.PullRequest, error) {
+	if state != domain.ReviewApproved && state != domain.ReviewChangesRequested {
+		return domain.PullRequest{}, fmt.Errorf("%w: unsupported review state %q", domain.ErrInvalidArgument, state)
 	}
 
-	candidates := make([]domain.UserID, 0, len(activeTeamMembers))
-	for _, member := range activeTeamMembers {
-		if _, exists := blacklistedMembers[member.ID]; !exists {
-			candidates = append(candidates, member.ID)
-		}
+	if err := authorizeSelf(ctx, reviewerID); err != nil {
+		return domain.PullRequest{}, err
 	}
 
-	if len(candidates) == 0 {
-		return domain.PullRequest{}, domain.UserID(""), domain.ErrNoCandidate
+	pr, err := s.prRepo.PullRequestByID(ctx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if pr.Status == domain.StatusMerged {
+		return domain.PullRequest{}, domain.ErrPRMerged
 	}
 
-	newReviewerID := candidates[s.randomizer.Intn(len(candidates))]
+	if pr.Status == domain.StatusClosed {
+		return domain.PullRequest{}, domain.ErrPRClosed
+	}
+
+	return s.prRepo.SetReviewState(ctx, prID, reviewerID, state)
+}
+
+// Timeline returns everything that happened to the pull request, oldest
+// first.
+func (s *PullRequestService) Timeline(ctx context.Context, prID domain.PullRequestID) ([]domain.PREvent, error) {
+	if _, err := s.prRepo.PullRequestByID(ctx, prID); err != nil {
+		return nil, err
+	}
 
-	return s.prRepo.ReassignReviewer(ctx, prID, oldUserID, newReviewerID)
+	return s.prRepo.Timeline(ctx, prID)
 }
 
-func (s *PullRequestService) chooseReviewers(candidates []domain.UserID) []domain.UserID {
+func (s *PullRequestService) chooseReviewers(candidates []domain.UserID, count int) []domain.UserID {
 	candidatesCount := len(candidates)
-	if candidatesCount == 0 {
+	if candidatesCount == 0 || count <= 0 {
 		return []domain.UserID{}
 	}
 
@@ -122,5 +302,5 @@ func (s *PullRequestService) chooseReviewers(candidates []domain.UserID) []domai
 		candidates[i], candidates[j] = candidates[j], candidates[i]
 	})
 
-	return candidates[:min(2, candidatesCount)]
+	return candidates[:min(count, candidatesCount)]
 }
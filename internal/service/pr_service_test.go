@@ -2,10 +2,13 @@ package service_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/repository"
 	"pr-reviewer-service/internal/repository/inmemory"
 	"pr-reviewer-service/internal/service"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +26,20 @@ type testPREnviroment struct {
 
 	prService   *service.PullRequestService
 	teamService *service.TeamService
+
+	notifier *recordingNotifier
+}
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []service.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification service.Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.notifications = append(n.notifications, notification)
 }
 
 func setup() testPREnviroment {
@@ -32,8 +49,9 @@ func setup() testPREnviroment {
 	teamRepo := inmemory.NewTeamRepo(storage)
 	prRepo := inmemory.NewPullRequestRepo(storage)
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	prService := service.NewPullRequestService(prRepo, userRepo)
+	notifier := &recordingNotifier{}
+	teamService := service.NewTeamService(teamRepo, userRepo, inmemory.NewUnitOfWork(storage), notifier)
+	prService := service.NewPullRequestService(prRepo, userRepo, teamRepo, inmemory.NewUnitOfWork(storage), notifier)
 
 	return testPREnviroment{
 		ctx:         context.Background(),
@@ -43,6 +61,7 @@ func setup() testPREnviroment {
 		prRepo:      prRepo,
 		prService:   prService,
 		teamService: teamService,
+		notifier:    notifier,
 	}
 }
 
@@ -66,10 +85,10 @@ var (
 
 func TestCreatePrWithTwoAssignedReviewers(t *testing.T) {
 	e := setup()
-	err := e.teamService.CreateTeam(e.ctx, testTeam)
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
 	require.NoError(t, err)
 
-	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID)
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
 	require.NoError(t, err)
 	assert.Equal(t, domain.PullRequestID("pr-1"), pr.ID)
 	assert.Equal(t, authorID, pr.AuthorID)
@@ -93,10 +112,10 @@ func TestCreatePRWithOneReviewer(t *testing.T) {
 			{UserID: "rev2", Username: "Reviewer 2", IsActive: false},
 		},
 	}
-	err := e.teamService.CreateTeam(e.ctx, teamOneCandidate)
+	_, err := e.teamService.CreateTeam(e.ctx, teamOneCandidate, false)
 	require.NoError(t, err)
 
-	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", "author")
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", "author", "")
 
 	require.NoError(t, err)
 	assert.Len(t, pr.AssignedReviewers, 1)
@@ -105,13 +124,13 @@ func TestCreatePRWithOneReviewer(t *testing.T) {
 
 func TestFailOnAlreadyExistingPR(t *testing.T) {
 	e := setup()
-	err := e.teamService.CreateTeam(e.ctx, testTeam)
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
 	require.NoError(t, err)
 
-	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR 1", authorID)
+	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR 1", authorID, "")
 	require.NoError(t, err)
 
-	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR 2", authorID)
+	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR 2", authorID, "")
 	require.Error(t, err)
 	assert.ErrorIs(t, err, domain.ErrPRExists)
 }
@@ -120,14 +139,14 @@ func TestFailOnAuthorNotFound(t *testing.T) {
 	t.Parallel()
 	h := setup()
 
-	_, err := h.prService.CreatePR(h.ctx, "pr-1", "Test PR", "non-existent-author")
+	_, err := h.prService.CreatePR(h.ctx, "pr-1", "Test PR", "non-existent-author", "")
 	require.Error(t, err)
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
 func setupReassignTest(t *testing.T) (testPREnviroment, domain.PullRequest) {
 	h := setup()
-	err := h.teamService.CreateTeam(h.ctx, testTeam)
+	_, err := h.teamService.CreateTeam(h.ctx, testTeam, false)
 	require.NoError(t, err)
 
 	pr := domain.PullRequest{
@@ -195,12 +214,139 @@ func TestFailReassignWhenPRNotFound(t *testing.T) {
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
+func TestConcurrentReassignsNeverPickTheSameReviewer(t *testing.T) {
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, domain.Team{
+		Name: "pair-team",
+		Members: []domain.TeamMember{
+			{UserID: "author", Username: "Author", IsActive: true},
+			{UserID: "rev1", Username: "Reviewer 1", IsActive: true},
+			{UserID: "rev2", Username: "Reviewer 2", IsActive: true},
+			{UserID: "spare", Username: "Spare", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	const prCount = 20
+	for i := range prCount {
+		_, err := e.prRepo.Create(e.ctx, domain.PullRequest{
+			ID: domain.PullRequestID(fmt.Sprintf("pr-%d", i)), Name: "Test PR", AuthorID: "author", TeamName: "pair-team",
+			Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{"rev1", "rev2"},
+		})
+		require.NoError(t, err)
+	}
+
+	// Both reviewers of every pull request are replaced at once. The first
+	// one takes the single spare member, the second one the reviewer freed by
+	// the first.
+	start := make(chan struct{})
+	errs := make(chan error, 2*prCount)
+	var wg sync.WaitGroup
+	for i := range prCount {
+		for _, reviewerID := range []domain.UserID{"rev1", "rev2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, _, err := e.prService.ReassignReviewer(e.ctx, domain.PullRequestID(fmt.Sprintf("pr-%d", i)), reviewerID)
+				errs <- err
+			}()
+		}
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	for i := range prCount {
+		pr, err := e.prRepo.PullRequestByID(e.ctx, domain.PullRequestID(fmt.Sprintf("pr-%d", i)))
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+		assert.NotEqual(t, pr.AssignedReviewers[0], pr.AssignedReviewers[1])
+		assert.Contains(t, pr.AssignedReviewers, domain.UserID("spare"))
+	}
+}
+
+// deactivatingUnitOfWork deactivates the users of every LockUsers call right
+// before their locks are taken, as if a concurrent deactivation had just
+// committed.
+type deactivatingUnitOfWork struct {
+	repository.UnitOfWork
+}
+
+func (uow deactivatingUnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, tx repository.Tx) error) error {
+	return uow.UnitOfWork.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+		return fn(ctx, deactivatingTx{Tx: tx})
+	})
+}
+
+type deactivatingTx struct {
+	repository.Tx
+}
+
+func (tx deactivatingTx) LockUsers(ctx context.Context, userIDs []domain.UserID) ([]domain.User, error) {
+	for _, userID := range userIDs {
+		if _, err := tx.Users().SetIsActiveByID(ctx, userID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return tx.Tx.LockUsers(ctx, userIDs)
+}
+
+var errReviewerChange = errors.New("reviewer change failed")
+
+// failingReviewUnitOfWork fails every change of reviewers made within its
+// units of work, so that everything done before has to be rolled back.
+type failingReviewUnitOfWork struct {
+	repository.UnitOfWork
+}
+
+func (uow failingReviewUnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context, tx repository.Tx) error) error {
+	return uow.UnitOfWork.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+		return fn(ctx, failingReviewTx{Tx: tx})
+	})
+}
+
+type failingReviewTx struct {
+	repository.Tx
+}
+
+func (tx failingReviewTx) PullRequests() repository.PullRequestRepository {
+	return failingReviewRepo{PullRequestRepository: tx.Tx.PullRequests()}
+}
+
+type failingReviewRepo struct {
+	repository.PullRequestRepository
+}
+
+func (failingReviewRepo) ReassignReviewer(context.Context, domain.PullRequestID, domain.UserID, domain.UserID, domain.ReassignReason) (domain.PullRequest, domain.UserID, error) {
+	return domain.PullRequest{}, "", errReviewerChange
+}
+
+func (failingReviewRepo) RemoveReviewer(context.Context, domain.PullRequestID, domain.UserID, domain.ReassignReason) (domain.PullRequest, error) {
+	return domain.PullRequest{}, errReviewerChange
+}
+
+func TestReassignSkipsReviewerDeactivatedBeforeLock(t *testing.T) {
+	e, pr := setupReassignTest(t)
+	prService := service.NewPullRequestService(e.prRepo, e.userRepo, e.teamRepo, deactivatingUnitOfWork{inmemory.NewUnitOfWork(e.storage)}, nil)
+
+	_, _, err := prService.ReassignReviewer(e.ctx, pr.ID, firstReviewerID)
+	require.ErrorIs(t, err, domain.ErrNoCandidate)
+
+	assert.Equal(t, []domain.UserID{firstReviewerID}, e.storage.PRs[pr.ID].AssignedReviewers)
+}
+
 func TestSuccessMergePR(t *testing.T) {
 	e := setup()
-	err := e.teamService.CreateTeam(e.ctx, testTeam)
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
 	require.NoError(t, err)
 
-	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID)
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
 	require.NoError(t, err)
 	require.Equal(t, domain.StatusOpen, pr.Status)
 
@@ -215,7 +361,7 @@ func TestSuccessMergePR(t *testing.T) {
 
 func TestFailMergeWhenPRNotFound(t *testing.T) {
 	e := setup()
-	err := e.teamService.CreateTeam(e.ctx, testTeam)
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
 	require.NoError(t, err)
 
 	mergedPR, err := e.prService.MergePR(e.ctx, "pr-1")
@@ -226,10 +372,10 @@ func TestFailMergeWhenPRNotFound(t *testing.T) {
 
 func TestMergeIsIdempotent(t *testing.T) {
 	e := setup()
-	err := e.teamService.CreateTeam(e.ctx, testTeam)
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
 	require.NoError(t, err)
 
-	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID)
+	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
 	require.NoError(t, err)
 
 	mergedPR, err := e.prService.MergePR(e.ctx, "pr-1")
@@ -241,3 +387,309 @@ func TestMergeIsIdempotent(t *testing.T) {
 	assert.Equal(t, domain.StatusMerged, mergedPR2.Status)
 	assert.Equal(t, firstMergeTime, mergedPR2.MergedAt)
 }
+
+func TestFailMergeWhenPRClosed(t *testing.T) {
+	e, pr := setupReassignTest(t)
+	_, err := e.prRepo.CloseByID(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	_, err = e.prService.MergePR(e.ctx, pr.ID)
+	assert.ErrorIs(t, err, domain.ErrPRClosed)
+
+	_, _, err = e.prService.ReassignReviewer(e.ctx, pr.ID, firstReviewerID)
+	assert.ErrorIs(t, err, domain.ErrPRClosed)
+	assert.Equal(t, domain.StatusClosed, e.storage.PRs[pr.ID].Status)
+}
+
+func TestClosePR(t *testing.T) {
+	e, pr := setupReassignTest(t)
+
+	closed, err := e.prService.ClosePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusClosed, closed.Status)
+
+	_, err = e.prService.ClosePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	_, err = e.prService.ClosePR(e.ctx, "pr-unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestFailCloseWhenPRMerged(t *testing.T) {
+	e, pr := setupReassignTest(t)
+	_, err := e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	_, err = e.prService.ClosePR(e.ctx, pr.ID)
+	assert.ErrorIs(t, err, domain.ErrPRMerged)
+}
+
+func TestReopenPRKeepsReviewers(t *testing.T) {
+	e, pr := setupReassignTest(t)
+	_, err := e.prService.ClosePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	reopened, err := e.prService.ReopenPR(e.ctx, pr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusOpen, reopened.Status)
+	assert.Equal(t, pr.AssignedReviewers, reopened.AssignedReviewers)
+
+	_, err = e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+	_, err = e.prService.ReopenPR(e.ctx, pr.ID)
+	assert.ErrorIs(t, err, domain.ErrPRMerged)
+}
+
+func TestSubmitReviewUpdatesReviewerState(t *testing.T) {
+	e, pr := setupReassignTest(t)
+
+	updatedPR, err := e.prService.SubmitReview(e.ctx, pr.ID, firstReviewerID, domain.ReviewApproved)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewApproved, updatedPR.ReviewState(firstReviewerID))
+
+	_, err = e.prService.SubmitReview(e.ctx, pr.ID, secondReviewerID, domain.ReviewApproved)
+	assert.ErrorIs(t, err, domain.ErrNotAssigned)
+
+	_, err = e.prService.SubmitReview(e.ctx, pr.ID, firstReviewerID, domain.ReviewPending)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestFailSubmitReviewWhenPRMerged(t *testing.T) {
+	e, pr := setupReassignTest(t)
+	_, err := e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	_, err = e.prService.SubmitReview(e.ctx, pr.ID, firstReviewerID, domain.ReviewApproved)
+	assert.ErrorIs(t, err, domain.ErrPRMerged)
+}
+
+func TestCreatePRUsesTargetTeamPool(t *testing.T) {
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
+	require.NoError(t, err)
+
+	infra := domain.Team{
+		Name:    "infra",
+		Members: []domain.TeamMember{{UserID: "u-infra", Username: "Infra", IsActive: true}},
+	}
+	_, err = e.teamService.CreateTeam(e.ctx, infra, false)
+	require.NoError(t, err)
+	_, err = e.teamService.AddMember(e.ctx, "infra", domain.TeamMember{UserID: authorID, Username: "Author", IsActive: true})
+	require.NoError(t, err)
+
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "infra")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TeamName("infra"), pr.TeamName)
+	assert.Equal(t, []domain.UserID{"u-infra"}, pr.AssignedReviewers)
+
+	pr, err = e.prService.CreatePR(e.ctx, "pr-2", "Test PR", authorID, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, teamName, pr.TeamName)
+	assert.NotContains(t, pr.AssignedReviewers, domain.UserID("u-infra"))
+}
+
+func TestCreatePRFailsForTeamOfOthers(t *testing.T) {
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
+	require.NoError(t, err)
+
+	_, err = e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "infra")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func setupHierarchyTest(t *testing.T) testPREnviroment {
+	e := setup()
+
+	payments := domain.Team{
+		Name: "payments",
+		Members: []domain.TeamMember{
+			{UserID: "u-payments-1", Username: "Payments 1", IsActive: true},
+			{UserID: "u-payments-2", Username: "Payments 2", IsActive: true},
+		},
+	}
+	checkout := domain.Team{
+		Name:       "payments/checkout",
+		ParentName: "payments",
+		Members: []domain.TeamMember{
+			{UserID: authorID, Username: "Author", IsActive: true},
+			{UserID: firstReviewerID, Username: "Reviewer 1", IsActive: true},
+		},
+	}
+
+	_, err := e.teamService.CreateTeam(e.ctx, payments, false)
+	require.NoError(t, err)
+	_, err = e.teamService.CreateTeam(e.ctx, checkout, false)
+	require.NoError(t, err)
+
+	return e
+}
+
+func TestCreatePREscalatesToParentTeam(t *testing.T) {
+	e := setupHierarchyTest(t)
+
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
+
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+	assert.Equal(t, firstReviewerID, pr.AssignedReviewers[0])
+	assert.Contains(t, []domain.UserID{"u-payments-1", "u-payments-2"}, pr.AssignedReviewers[1])
+}
+
+func TestReassignReviewerEscalatesToParentTeam(t *testing.T) {
+	e := setupHierarchyTest(t)
+
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: authorID, TeamName: "payments/checkout", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{firstReviewerID, "u-payments-1"},
+	}
+
+	_, newReviewerID, err := e.prService.ReassignReviewer(e.ctx, "pr-1", firstReviewerID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserID("u-payments-2"), newReviewerID)
+}
+
+func timelineKinds(events []domain.PREvent) []domain.PREventKind {
+	kinds := make([]domain.PREventKind, len(events))
+	for i, event := range events {
+		kinds[i] = event.Kind
+	}
+
+	return kinds
+}
+
+func TestTimelineKeepsReassignmentHistory(t *testing.T) {
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
+	require.NoError(t, err)
+
+	_, err = e.userRepo.SetIsActiveByID(e.ctx, secondReviewerID, false)
+	require.NoError(t, err)
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
+	require.NoError(t, err)
+	require.Equal(t, []domain.UserID{firstReviewerID}, pr.AssignedReviewers)
+	_, err = e.userRepo.SetIsActiveByID(e.ctx, secondReviewerID, true)
+	require.NoError(t, err)
+
+	ctx := domain.WithActor(e.ctx, "lead@example.com")
+	_, newReviewerID, err := e.prService.ReassignReviewer(ctx, pr.ID, firstReviewerID)
+	require.NoError(t, err)
+	require.Equal(t, secondReviewerID, newReviewerID)
+
+	_, err = e.prService.SubmitReview(e.ctx, pr.ID, secondReviewerID, domain.ReviewApproved)
+	require.NoError(t, err)
+	_, err = e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+	_, err = e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	events, err := e.prService.Timeline(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.PREventKind{
+		domain.EventCreated,
+		domain.EventReviewerAssigned,
+		domain.EventReviewerReassigned,
+		domain.EventReviewSubmitted,
+		domain.EventMerged,
+	}, timelineKinds(events))
+
+	reassigned := events[2]
+	assert.Equal(t, secondReviewerID, reassigned.UserID)
+	assert.Equal(t, firstReviewerID, reassigned.PreviousUserID)
+	assert.Equal(t, domain.ReassignManual, reassigned.Reason)
+	assert.Equal(t, "lead@example.com", reassigned.Actor)
+
+	assert.Equal(t, domain.ReviewApproved, events[3].ReviewState)
+	assert.Empty(t, events[3].Actor)
+}
+
+func TestTimelineRecordsWhyReviewerWasRemoved(t *testing.T) {
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
+	require.NoError(t, err)
+
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	_, err = e.teamService.RemoveMember(e.ctx, teamName, firstReviewerID)
+	require.NoError(t, err)
+
+	events, err := e.prService.Timeline(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	removed := events[len(events)-1]
+	assert.Equal(t, domain.EventReviewerRemoved, removed.Kind)
+	assert.Equal(t, firstReviewerID, removed.UserID)
+	assert.Equal(t, domain.ReassignLeftTeam, removed.Reason)
+
+	// Git hosts drop the reviewer too
+	notification := e.notifier.notifications[len(e.notifier.notifications)-1]
+	assert.Equal(t, domain.EventReviewerRemoved, notification.Kind)
+	assert.Equal(t, firstReviewerID, notification.PreviousReviewer)
+	assert.NotContains(t, notification.PullRequest.AssignedReviewers, firstReviewerID)
+}
+
+func TestTimelineOfUnknownPR(t *testing.T) {
+	e := setup()
+
+	_, err := e.prService.Timeline(e.ctx, "pr-not-exist")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestNotifiesAboutAssignmentsAndMerge(t *testing.T) {
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
+	require.NoError(t, err)
+
+	_, err = e.userRepo.SetIsActiveByID(e.ctx, secondReviewerID, false)
+	require.NoError(t, err)
+	pr, err := e.prService.CreatePR(e.ctx, "pr-1", "Test PR", authorID, "")
+	require.NoError(t, err)
+	_, err = e.userRepo.SetIsActiveByID(e.ctx, secondReviewerID, true)
+	require.NoError(t, err)
+
+	ctx := domain.WithActor(e.ctx, "lead@example.com")
+	_, _, err = e.prService.ReassignReviewer(ctx, pr.ID, firstReviewerID)
+	require.NoError(t, err)
+
+	_, err = e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+	_, err = e.prService.MergePR(e.ctx, pr.ID)
+	require.NoError(t, err)
+
+	require.Len(t, e.notifier.notifications, 3)
+
+	assigned := e.notifier.notifications[0]
+	assert.Equal(t, domain.EventReviewerAssigned, assigned.Kind)
+	assert.Equal(t, []domain.UserID{firstReviewerID}, assigned.Reviewers)
+
+	reassigned := e.notifier.notifications[1]
+	assert.Equal(t, domain.EventReviewerReassigned, reassigned.Kind)
+	assert.Equal(t, []domain.UserID{secondReviewerID}, reassigned.Reviewers)
+	assert.Equal(t, firstReviewerID, reassigned.PreviousReviewer)
+	assert.Equal(t, "lead@example.com", reassigned.Actor)
+
+	merged := e.notifier.notifications[2]
+	assert.Equal(t, domain.EventMerged, merged.Kind)
+	assert.Equal(t, domain.StatusMerged, merged.PullRequest.Status)
+}
+
+func TestNotifiesReviewerTakingOverReleasedReview(t *testing.T) {
+	e, pr := setupReassignTest(t)
+
+	_, err := e.teamService.RemoveMember(e.ctx, teamName, firstReviewerID)
+	require.NoError(t, err)
+
+	require.Len(t, e.notifier.notifications, 1)
+	reassigned := e.notifier.notifications[0]
+	assert.Equal(t, domain.EventReviewerReassigned, reassigned.Kind)
+	assert.Equal(t, pr.ID, reassigned.PullRequest.ID)
+	assert.Equal(t, []domain.UserID{secondReviewerID}, reassigned.Reviewers)
+	assert.Equal(t, domain.ReassignLeftTeam, reassigned.Reason)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+)
+
+// ReleasedReview describes what happened to an open review of a user who
+// left the reviewer pool. ReplacedBy is empty when nobody could take the
+// review over and the reviewer was simply removed from the pull request.
+type ReleasedReview struct {
+	PullRequestID domain.PullRequestID
+	ReplacedBy    domain.UserID
+}
+
+// reviewReleaser hands reviews over within a unit of work, the pull request
+// and the new reviewer stay locked until it ends.
+type reviewReleaser struct {
+	tx       repository.Tx
+	pool     reviewerPool
+	notifier Notifier
+}
+
+func newReviewReleaser(tx repository.Tx, n Notifier) reviewReleaser {
+	return reviewReleaser{
+		tx:       tx,
+		pool:     reviewerPool{teamRepo: tx.Teams(), userRepo: tx.Users()},
+		notifier: notifierOrNop(n),
+	}
+}
+
+// withinTx runs fn in a unit of work together with a releaser bound to it.
+// Notifications about the handed over reviews are sent to n once the unit of
+// work committed.
+func withinTx(ctx context.Context, uow repository.UnitOfWork, n Notifier, fn func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error) error {
+	held := &heldNotifications{}
+	err := uow.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+		return fn(ctx, tx, newReviewReleaser(tx, held))
+	})
+	if err != nil {
+		return err
+	}
+
+	held.sendTo(ctx, notifierOrNop(n))
+
+	return nil
+}
+
+// releaseOpenReviews hands every open review the user does for the team over
+// to another active member of that team or, failing that, of its parents.
+// Reviews the user does for other teams are left untouched.
+func (rr reviewReleaser) releaseOpenReviews(ctx context.Context, userID domain.UserID, teamName domain.TeamName, reason domain.ReassignReason) ([]ReleasedReview, error) {
+	return rr.release(ctx, userID, reason, func(review domain.PullRequestShort) (domain.TeamName, bool) {
+		if review.TeamName != "" && review.TeamName != teamName {
+			return "", false
+		}
+
+		return teamName, true
+	})
+}
+
+// releaseAllOpenReviews hands every open review of the user over, each one
+// within the team the pull request is reviewed by.
+func (rr reviewReleaser) releaseAllOpenReviews(ctx context.Context, user domain.User, reason domain.ReassignReason) ([]ReleasedReview, error) {
+	return rr.release(ctx, user.ID, reason, func(review domain.PullRequestShort) (domain.TeamName, bool) {
+		if review.TeamName == "" {
+			return user.TeamName, true
+		}
+
+		return review.TeamName, true
+	})
+}
+
+// release reassigns the open reviews of the user for which poolOf reports a
+// team to take them over, reviews without candidates are dropped.
+func (rr reviewReleaser) release(ctx context.Context, userID domain.UserID, reason domain.ReassignReason, poolOf func(domain.PullRequestShort) (domain.TeamName, bool)) ([]ReleasedReview, error) {
+	openReviews, err := rr.tx.PullRequests().PullRequestsByReviewer(ctx, userID, domain.PullRequestFilter{
+		Statuses: []domain.PRStatus{domain.StatusOpen},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	released := make([]ReleasedReview, 0, len(openReviews))
+	if len(openReviews) == 0 {
+		return released, nil
+	}
+
+	for _, review := range openReviews {
+		teamName, ok := poolOf(review)
+		if !ok {
+			continue
+		}
+
+		rel, err := rr.releaseReview(ctx, userID, review.ID, teamName, reason)
+		if errors.Is(err, domain.ErrNotAssigned) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		released = append(released, rel)
+	}
+
+	return released, nil
+}
+
+// releaseReview hands the review of the user on the pull request over to
+// another member of the team, or drops it when nobody can take it over.
+func (rr reviewReleaser) releaseReview(ctx context.Context, userID domain.UserID, prID domain.PullRequestID, teamName domain.TeamName, reason domain.ReassignReason) (ReleasedReview, error) {
+	pr, err := rr.tx.LockPullRequest(ctx, prID)
+	if err != nil {
+		return ReleasedReview{}, err
+	}
+
+	if !slices.Contains(pr.AssignedReviewers, userID) {
+		return ReleasedReview{}, domain.ErrNotAssigned
+	}
+
+	candidates, err := rr.pool.replacementCandidates(ctx, pr, teamName)
+	if err != nil {
+		return ReleasedReview{}, err
+	}
+
+	newReviewerID, err := lockCandidate(ctx, rr.tx, candidates)
+	if errors.Is(err, domain.ErrNoCandidate) {
+		removed, err := rr.tx.PullRequests().RemoveReviewer(ctx, pr.ID, userID, reason)
+		if err != nil {
+			return ReleasedReview{}, err
+		}
+
+		rr.notifier.Notify(ctx, Notification{
+			Kind:             domain.EventReviewerRemoved,
+			PullRequest:      removed,
+			PreviousReviewer: userID,
+			Reason:           reason,
+			Actor:            domain.ActorFromContext(ctx),
+		})
+
+		return ReleasedReview{PullRequestID: pr.ID}, nil
+	}
+	if err != nil {
+		return ReleasedReview{}, err
+	}
+
+	reassigned, _, err := rr.tx.PullRequests().ReassignReviewer(ctx, pr.ID, userID, newReviewerID, reason)
+	if err != nil {
+		return ReleasedReview{}, err
+	}
+
+	rr.notifier.Notify(ctx, Notification{
+		Kind:             domain.EventReviewerReassigned,
+		PullRequest:      reassigned,
+		Reviewers:        []domain.UserID{newReviewerID},
+		PreviousReviewer: userID,
+		Reason:           reason,
+		Actor:            domain.ActorFromContext(ctx),
+	})
+
+	return ReleasedReview{PullRequestID: pr.ID, ReplacedBy: newReviewerID}, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+)
+
+// reviewerPool looks for review candidates in a team and escalates to its
+// parent teams when the team itself has too few of them.
+type reviewerPool struct {
+	teamRepo repository.TeamRepository
+	userRepo repository.UserRepository
+}
+
+// candidateLevels returns active members who may review, grouped per team
+// from the given team up to the root of the hierarchy. Walking up stops as
+// soon as at least want candidates were found. Excluded users and users
+// already found on a lower level are skipped.
+func (p reviewerPool) candidateLevels(ctx context.Context, teamName domain.TeamName, excluded map[domain.UserID]struct{}, want int) ([][]domain.UserID, error) {
+	seen := make(map[domain.UserID]struct{}, len(excluded))
+	for id := range excluded {
+		seen[id] = struct{}{}
+	}
+
+	levels := [][]domain.UserID{}
+	visited := map[domain.TeamName]struct{}{}
+	found := 0
+
+	for teamName != "" && found < want {
+		if _, ok := visited[teamName]; ok {
+			break
+		}
+		visited[teamName] = struct{}{}
+
+		activeMembers, err := p.userRepo.ActiveUsersByTeamName(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		level := []domain.UserID{}
+		for _, member := range activeMembers {
+			if _, ok := seen[member.ID]; ok {
+				continue
+			}
+			seen[member.ID] = struct{}{}
+			level = append(level, member.ID)
+		}
+
+		if len(level) > 0 {
+			levels = append(levels, level)
+			found += len(level)
+		}
+
+		team, err := p.teamRepo.TeamByName(ctx, teamName)
+		if errors.Is(err, domain.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		teamName = team.ParentName
+	}
+
+	return levels, nil
+}
+
+// replacementCandidates returns the closest candidates who may take over a
+// review on the pull request: neither its author nor one of its current
+// reviewers.
+func (p reviewerPool) replacementCandidates(ctx context.Context, pr domain.PullRequest, teamName domain.TeamName) ([]domain.UserID, error) {
+	excluded := make(map[domain.UserID]struct{}, len(pr.AssignedReviewers)+1)
+
+	excluded[pr.AuthorID] = struct{}{}
+	for _, reviewerID := range pr.AssignedReviewers {
+		excluded[reviewerID] = struct{}{}
+	}
+
+	levels, err := p.candidateLevels(ctx, teamName, excluded, 1)
+	if err != nil || len(levels) == 0 {
+		return nil, err
+	}
+
+	return levels[0], nil
+}
+
+// lockCandidate locks the candidates and picks one of those still active
+// once the locks are held.
+func lockCandidate(ctx context.Context, tx repository.Tx, candidates []domain.UserID) (domain.UserID, error) {
+	if len(candidates) == 0 {
+		return domain.UserID(""), domain.ErrNoCandidate
+	}
+
+	users, err := tx.LockUsers(ctx, candidates)
+	if err != nil {
+		return domain.UserID(""), err
+	}
+
+	active := []domain.UserID{}
+	for _, user := range users {
+		if user.IsActive && !user.IsDeleted() {
+			active = append(active, user.ID)
+		}
+	}
+
+	if len(active) == 0 {
+		return domain.UserID(""), domain.ErrNoCandidate
+	}
+
+	return active[rand.Intn(len(active))], nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+	"time"
+)
+
+const (
+	// StateVersion is the version of the export format written by Export
+	// and accepted by Import.
+	StateVersion = 1
+
+	exportPageSize = 500
+)
+
+// State is a full copy of the teams, users and pull requests. Memberships
+// are carried by the users.
+type State struct {
+	Version      int
+	ExportedAt   time.Time
+	Teams        []domain.Team
+	Users        []domain.User
+	PullRequests []domain.PullRequest
+}
+
+// StateWriter receives an export piece by piece: all teams first, then all
+// users and all pull requests last.
+type StateWriter interface {
+	WriteTeam(team domain.Team) error
+	WriteUser(user domain.User) error
+	WritePullRequest(pr domain.PullRequest) error
+}
+
+type StateImport struct {
+	Teams        int
+	Users        int
+	PullRequests int
+}
+
+type StateService struct {
+	uow repository.UnitOfWork
+}
+
+func NewStateService(uow repository.UnitOfWork) *StateService {
+	return &StateService{
+		uow: uow,
+	}
+}
+
+// Export hands everything to the writer, users and pull requests are read
+// page by page so the whole state never has to fit in memory. All of it is
+// read from one snapshot, so the export is consistent.
+func (s *StateService) Export(ctx context.Context, w StateWriter) error {
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	return s.uow.WithinSnapshot(ctx, func(ctx context.Context, tx repository.Tx) error {
+		return export(ctx, tx, w)
+	})
+}
+
+func export(ctx context.Context, tx repository.Tx, w StateWriter) error {
+	teamNames, err := tx.Teams().TeamNames(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	for _, teamName := range teamNames {
+		team, err := tx.Teams().TeamByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		team.Members = nil
+		if err := w.WriteTeam(team); err != nil {
+			return err
+		}
+	}
+
+	userFilter := domain.UserFilter{IncludeDeleted: true, Limit: exportPageSize}
+	for {
+		users, err := tx.Users().List(ctx, userFilter)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := w.WriteUser(user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < exportPageSize {
+			break
+		}
+		userFilter.After = users[len(users)-1].ID
+	}
+
+	prFilter := domain.PullRequestFilter{Order: domain.SortOldestFirst, Limit: exportPageSize}
+	for {
+		prs, err := tx.PullRequests().List(ctx, prFilter)
+		if err != nil {
+			return err
+		}
+
+		for _, pr := range prs {
+			if err := w.WritePullRequest(pr); err != nil {
+				return err
+			}
+		}
+
+		if len(prs) < exportPageSize {
+			break
+		}
+		last := prs[len(prs)-1]
+		prFilter.After = &domain.PullRequestCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return nil
+}
+
+// Import restores an export into empty storage. Teams are created parents
+// first, then users with their memberships and pull requests last. Restored
+// pull requests do not notify webhook subscriptions. The import is a single
+// transaction, after an error nothing is kept and it can simply be retried.
+func (s *StateService) Import(ctx context.Context, state State) (StateImport, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return StateImport{}, err
+	}
+
+	if state.Version != StateVersion {
+		return StateImport{}, fmt.Errorf("%w: unsupported export version %d", domain.ErrInvalidArgument, state.Version)
+	}
+
+	teams, err := parentsFirst(state.Teams)
+	if err != nil {
+		return StateImport{}, err
+	}
+
+	err = s.uow.WithinTx(ctx, func(ctx context.Context, tx repository.Tx) error {
+		if err := ensureEmpty(ctx, tx); err != nil {
+			return err
+		}
+
+		for _, team := range teams {
+			team.Members = nil
+			team.SubTeams = nil
+			if err := tx.Teams().Create(ctx, team); err != nil {
+				return fmt.Errorf("team %s: %w", team.Name, err)
+			}
+		}
+
+		for _, user := range state.Users {
+			if err := tx.Users().Create(ctx, user); err != nil {
+				return fmt.Errorf("user %s: %w", user.ID, err)
+			}
+
+			for _, teamName := range user.Teams {
+				if teamName == user.TeamName {
+					continue
+				}
+				if _, err := tx.Users().AddMembership(ctx, user.ID, teamName); err != nil {
+					return fmt.Errorf("user %s: %w", user.ID, err)
+				}
+			}
+		}
+
+		for _, pr := range state.PullRequests {
+			if _, err := tx.PullRequests().Restore(ctx, pr); err != nil {
+				return fmt.Errorf("pull request %s: %w", pr.ID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return StateImport{}, err
+	}
+
+	return StateImport{
+		Teams:        len(state.Teams),
+		Users:        len(state.Users),
+		PullRequests: len(state.PullRequests),
+	}, nil
+}
+
+func ensureEmpty(ctx context.Context, tx repository.Tx) error {
+	teamNames, err := tx.Teams().TeamNames(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	users, err := tx.Users().List(ctx, domain.UserFilter{IncludeDeleted: true, Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	prs, err := tx.PullRequests().List(ctx, domain.PullRequestFilter{Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	if len(teamNames) > 0 || len(users) > 0 || len(prs) > 0 {
+		return domain.ErrStorageNotEmpty
+	}
+
+	return nil
+}
+
+// parentsFirst orders the teams so that every parent comes before its
+// sub-teams. Unknown parents and cycles are rejected.
+func parentsFirst(teams []domain.Team) ([]domain.Team, error) {
+	byName := make(map[domain.TeamName]domain.Team, len(teams))
+	for _, team := range teams {
+		if _, ok := byName[team.Name]; ok {
+			return nil, fmt.Errorf("%w: team %s is listed twice", domain.ErrInvalidArgument, team.Name)
+		}
+		byName[team.Name] = team
+	}
+
+	ordered := make([]domain.Team, 0, len(teams))
+	done := make(map[domain.TeamName]struct{}, len(teams))
+
+	for _, team := range teams {
+		chain := []domain.Team{}
+		for name := team.Name; name != ""; name = byName[name].ParentName {
+			if _, ok := done[name]; ok {
+				break
+			}
+
+			current, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: parent %s of team %s is missing", domain.ErrInvalidArgument, name, team.Name)
+			}
+			if slices.ContainsFunc(chain, func(t domain.Team) bool { return t.Name == name }) {
+				return nil, fmt.Errorf("%w: team %s is part of a parent cycle", domain.ErrInvalidArgument, name)
+			}
+			chain = append(chain, current)
+		}
+
+		for i := len(chain) - 1; i >= 0; i-- {
+			done[chain[i].Name] = struct{}{}
+			ordered = append(ordered, chain[i])
+		}
+	}
+
+	return ordered, nil
+}
@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collectingStateWriter struct {
+	state service.State
+}
+
+func (w *collectingStateWriter) WriteTeam(team domain.Team) error {
+	w.state.Teams = append(w.state.Teams, team)
+	return nil
+}
+
+func (w *collectingStateWriter) WriteUser(user domain.User) error {
+	w.state.Users = append(w.state.Users, user)
+	return nil
+}
+
+func (w *collectingStateWriter) WritePullRequest(pr domain.PullRequest) error {
+	w.state.PullRequests = append(w.state.PullRequests, pr)
+	return nil
+}
+
+type testStateEnviroment struct {
+	ctx     context.Context
+	storage *inmemory.InMemoryStorage
+
+	teamRepo *inmemory.TeamRepo
+	userRepo *inmemory.UserRepo
+	prRepo   *inmemory.PullRequestRepo
+
+	stateService *service.StateService
+}
+
+func setupStateTest() testStateEnviroment {
+	storage, _ := inmemory.NewStorage()
+
+	teamRepo := inmemory.NewTeamRepo(storage)
+	userRepo := inmemory.NewUserRepo(storage)
+	prRepo := inmemory.NewPullRequestRepo(storage)
+
+	return testStateEnviroment{
+		ctx:          context.Background(),
+		storage:      storage,
+		teamRepo:     teamRepo,
+		userRepo:     userRepo,
+		prRepo:       prRepo,
+		stateService: service.NewStateService(inmemory.NewUnitOfWork(storage)),
+	}
+}
+
+func (e testStateEnviroment) export(t *testing.T) service.State {
+	t.Helper()
+
+	w := &collectingStateWriter{state: service.State{Version: service.StateVersion}}
+	require.NoError(t, e.stateService.Export(e.ctx, w))
+
+	return w.state
+}
+
+func TestStateExportImportRoundTrip(t *testing.T) {
+	source := setupStateTest()
+
+	archivedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	deletedAt := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	mergedAt := createdAt.Add(time.Hour)
+
+	// sub-team first, so that import has to reorder the teams
+	require.NoError(t, source.teamRepo.Create(source.ctx, domain.Team{Name: "platform"}))
+	require.NoError(t, source.teamRepo.Create(source.ctx, domain.Team{Name: "backend", ParentName: "platform"}))
+	require.NoError(t, source.teamRepo.Create(source.ctx, domain.Team{Name: "legacy", ArchivedAt: &archivedAt}))
+
+	require.NoError(t, source.userRepo.Create(source.ctx, domain.User{ID: "u1", Username: "Alice", FullName: "Alice Liddell", Role: domain.RoleLead, TeamName: "backend", IsActive: true}))
+	_, err := source.userRepo.AddMembership(source.ctx, "u1", "platform")
+	require.NoError(t, err)
+	require.NoError(t, source.userRepo.Create(source.ctx, domain.User{ID: "u2", Username: "Bob", TeamName: "backend", IsActive: true}))
+	require.NoError(t, source.userRepo.Create(source.ctx, domain.User{ID: "u3", Username: "Carol", IsActive: false, DeletedAt: &deletedAt}))
+
+	_, err = source.prRepo.Create(source.ctx, domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Add search",
+		AuthorID:          "u1",
+		TeamName:          "backend",
+		Status:            domain.StatusMerged,
+		AssignedReviewers: []domain.UserID{"u2"},
+		ReviewStates:      map[domain.UserID]domain.ReviewState{"u2": domain.ReviewApproved},
+		CreatedAt:         createdAt,
+		MergedAt:          &mergedAt,
+	})
+	require.NoError(t, err)
+	_, err = source.prRepo.Create(source.ctx, domain.PullRequest{
+		ID:                "pr-2",
+		Name:              "Fix typo",
+		AuthorID:          "u2",
+		TeamName:          "backend",
+		Status:            domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{"u1"},
+		ReviewStates:      map[domain.UserID]domain.ReviewState{},
+		CreatedAt:         createdAt.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	exported := source.export(t)
+	assert.Len(t, exported.Teams, 3)
+	assert.Len(t, exported.Users, 3)
+	assert.Len(t, exported.PullRequests, 2)
+
+	target := setupStateTest()
+	imported, err := target.stateService.Import(target.ctx, exported)
+	require.NoError(t, err)
+	assert.Equal(t, service.StateImport{Teams: 3, Users: 3, PullRequests: 2}, imported)
+
+	assert.Equal(t, source.storage.Teams, target.storage.Teams)
+	assert.Equal(t, source.storage.Users, target.storage.Users)
+	assert.Equal(t, source.storage.PRs, target.storage.PRs)
+	assert.Equal(t, exported, target.export(t))
+
+	timeline, err := target.prRepo.Timeline(target.ctx, "pr-1")
+	require.NoError(t, err)
+	require.Len(t, timeline, 3)
+	assert.Equal(t, domain.EventMerged, timeline[2].Kind)
+	assert.Equal(t, mergedAt, timeline[2].CreatedAt)
+}
+
+func TestStateImportRejectsNonEmptyStorage(t *testing.T) {
+	e := setupStateTest()
+	require.NoError(t, e.teamRepo.Create(e.ctx, domain.Team{Name: "backend"}))
+
+	_, err := e.stateService.Import(e.ctx, service.State{
+		Version: service.StateVersion,
+		Teams:   []domain.Team{{Name: "frontend"}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrStorageNotEmpty)
+	assert.NotContains(t, e.storage.Teams, domain.TeamName("frontend"))
+}
+
+func TestStateImportKeepsNothingOnFailureAndCanBeRetried(t *testing.T) {
+	e := setupStateTest()
+	state := service.State{
+		Version: service.StateVersion,
+		Teams:   []domain.Team{{Name: "backend"}},
+		Users: []domain.User{
+			{ID: "u-1", Username: "alice", Role: domain.RoleMember, TeamName: "backend", Teams: []domain.TeamName{"backend", "unknown"}, IsActive: true},
+		},
+	}
+
+	_, err := e.stateService.Import(e.ctx, state)
+
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Empty(t, e.storage.Teams)
+	assert.Empty(t, e.storage.Users)
+
+	state.Users[0].Teams = []domain.TeamName{"backend"}
+	imported, err := e.stateService.Import(e.ctx, state)
+
+	require.NoError(t, err)
+	assert.Equal(t, service.StateImport{Teams: 1, Users: 1}, imported)
+}
+
+func TestStateImportDoesNotNotifyWebhooks(t *testing.T) {
+	e := setupStateTest()
+	e.storage.Webhooks[1] = domain.WebhookSubscription{
+		ID: 1, URL: "https://example.com/hook",
+		EventTypes: []domain.PREventKind{domain.EventCreated, domain.EventReviewerAssigned},
+	}
+
+	_, err := e.stateService.Import(e.ctx, service.State{
+		Version: service.StateVersion,
+		Teams:   []domain.Team{{Name: "backend"}},
+		Users: []domain.User{
+			{ID: "u-1", Username: "alice", Role: domain.RoleMember, TeamName: "backend", Teams: []domain.TeamName{"backend"}, IsActive: true},
+			{ID: "u-2", Username: "bob", Role: domain.RoleMember, TeamName: "backend", Teams: []domain.TeamName{"backend"}, IsActive: true},
+		},
+		PullRequests: []domain.PullRequest{
+			{ID: "pr-1", Name: "PR 1", AuthorID: "u-1", TeamName: "backend", Status: domain.StatusOpen, AssignedReviewers: []domain.UserID{"u-2"}},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, e.storage.WebhookDeliveries)
+
+	timeline, err := e.prRepo.Timeline(e.ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Len(t, timeline, 2)
+}
+
+func TestStateImportRejectsUnknownVersion(t *testing.T) {
+	e := setupStateTest()
+
+	_, err := e.stateService.Import(e.ctx, service.State{Version: service.StateVersion + 1})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestStateImportRejectsMissingParentTeam(t *testing.T) {
+	e := setupStateTest()
+
+	_, err := e.stateService.Import(e.ctx, service.State{
+		Version: service.StateVersion,
+		Teams:   []domain.Team{{Name: "backend", ParentName: "platform"}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+	assert.Empty(t, e.storage.Teams)
+}
@@ -2,26 +2,636 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/repository"
+	"slices"
 )
 
 type TeamService struct {
 	teamRepo repository.TeamRepository
 	userRepo repository.UserRepository
+	uow      repository.UnitOfWork
+	notifier Notifier
 }
 
-func NewTeamService(tr repository.TeamRepository, ur repository.UserRepository) *TeamService {
+type TeamCreation struct {
+	MovedMembers    []domain.MemberConflict
+	ReleasedReviews []ReleasedReview
+}
+
+type TeamArchival struct {
+	Team               domain.Team
+	MovedMembers       []domain.UserID
+	DeactivatedMembers []domain.UserID
+	ReleasedReviews    []ReleasedReview
+}
+
+type TeamChangeKind string
+
+const (
+	TeamChangeAddMembers    TeamChangeKind = "ADD_MEMBERS"
+	TeamChangeRemoveMembers TeamChangeKind = "REMOVE_MEMBERS"
+	TeamChangeSetMembers    TeamChangeKind = "SET_MEMBERS"
+)
+
+// TeamChange is one step of ChangeTeam, Members are users that exist
+// already.
+type TeamChange struct {
+	Kind    TeamChangeKind
+	Members []domain.UserID
+}
+
+type MemberMove struct {
+	User            domain.User
+	ReleasedReviews []ReleasedReview
+}
+
+func NewTeamService(tr repository.TeamRepository, ur repository.UserRepository, uow repository.UnitOfWork, n Notifier) *TeamService {
 	return &TeamService{
 		teamRepo: tr,
 		userRepo: ur,
+		uow:      uow,
+		notifier: n,
 	}
 }
 
-func (s *TeamService) CreateTeam(ctx context.Context, team domain.Team) error {
-	return s.teamRepo.Create(ctx, team)
+// CreateTeam creates the team with its members. Members who already belong
+// to another team are rejected unless moveExisting is set, in which case they
+// are moved and their open reviews stay within their previous team.
+func (s *TeamService) CreateTeam(ctx context.Context, team domain.Team, moveExisting bool) (TeamCreation, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return TeamCreation{}, err
+	}
+
+	creation := TeamCreation{
+		MovedMembers:    []domain.MemberConflict{},
+		ReleasedReviews: []ReleasedReview{},
+	}
+
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		if _, err := tx.Teams().TeamByName(ctx, team.Name); err == nil {
+			return domain.ErrTeamExists
+		} else if !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+
+		if team.ParentName != "" {
+			if err := ensureActiveTeam(ctx, tx.Teams(), team.ParentName); err != nil {
+				return err
+			}
+		}
+
+		memberIDs := make([]domain.UserID, len(team.Members))
+		for i, member := range team.Members {
+			memberIDs[i] = member.UserID
+		}
+
+		// members stay locked until the team is created, so nobody can move
+		// them into another team in between
+		users, err := tx.LockUsersForUpdate(ctx, memberIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if user.TeamName != "" && user.TeamName != team.Name {
+				creation.MovedMembers = append(creation.MovedMembers, domain.MemberConflict{UserID: user.ID, TeamName: user.TeamName})
+			}
+		}
+
+		if len(creation.MovedMembers) > 0 && !moveExisting {
+			return &domain.TeamConflictError{Conflicts: creation.MovedMembers}
+		}
+
+		if err := tx.Teams().Create(ctx, team); err != nil {
+			return err
+		}
+
+		for _, conflict := range creation.MovedMembers {
+			released, err := rr.releaseOpenReviews(ctx, conflict.UserID, conflict.TeamName, domain.ReassignLeftTeam)
+			if err != nil {
+				return err
+			}
+
+			creation.ReleasedReviews = append(creation.ReleasedReviews, released...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return TeamCreation{}, err
+	}
+
+	return creation, nil
 }
 
 func (s *TeamService) Team(ctx context.Context, teamName domain.TeamName) (domain.Team, error) {
 	return s.teamRepo.TeamByName(ctx, teamName)
 }
+
+// ActiveTeams returns all teams that are not archived, ordered by name.
+func (s *TeamService) ActiveTeams(ctx context.Context) ([]domain.Team, error) {
+	names, err := s.teamRepo.TeamNames(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]domain.Team, 0, len(names))
+	for _, name := range names {
+		team, err := s.teamRepo.TeamByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, nil
+}
+
+// TeamWithDescendants returns the team with its sub-teams filled in
+// recursively.
+func (s *TeamService) TeamWithDescendants(ctx context.Context, teamName domain.TeamName) (domain.Team, error) {
+	return s.teamTree(ctx, teamName, map[domain.TeamName]struct{}{})
+}
+
+func (s *TeamService) teamTree(ctx context.Context, teamName domain.TeamName, visited map[domain.TeamName]struct{}) (domain.Team, error) {
+	team, err := s.teamRepo.TeamByName(ctx, teamName)
+	if err != nil {
+		return domain.Team{}, err
+	}
+	visited[teamName] = struct{}{}
+
+	subTeamNames, err := s.teamRepo.SubTeamNames(ctx, teamName)
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	team.SubTeams = make([]domain.Team, 0, len(subTeamNames))
+	for _, name := range subTeamNames {
+		if _, ok := visited[name]; ok {
+			continue
+		}
+
+		subTeam, err := s.teamTree(ctx, name, visited)
+		if err != nil {
+			return domain.Team{}, err
+		}
+
+		team.SubTeams = append(team.SubTeams, subTeam)
+	}
+
+	return team, nil
+}
+
+// SetParent places the team under another one, an empty parent name makes it
+// a top-level team. A team can not become a descendant of itself.
+func (s *TeamService) SetParent(ctx context.Context, teamName domain.TeamName, parentName domain.TeamName) (domain.Team, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.Team{}, err
+	}
+
+	if _, err := s.teamRepo.TeamByName(ctx, teamName); err != nil {
+		return domain.Team{}, err
+	}
+
+	if parentName != "" {
+		if err := ensureActiveTeam(ctx, s.teamRepo, parentName); err != nil {
+			return domain.Team{}, err
+		}
+
+		for ancestor := parentName; ancestor != ""; {
+			if ancestor == teamName {
+				return domain.Team{}, fmt.Errorf("%w: %s can not be placed under its own descendant %s", domain.ErrInvalidArgument, teamName, parentName)
+			}
+
+			team, err := s.teamRepo.TeamByName(ctx, ancestor)
+			if err != nil {
+				return domain.Team{}, err
+			}
+			ancestor = team.ParentName
+		}
+	}
+
+	if err := s.teamRepo.SetParent(ctx, teamName, parentName); err != nil {
+		return domain.Team{}, err
+	}
+
+	return s.teamRepo.TeamByName(ctx, teamName)
+}
+
+func (s *TeamService) AddMember(ctx context.Context, teamName domain.TeamName, member domain.TeamMember) (domain.Team, error) {
+	if err := authorizeTeamLead(ctx, s.userRepo, teamName); err != nil {
+		return domain.Team{}, err
+	}
+
+	if member.UserID == "" {
+		return domain.Team{}, fmt.Errorf("%w: user_id is required", domain.ErrInvalidArgument)
+	}
+
+	if err := ensureActiveTeam(ctx, s.teamRepo, teamName); err != nil {
+		return domain.Team{}, err
+	}
+
+	existing, err := s.userRepo.UserByID(ctx, member.UserID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.Team{}, err
+	}
+
+	// Members of other teams join this one as well and keep their home team.
+	if err == nil && existing.TeamName != "" && existing.TeamName != teamName {
+		if _, err := s.userRepo.AddMembership(ctx, existing.ID, teamName); err != nil {
+			return domain.Team{}, err
+		}
+
+		return s.teamRepo.TeamByName(ctx, teamName)
+	}
+
+	user := domain.User{
+		ID:       member.UserID,
+		Username: member.Username,
+		TeamName: teamName,
+		IsActive: member.IsActive,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return domain.Team{}, err
+	}
+
+	return s.teamRepo.TeamByName(ctx, teamName)
+}
+
+// RemoveMember detaches the user from the team and hands their open reviews
+// over to the remaining team members. The user itself is kept for history.
+func (s *TeamService) RemoveMember(ctx context.Context, teamName domain.TeamName, userID domain.UserID) ([]ReleasedReview, error) {
+	if err := authorizeTeamLead(ctx, s.userRepo, teamName); err != nil {
+		return nil, err
+	}
+
+	var released []ReleasedReview
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		var err error
+		released, err = removeMember(ctx, tx, rr, teamName, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return released, nil
+}
+
+// MoveMember moves the user into another team. Open reviews the user got
+// from the previous team stay within that team.
+func (s *TeamService) MoveMember(ctx context.Context, userID domain.UserID, teamName domain.TeamName) (MemberMove, error) {
+	if err := authorizeTeamLead(ctx, s.userRepo, teamName); err != nil {
+		return MemberMove{}, err
+	}
+
+	var move MemberMove
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		// the user stays locked until moved, so the previous team can't change
+		if _, err := tx.LockUsersForUpdate(ctx, []domain.UserID{userID}); err != nil {
+			return err
+		}
+
+		user, err := tx.Users().UserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// a user without a team only joins one, otherwise the previous team
+		// must be led as well
+		if user.TeamName != "" {
+			if err := authorizeTeamLead(ctx, tx.Users(), user.TeamName); err != nil {
+				return err
+			}
+		}
+
+		if user.TeamName == teamName {
+			move = MemberMove{User: user, ReleasedReviews: []ReleasedReview{}}
+			return nil
+		}
+
+		if err := ensureActiveTeam(ctx, tx.Teams(), teamName); err != nil {
+			return err
+		}
+
+		if move.User, err = tx.Users().SetTeamByID(ctx, userID, teamName); err != nil {
+			return err
+		}
+
+		move.ReleasedReviews = []ReleasedReview{}
+		if user.TeamName != "" {
+			move.ReleasedReviews, err = rr.releaseOpenReviews(ctx, userID, user.TeamName, domain.ReassignLeftTeam)
+		}
+		return err
+	})
+	if err != nil {
+		return MemberMove{}, err
+	}
+
+	return move, nil
+}
+
+func (s *TeamService) RenameTeam(ctx context.Context, teamName domain.TeamName, newTeamName domain.TeamName) (domain.Team, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.Team{}, err
+	}
+
+	if newTeamName == "" {
+		return domain.Team{}, fmt.Errorf("%w: new team name is required", domain.ErrInvalidArgument)
+	}
+
+	if teamName == newTeamName {
+		return s.teamRepo.TeamByName(ctx, teamName)
+	}
+
+	if err := s.teamRepo.Rename(ctx, teamName, newTeamName); err != nil {
+		return domain.Team{}, err
+	}
+
+	return s.teamRepo.TeamByName(ctx, newTeamName)
+}
+
+// ArchiveTeam stops the team from receiving assignments while keeping its
+// pull request history. The open reviews of the members for the team are
+// released. Members are moved to moveTo when it is set, otherwise members
+// without another team are deactivated.
+func (s *TeamService) ArchiveTeam(ctx context.Context, teamName domain.TeamName, moveTo domain.TeamName) (TeamArchival, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return TeamArchival{}, err
+	}
+
+	if moveTo != "" && moveTo == teamName {
+		return TeamArchival{}, fmt.Errorf("%w: cannot move members into the archived team", domain.ErrInvalidArgument)
+	}
+
+	archival := TeamArchival{
+		MovedMembers:       []domain.UserID{},
+		DeactivatedMembers: []domain.UserID{},
+		ReleasedReviews:    []ReleasedReview{},
+	}
+
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		team, err := tx.Teams().TeamByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		archival.Team = team
+		if team.IsArchived() {
+			return nil
+		}
+
+		if moveTo != "" {
+			if err := ensureActiveTeam(ctx, tx.Teams(), moveTo); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Teams().Archive(ctx, teamName); err != nil {
+			return err
+		}
+
+		// members stay locked until they left the team, so nobody can move
+		// them elsewhere in between
+		memberIDs := make([]domain.UserID, len(team.Members))
+		for i, member := range team.Members {
+			memberIDs[i] = member.UserID
+		}
+		if _, err := tx.LockUsersForUpdate(ctx, memberIDs); err != nil {
+			return err
+		}
+
+		for _, member := range team.Members {
+			user, err := tx.Users().UserByID(ctx, member.UserID)
+			if err != nil {
+				return err
+			}
+
+			if moveTo != "" {
+				if err := moveMembership(ctx, tx.Users(), user, teamName, moveTo); err != nil {
+					return err
+				}
+
+				archival.MovedMembers = append(archival.MovedMembers, member.UserID)
+			} else if member.IsActive && len(user.Teams) <= 1 {
+				if _, err := tx.Users().SetIsActiveByID(ctx, member.UserID, false); err != nil {
+					return err
+				}
+
+				archival.DeactivatedMembers = append(archival.DeactivatedMembers, member.UserID)
+			}
+
+			// moved members don't take the reviews of the archived team along
+			released, err := rr.releaseOpenReviews(ctx, member.UserID, teamName, domain.ReassignTeamArchived)
+			if err != nil {
+				return err
+			}
+
+			archival.ReleasedReviews = append(archival.ReleasedReviews, released...)
+		}
+
+		archival.Team, err = tx.Teams().TeamByName(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		return TeamArchival{}, err
+	}
+
+	return archival, nil
+}
+
+// CreateTeamOfUsers creates the team with existing users as members, they
+// join it in addition to their home team.
+func (s *TeamService) CreateTeamOfUsers(ctx context.Context, teamName domain.TeamName, userIDs []domain.UserID) (domain.Team, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.Team{}, err
+	}
+
+	if teamName == "" {
+		return domain.Team{}, fmt.Errorf("%w: team name is required", domain.ErrInvalidArgument)
+	}
+
+	var team domain.Team
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		if _, err := tx.Teams().TeamByName(ctx, teamName); err == nil {
+			return domain.ErrTeamExists
+		} else if !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+
+		if err := tx.Teams().Create(ctx, domain.Team{Name: teamName}); err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			if err := addExistingMember(ctx, tx, teamName, userID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		team, err = tx.Teams().TeamByName(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	return team, nil
+}
+
+// ChangeTeam applies the changes to an active team in order, either all of
+// them or none. Removed members hand their open reviews for the team over.
+func (s *TeamService) ChangeTeam(ctx context.Context, teamName domain.TeamName, changes []TeamChange) (domain.Team, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.Team{}, err
+	}
+
+	var team domain.Team
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		if err := ensureActiveTeam(ctx, tx.Teams(), teamName); err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if err := applyTeamChange(ctx, tx, rr, teamName, change); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		team, err = tx.Teams().TeamByName(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	return team, nil
+}
+
+func applyTeamChange(ctx context.Context, tx repository.Tx, rr reviewReleaser, teamName domain.TeamName, change TeamChange) error {
+	switch change.Kind {
+	case TeamChangeAddMembers:
+		for _, userID := range change.Members {
+			if err := addExistingMember(ctx, tx, teamName, userID); err != nil {
+				return err
+			}
+		}
+
+	case TeamChangeRemoveMembers:
+		for _, userID := range change.Members {
+			if _, err := removeMember(ctx, tx, rr, teamName, userID); err != nil {
+				return err
+			}
+		}
+
+	case TeamChangeSetMembers:
+		team, err := tx.Teams().TeamByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range change.Members {
+			if err := addExistingMember(ctx, tx, teamName, userID); err != nil {
+				return err
+			}
+		}
+
+		for _, member := range team.Members {
+			if slices.Contains(change.Members, member.UserID) {
+				continue
+			}
+			if _, err := removeMember(ctx, tx, rr, teamName, member.UserID); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown team change %q", domain.ErrInvalidArgument, change.Kind)
+	}
+
+	return nil
+}
+
+// DeleteTeam removes a team for good. Only teams without members can be
+// deleted, teams with history should be archived instead.
+func (s *TeamService) DeleteTeam(ctx context.Context, teamName domain.TeamName) error {
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	return s.teamRepo.Delete(ctx, teamName)
+}
+
+// addExistingMember adds the user to the team keeping its profile as it is.
+// The team becomes the home team of users without one.
+func addExistingMember(ctx context.Context, tx repository.Tx, teamName domain.TeamName, userID domain.UserID) error {
+	user, err := tx.Users().UserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsMemberOf(teamName) {
+		return nil
+	}
+
+	if user.TeamName == "" {
+		_, err = tx.Users().SetTeamByID(ctx, userID, teamName)
+		return err
+	}
+
+	_, err = tx.Users().AddMembership(ctx, userID, teamName)
+	return err
+}
+
+// removeMember detaches the user from the team and hands their open reviews
+// for it over.
+func removeMember(ctx context.Context, tx repository.Tx, rr reviewReleaser, teamName domain.TeamName, userID domain.UserID) ([]ReleasedReview, error) {
+	user, err := tx.Users().UserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsMemberOf(teamName) {
+		return nil, fmt.Errorf("%w: %s is not a member of %s", domain.ErrNotFound, userID, teamName)
+	}
+
+	if _, err := tx.Users().RemoveMembership(ctx, userID, teamName); err != nil {
+		return nil, err
+	}
+
+	return rr.releaseOpenReviews(ctx, userID, teamName, domain.ReassignLeftTeam)
+}
+
+// moveMembership replaces the membership of the user in one team with a
+// membership in another, the home team follows when it is the one replaced.
+func moveMembership(ctx context.Context, userRepo repository.UserRepository, user domain.User, from domain.TeamName, to domain.TeamName) error {
+	if user.TeamName == from {
+		_, err := userRepo.SetTeamByID(ctx, user.ID, to)
+		return err
+	}
+
+	if _, err := userRepo.RemoveMembership(ctx, user.ID, from); err != nil {
+		return err
+	}
+
+	_, err := userRepo.AddMembership(ctx, user.ID, to)
+	return err
+}
+
+func ensureActiveTeam(ctx context.Context, teamRepo repository.TeamRepository, teamName domain.TeamName) error {
+	team, err := teamRepo.TeamByName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+
+	if team.IsArchived() {
+		return fmt.Errorf("%w: %s", domain.ErrTeamArchived, teamName)
+	}
+
+	return nil
+}
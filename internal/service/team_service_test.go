@@ -18,6 +18,7 @@ type testTeamEnviroment struct {
 
 	userRepo repository.UserRepository
 	teamRepo repository.TeamRepository
+	prRepo   repository.PullRequestRepository
 
 	teamService *service.TeamService
 }
@@ -27,14 +28,16 @@ func setupTeamTest() testTeamEnviroment {
 
 	userRepo := inmemory.NewUserRepo(storage)
 	teamRepo := inmemory.NewTeamRepo(storage)
+	prRepo := inmemory.NewPullRequestRepo(storage)
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
+	teamService := service.NewTeamService(teamRepo, userRepo, inmemory.NewUnitOfWork(storage), nil)
 
 	return testTeamEnviroment{
 		ctx:         context.Background(),
 		storage:     storage,
 		userRepo:    userRepo,
 		teamRepo:    teamRepo,
+		prRepo:      prRepo,
 		teamService: teamService,
 	}
 }
@@ -56,7 +59,7 @@ var (
 func TestSuccessCreateTeam(t *testing.T) {
 	e := setupTeamTest()
 
-	err := e.teamService.CreateTeam(e.ctx, teamPlatform)
+	_, err := e.teamService.CreateTeam(e.ctx, teamPlatform, false)
 	require.NoError(t, err)
 
 	dbTeam, exists := e.storage.Teams[teamPlatformName]
@@ -78,15 +81,15 @@ func TestSuccessCreateTeam(t *testing.T) {
 
 func TestFailOnAlreadyExistingTeam(t *testing.T) {
 	e := setupTeamTest()
-	err1 := e.teamService.CreateTeam(e.ctx, teamPlatform)
+	_, err1 := e.teamService.CreateTeam(e.ctx, teamPlatform, false)
 	require.NoError(t, err1)
 
-	err2 := e.teamService.CreateTeam(e.ctx, teamPlatform)
+	_, err2 := e.teamService.CreateTeam(e.ctx, teamPlatform, false)
 	require.Error(t, err2)
 	assert.ErrorIs(t, err2, domain.ErrTeamExists)
 }
 
-func TestCreateTeamUpdatedUsers(t *testing.T) {
+func TestCreateTeamFailsOnMembersOfOtherTeam(t *testing.T) {
 	e := setupTeamTest()
 
 	frontendTeam := domain.Team{
@@ -95,15 +98,47 @@ func TestCreateTeamUpdatedUsers(t *testing.T) {
 			{UserID: firstUserID, Username: "First-Frontend", IsActive: false},
 		},
 	}
-	err := e.teamService.CreateTeam(e.ctx, frontendTeam)
+	_, err := e.teamService.CreateTeam(e.ctx, frontendTeam, false)
 	require.NoError(t, err)
 
-	userFirst := e.storage.Users[firstUserID]
-	require.Equal(t, domain.TeamName("frontend"), userFirst.TeamName)
-	require.False(t, userFirst.IsActive)
+	_, err = e.teamService.CreateTeam(e.ctx, teamPlatform, false)
 
-	err = e.teamService.CreateTeam(e.ctx, teamPlatform)
+	var conflictErr *domain.TeamConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.ErrorIs(t, err, domain.ErrUserInOtherTeam)
+	assert.Equal(t, []domain.MemberConflict{{UserID: firstUserID, TeamName: "frontend"}}, conflictErr.Conflicts)
+
+	_, exists := e.storage.Teams[teamPlatformName]
+	assert.False(t, exists)
+	assert.Equal(t, domain.TeamName("frontend"), e.storage.Users[firstUserID].TeamName)
+	_, exists = e.storage.Users[secondUserID]
+	assert.False(t, exists)
+}
+
+func TestCreateTeamMovesExistingMembers(t *testing.T) {
+	e := setupTeamTest()
+
+	frontendTeam := domain.Team{
+		Name: "frontend",
+		Members: []domain.TeamMember{
+			{UserID: firstUserID, Username: "First-Frontend", IsActive: false},
+			{UserID: thirdUserID, Username: "Third", IsActive: true},
+			{UserID: fourthUserID, Username: "Fourth", IsActive: true},
+		},
+	}
+	_, err := e.teamService.CreateTeam(e.ctx, frontendTeam, false)
+	require.NoError(t, err)
+
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: thirdUserID, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{firstUserID},
+	}
+
+	creation, err := e.teamService.CreateTeam(e.ctx, teamPlatform, true)
 	require.NoError(t, err)
+	assert.Equal(t, []domain.MemberConflict{{UserID: firstUserID, TeamName: "frontend"}}, creation.MovedMembers)
+	require.Len(t, creation.ReleasedReviews, 1)
+	assert.Equal(t, fourthUserID, creation.ReleasedReviews[0].ReplacedBy)
 
 	userFirstUpdated := e.storage.Users[firstUserID]
 	assert.Equal(t, teamPlatformName, userFirstUpdated.TeamName)
@@ -114,9 +149,28 @@ func TestCreateTeamUpdatedUsers(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestCreateTeamRollsBackWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: fourthUserID, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	_, err := e.failingTeamService().CreateTeam(e.ctx, domain.Team{
+		Name:    "frontend",
+		Members: []domain.TeamMember{{UserID: thirdUserID, Username: "Third", IsActive: true}},
+	}, true)
+
+	require.ErrorIs(t, err, errReviewerChange)
+	_, exists := e.storage.Teams["frontend"]
+	assert.False(t, exists)
+	assert.Equal(t, teamBackendName, e.storage.Users[thirdUserID].TeamName)
+	assert.Equal(t, []domain.UserID{thirdUserID}, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
 func TestGetTeamSuccess(t *testing.T) {
 	e := setupTeamTest()
-	err := e.teamService.CreateTeam(e.ctx, teamPlatform)
+	_, err := e.teamService.CreateTeam(e.ctx, teamPlatform, false)
 	require.NoError(t, err)
 
 	team, err := e.teamService.Team(e.ctx, teamPlatformName)
@@ -135,3 +189,431 @@ func TestGetTeamFailNotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
+
+var (
+	teamBackendName = domain.TeamName("backend")
+	thirdUserID     = domain.UserID("third-user-id")
+	fourthUserID    = domain.UserID("fourth-user-id")
+
+	teamBackend = domain.Team{
+		Name: teamBackendName,
+		Members: []domain.TeamMember{
+			{UserID: thirdUserID, Username: "Third", IsActive: true},
+			{UserID: fourthUserID, Username: "Fourth", IsActive: true},
+		},
+	}
+)
+
+func setupMembershipTest(t *testing.T) testTeamEnviroment {
+	e := setupTeamTest()
+	_, err := e.teamService.CreateTeam(e.ctx, teamPlatform, false)
+	require.NoError(t, err)
+	_, err = e.teamService.CreateTeam(e.ctx, teamBackend, false)
+	require.NoError(t, err)
+	return e
+}
+
+func TestAddMemberCreatesUser(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	member := domain.TeamMember{UserID: "new-user-id", Username: "New", IsActive: true}
+	team, err := e.teamService.AddMember(e.ctx, teamPlatformName, member)
+
+	require.NoError(t, err)
+	assert.Contains(t, team.Members, member)
+	assert.Equal(t, teamPlatformName, e.storage.Users["new-user-id"].TeamName)
+}
+
+func TestAddMemberJoinsSecondTeam(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	member := domain.TeamMember{UserID: thirdUserID, Username: "Third", IsActive: true}
+	team, err := e.teamService.AddMember(e.ctx, teamPlatformName, member)
+
+	require.NoError(t, err)
+	assert.Contains(t, team.Members, member)
+	assert.Equal(t, teamBackendName, e.storage.Users[thirdUserID].TeamName)
+	assert.ElementsMatch(t, []domain.TeamName{teamBackendName, teamPlatformName}, e.storage.Users[thirdUserID].Teams)
+
+	backend, err := e.teamService.Team(e.ctx, teamBackendName)
+	require.NoError(t, err)
+	assert.Contains(t, backend.Members, member)
+}
+
+func TestAddMemberFailsOnUnknownTeam(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	member := domain.TeamMember{UserID: "new-user-id", Username: "New", IsActive: true}
+	_, err := e.teamService.AddMember(e.ctx, "ghost-team", member)
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestRemoveMemberReassignsOpenReviews(t *testing.T) {
+	e := setupMembershipTest(t)
+	fifth := domain.TeamMember{UserID: "fifth-user-id", Username: "Fifth", IsActive: true}
+	_, err := e.teamService.AddMember(e.ctx, teamBackendName, fifth)
+	require.NoError(t, err)
+
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: fourthUserID, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	released, err := e.teamService.RemoveMember(e.ctx, teamBackendName, thirdUserID)
+
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, fifth.UserID, released[0].ReplacedBy)
+	assert.Equal(t, []domain.UserID{fifth.UserID}, e.storage.PRs["pr-1"].AssignedReviewers)
+	assert.Equal(t, domain.TeamName(""), e.storage.Users[thirdUserID].TeamName)
+}
+
+func TestRemoveMemberDropsReviewWithoutCandidates(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: fourthUserID, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	released, err := e.teamService.RemoveMember(e.ctx, teamBackendName, thirdUserID)
+
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Empty(t, released[0].ReplacedBy)
+	assert.Empty(t, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+func TestRemoveMemberKeepsReviewsForOtherTeams(t *testing.T) {
+	e := setupMembershipTest(t)
+	_, err := e.teamService.AddMember(e.ctx, teamPlatformName, domain.TeamMember{UserID: thirdUserID, Username: "Third", IsActive: true})
+	require.NoError(t, err)
+
+	e.storage.PRs["pr-backend"] = domain.PullRequest{
+		ID: "pr-backend", AuthorID: fourthUserID, TeamName: teamBackendName, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+	e.storage.PRs["pr-platform"] = domain.PullRequest{
+		ID: "pr-platform", AuthorID: firstUserID, TeamName: teamPlatformName, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	released, err := e.teamService.RemoveMember(e.ctx, teamPlatformName, thirdUserID)
+
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, domain.PullRequestID("pr-platform"), released[0].PullRequestID)
+	assert.Equal(t, []domain.UserID{thirdUserID}, e.storage.PRs["pr-backend"].AssignedReviewers)
+	assert.Equal(t, []domain.TeamName{teamBackendName}, e.storage.Users[thirdUserID].Teams)
+}
+
+func TestRemoveMemberFailsWhenNotMember(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	_, err := e.teamService.RemoveMember(e.ctx, teamPlatformName, thirdUserID)
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestMoveMemberKeepsReviewsInPreviousTeam(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: "outsider", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	move, err := e.teamService.MoveMember(e.ctx, thirdUserID, teamPlatformName)
+
+	require.NoError(t, err)
+	assert.Equal(t, teamPlatformName, move.User.TeamName)
+	require.Len(t, move.ReleasedReviews, 1)
+	assert.Equal(t, fourthUserID, move.ReleasedReviews[0].ReplacedBy)
+	assert.Equal(t, []domain.UserID{fourthUserID}, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+// failingTeamService works on the same storage as the environment, but
+// every change of reviewers fails.
+func (e testTeamEnviroment) failingTeamService() *service.TeamService {
+	return service.NewTeamService(e.teamRepo, e.userRepo, failingReviewUnitOfWork{inmemory.NewUnitOfWork(e.storage)}, nil)
+}
+
+func TestRemoveAndMoveMemberRollBackWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: "outsider", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+	teamService := e.failingTeamService()
+
+	_, err := teamService.RemoveMember(e.ctx, teamBackendName, thirdUserID)
+	require.ErrorIs(t, err, errReviewerChange)
+	assert.Equal(t, teamBackendName, e.storage.Users[thirdUserID].TeamName)
+	assert.Equal(t, []domain.TeamName{teamBackendName}, e.storage.Users[thirdUserID].Teams)
+
+	_, err = teamService.MoveMember(e.ctx, thirdUserID, teamPlatformName)
+	require.ErrorIs(t, err, errReviewerChange)
+	assert.Equal(t, teamBackendName, e.storage.Users[thirdUserID].TeamName)
+
+	assert.Equal(t, []domain.UserID{thirdUserID}, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+func TestMoveMemberFailsOnUnknownTeam(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	_, err := e.teamService.MoveMember(e.ctx, thirdUserID, "ghost-team")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Equal(t, teamBackendName, e.storage.Users[thirdUserID].TeamName)
+}
+
+func TestLeadMovesUserWithoutTeamIntoOwnTeam(t *testing.T) {
+	e := setupMembershipTest(t)
+	userService := service.NewUserService(e.userRepo, e.prRepo, inmemory.NewUnitOfWork(e.storage), nil)
+	_, err := userService.CreateUser(e.ctx, domain.User{ID: "new-user-id", Username: "New", IsActive: true})
+	require.NoError(t, err)
+
+	lead := domain.WithPrincipal(e.ctx, domain.Principal{UserID: firstUserID, Roles: []domain.UserRole{domain.RoleLead}})
+	move, err := e.teamService.MoveMember(lead, "new-user-id", teamPlatformName)
+
+	require.NoError(t, err)
+	assert.Equal(t, teamPlatformName, move.User.TeamName)
+	assert.Empty(t, move.ReleasedReviews)
+}
+
+func TestCreateTeamOfUsersKeepsTheirHomeTeams(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	team, err := e.teamService.CreateTeamOfUsers(e.ctx, "frontend", []domain.UserID{firstUserID, thirdUserID})
+
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+	assert.Equal(t, teamPlatformName, e.storage.Users[firstUserID].TeamName)
+	assert.True(t, e.storage.Users[thirdUserID].IsMemberOf("frontend"))
+}
+
+func TestCreateTeamOfUsersFailsOnUnknownUser(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	_, err := e.teamService.CreateTeamOfUsers(e.ctx, "frontend", []domain.UserID{firstUserID, "unknown"})
+
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	_, exists := e.storage.Teams["frontend"]
+	assert.False(t, exists)
+	assert.False(t, e.storage.Users[firstUserID].IsMemberOf("frontend"))
+}
+
+func TestChangeTeamReplacesMembers(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: "outsider", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{fourthUserID},
+	}
+
+	team, err := e.teamService.ChangeTeam(e.ctx, teamBackendName, []service.TeamChange{
+		{Kind: service.TeamChangeSetMembers, Members: []domain.UserID{thirdUserID, firstUserID}},
+	})
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.UserID{thirdUserID, firstUserID}, memberIDs(team))
+	assert.Equal(t, teamPlatformName, e.storage.Users[firstUserID].TeamName)
+	assert.False(t, e.storage.Users[fourthUserID].IsMemberOf(teamBackendName))
+
+	// both remaining members are candidates for the released review
+	reviewers := e.storage.PRs["pr-1"].AssignedReviewers
+	require.Len(t, reviewers, 1)
+	assert.Contains(t, []domain.UserID{thirdUserID, firstUserID}, reviewers[0])
+}
+
+func TestChangeTeamAppliesNothingWhenAChangeFails(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	_, err := e.teamService.ChangeTeam(e.ctx, teamBackendName, []service.TeamChange{
+		{Kind: service.TeamChangeAddMembers, Members: []domain.UserID{firstUserID}},
+		{Kind: service.TeamChangeRemoveMembers, Members: []domain.UserID{secondUserID}},
+	})
+
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	assert.False(t, e.storage.Users[firstUserID].IsMemberOf(teamBackendName))
+}
+
+func memberIDs(team domain.Team) []domain.UserID {
+	userIDs := make([]domain.UserID, len(team.Members))
+	for i, member := range team.Members {
+		userIDs[i] = member.UserID
+	}
+
+	return userIDs
+}
+
+func TestRenameTeamKeepsMembers(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	team, err := e.teamService.RenameTeam(e.ctx, teamBackendName, "core")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TeamName("core"), team.Name)
+	assert.ElementsMatch(t, teamBackend.Members, team.Members)
+
+	_, err = e.teamService.Team(e.ctx, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestRenameTeamFailsOnExistingName(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	_, err := e.teamService.RenameTeam(e.ctx, teamBackendName, teamPlatformName)
+
+	assert.ErrorIs(t, err, domain.ErrTeamExists)
+}
+
+func TestArchiveTeamDeactivatesMembersAndReleasesReviews(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: fourthUserID, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	archival, err := e.teamService.ArchiveTeam(e.ctx, teamBackendName, "")
+
+	require.NoError(t, err)
+	assert.True(t, archival.Team.IsArchived())
+	assert.ElementsMatch(t, []domain.UserID{thirdUserID, fourthUserID}, archival.DeactivatedMembers)
+	assert.Empty(t, archival.MovedMembers)
+	require.Len(t, archival.ReleasedReviews, 1)
+	assert.Empty(t, e.storage.PRs["pr-1"].AssignedReviewers)
+	assert.False(t, e.storage.Users[thirdUserID].IsActive)
+	assert.Equal(t, teamBackendName, e.storage.Users[thirdUserID].TeamName)
+
+	team, err := e.teamService.Team(e.ctx, teamBackendName)
+	require.NoError(t, err)
+	assert.True(t, team.IsArchived())
+}
+
+func TestArchiveTeamRollsBackWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: "outsider", Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID, fourthUserID},
+	}
+
+	_, err := e.failingTeamService().ArchiveTeam(e.ctx, teamBackendName, "")
+
+	require.ErrorIs(t, err, errReviewerChange)
+	assert.False(t, e.storage.Teams[teamBackendName].IsArchived())
+	assert.True(t, e.storage.Users[thirdUserID].IsActive)
+	assert.True(t, e.storage.Users[fourthUserID].IsActive)
+	assert.Equal(t, []domain.UserID{thirdUserID, fourthUserID}, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+func TestArchiveTeamMovesMembers(t *testing.T) {
+	e := setupMembershipTest(t)
+	e.storage.PRs["pr-1"] = domain.PullRequest{
+		ID: "pr-1", AuthorID: fourthUserID, TeamName: teamBackendName, Status: domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{thirdUserID},
+	}
+
+	archival, err := e.teamService.ArchiveTeam(e.ctx, teamBackendName, teamPlatformName)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.UserID{thirdUserID, fourthUserID}, archival.MovedMembers)
+	assert.Empty(t, archival.Team.Members)
+	assert.Equal(t, teamPlatformName, e.storage.Users[thirdUserID].TeamName)
+	assert.Equal(t, []domain.TeamName{teamPlatformName}, e.storage.Users[thirdUserID].Teams)
+	assert.True(t, e.storage.Users[thirdUserID].IsActive)
+
+	// the review for the archived team stays behind
+	require.Len(t, archival.ReleasedReviews, 1)
+	assert.Empty(t, e.storage.PRs["pr-1"].AssignedReviewers)
+}
+
+func TestArchivedTeamStopsReceivingAssignments(t *testing.T) {
+	e := setupMembershipTest(t)
+	_, err := e.teamService.ArchiveTeam(e.ctx, teamBackendName, "")
+	require.NoError(t, err)
+
+	_, err = e.userRepo.SetIsActiveByID(e.ctx, thirdUserID, true)
+	require.NoError(t, err)
+
+	active, err := e.userRepo.ActiveUsersByTeamName(e.ctx, teamBackendName)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	member := domain.TeamMember{UserID: "new-user-id", Username: "New", IsActive: true}
+	_, err = e.teamService.AddMember(e.ctx, teamBackendName, member)
+	assert.ErrorIs(t, err, domain.ErrTeamArchived)
+
+	_, err = e.teamService.MoveMember(e.ctx, firstUserID, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrTeamArchived)
+}
+
+func TestDeleteTeamOnlyWhenEmpty(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	err := e.teamService.DeleteTeam(e.ctx, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrTeamNotEmpty)
+
+	_, err = e.teamService.ArchiveTeam(e.ctx, teamBackendName, teamPlatformName)
+	require.NoError(t, err)
+
+	err = e.teamService.DeleteTeam(e.ctx, teamBackendName)
+	require.NoError(t, err)
+
+	_, err = e.teamService.Team(e.ctx, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestCreateTeamUnderParent(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	checkout := domain.Team{Name: "backend/checkout", ParentName: teamBackendName, Members: []domain.TeamMember{}}
+	_, err := e.teamService.CreateTeam(e.ctx, checkout, false)
+	require.NoError(t, err)
+
+	orphan := domain.Team{Name: "orphan", ParentName: "ghost-team", Members: []domain.TeamMember{}}
+	_, err = e.teamService.CreateTeam(e.ctx, orphan, false)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	tree, err := e.teamService.TeamWithDescendants(e.ctx, teamBackendName)
+	require.NoError(t, err)
+	require.Len(t, tree.SubTeams, 1)
+	assert.Equal(t, checkout.Name, tree.SubTeams[0].Name)
+	assert.Equal(t, teamBackendName, tree.SubTeams[0].ParentName)
+
+	err = e.teamService.DeleteTeam(e.ctx, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrTeamNotEmpty)
+}
+
+func TestSetParentRejectsCycles(t *testing.T) {
+	e := setupMembershipTest(t)
+
+	team, err := e.teamService.SetParent(e.ctx, teamBackendName, teamPlatformName)
+	require.NoError(t, err)
+	assert.Equal(t, teamPlatformName, team.ParentName)
+
+	_, err = e.teamService.SetParent(e.ctx, teamPlatformName, teamBackendName)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, err = e.teamService.SetParent(e.ctx, teamPlatformName, teamPlatformName)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	team, err = e.teamService.SetParent(e.ctx, teamBackendName, "")
+	require.NoError(t, err)
+	assert.Empty(t, team.ParentName)
+}
+
+func TestActiveTeamsSkipsArchivedTeams(t *testing.T) {
+	e := setupTeamTest()
+	_, err := e.teamService.CreateTeam(e.ctx, teamPlatform, false)
+	require.NoError(t, err)
+	_, err = e.teamService.CreateTeam(e.ctx, domain.Team{Name: "legacy"}, false)
+	require.NoError(t, err)
+	_, err = e.teamService.ArchiveTeam(e.ctx, "legacy", "")
+	require.NoError(t, err)
+
+	teams, err := e.teamService.ActiveTeams(e.ctx)
+
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	assert.Equal(t, teamPlatformName, teams[0].Name)
+	assert.Len(t, teams[0].Members, 2)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	tokenSecretBytes = 32
+	// tokenSecretPrefix makes leaked secrets easy to spot in logs and code.
+	tokenSecretPrefix = "prr_"
+)
+
+// BootstrapTokenName is the name of the admin token configured at startup,
+// it lets the first tokens be issued.
+const BootstrapTokenName = "bootstrap"
+
+type TokenService struct {
+	tokenRepo      repository.APITokenRepository
+	bootstrapToken string
+}
+
+// NewTokenService takes the secret of the bootstrap admin token, an empty
+// secret disables it.
+func NewTokenService(tr repository.APITokenRepository, bootstrapToken string) *TokenService {
+	return &TokenService{
+		tokenRepo:      tr,
+		bootstrapToken: bootstrapToken,
+	}
+}
+
+// Issue creates a token with the given scopes and returns it with its
+// secret, the secret can't be recovered later.
+func (s *TokenService) Issue(ctx context.Context, name string, scopes []domain.TokenScope) (domain.APIToken, string, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.APIToken{}, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.APIToken{}, "", fmt.Errorf("%w: name must not be empty", domain.ErrInvalidArgument)
+	}
+
+	if len(scopes) == 0 {
+		return domain.APIToken{}, "", fmt.Errorf("%w: at least one scope is required", domain.ErrInvalidArgument)
+	}
+
+	unique := make([]domain.TokenScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(domain.TokenScopes, scope) {
+			return domain.APIToken{}, "", fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidArgument, scope)
+		}
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+
+	raw := make([]byte, tokenSecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return domain.APIToken{}, "", err
+	}
+	secret := tokenSecretPrefix + hex.EncodeToString(raw)
+
+	token, err := s.tokenRepo.CreateToken(ctx, domain.APIToken{
+		Name:   name,
+		Scopes: unique,
+		Hash:   hashTokenSecret(secret),
+	})
+	if err != nil {
+		return domain.APIToken{}, "", err
+	}
+
+	return token, secret, nil
+}
+
+func (s *TokenService) Tokens(ctx context.Context) ([]domain.APIToken, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.tokenRepo.Tokens(ctx)
+}
+
+// Revoke revokes the token for good, revoking it again changes nothing.
+func (s *TokenService) Revoke(ctx context.Context, tokenID int64) (domain.APIToken, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.APIToken{}, err
+	}
+
+	return s.tokenRepo.RevokeToken(ctx, tokenID, time.Now())
+}
+
+// Authenticate finds the token the secret belongs to, unknown and revoked
+// secrets fail with domain.ErrUnauthorized.
+func (s *TokenService) Authenticate(ctx context.Context, secret string) (domain.APIToken, error) {
+	if secret == "" {
+		return domain.APIToken{}, domain.ErrUnauthorized
+	}
+
+	if s.bootstrapToken != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.bootstrapToken)) == 1 {
+		return domain.APIToken{Name: BootstrapTokenName, Scopes: []domain.TokenScope{domain.ScopeAdmin}}, nil
+	}
+
+	token, err := s.tokenRepo.UseToken(ctx, hashTokenSecret(secret), time.Now())
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.APIToken{}, domain.ErrUnauthorized
+	}
+	if err != nil {
+		return domain.APIToken{}, err
+	}
+
+	return token, nil
+}
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTokenTest(bootstrapToken string) (context.Context, *inmemory.InMemoryStorage, *service.TokenService) {
+	storage, _ := inmemory.NewStorage()
+
+	return context.Background(), storage, service.NewTokenService(inmemory.NewAPITokenRepo(storage), bootstrapToken)
+}
+
+func TestIssueTokenStoresOnlyHash(t *testing.T) {
+	ctx, storage, tokenService := setupTokenTest("")
+
+	token, secret, err := tokenService.Issue(ctx, " ci ", []domain.TokenScope{domain.ScopePRsWrite, domain.ScopeRead, domain.ScopePRsWrite})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ci", token.Name)
+	assert.Equal(t, []domain.TokenScope{domain.ScopePRsWrite, domain.ScopeRead}, token.Scopes)
+	assert.True(t, strings.HasPrefix(secret, "prr_"))
+
+	stored := storage.APITokens[token.ID]
+	assert.Len(t, stored.Hash, 64)
+	assert.NotContains(t, stored.Hash, strings.TrimPrefix(secret, "prr_"))
+}
+
+func TestIssueTokenValidatesInput(t *testing.T) {
+	ctx, _, tokenService := setupTokenTest("")
+
+	_, _, err := tokenService.Issue(ctx, "", []domain.TokenScope{domain.ScopeRead})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, _, err = tokenService.Issue(ctx, "ci", nil)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, _, err = tokenService.Issue(ctx, "ci", []domain.TokenScope{"teams:delete"})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	ctx, _, tokenService := setupTokenTest("")
+
+	issued, secret, err := tokenService.Issue(ctx, "ci", []domain.TokenScope{domain.ScopeRead})
+	require.NoError(t, err)
+
+	token, err := tokenService.Authenticate(ctx, secret)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, token.ID)
+	assert.NotNil(t, token.LastUsedAt)
+	assert.True(t, token.Allows(domain.ScopeRead))
+	assert.False(t, token.Allows(domain.ScopePRsWrite))
+
+	_, err = tokenService.Authenticate(ctx, secret+"0")
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+
+	_, err = tokenService.Authenticate(ctx, "")
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
+
+func TestRevokedTokenIsRejected(t *testing.T) {
+	ctx, _, tokenService := setupTokenTest("")
+
+	issued, secret, err := tokenService.Issue(ctx, "ci", []domain.TokenScope{domain.ScopeRead})
+	require.NoError(t, err)
+
+	revoked, err := tokenService.Revoke(ctx, issued.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+
+	again, err := tokenService.Revoke(ctx, issued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, revoked.RevokedAt, again.RevokedAt)
+
+	_, err = tokenService.Authenticate(ctx, secret)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+
+	_, err = tokenService.Revoke(ctx, 42)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestBootstrapTokenIsAdmin(t *testing.T) {
+	ctx, _, tokenService := setupTokenTest("bootstrap-secret")
+
+	token, err := tokenService.Authenticate(ctx, "bootstrap-secret")
+	require.NoError(t, err)
+	assert.Equal(t, service.BootstrapTokenName, token.Name)
+	assert.True(t, token.Allows(domain.ScopeTeamsWrite))
+
+	_, _, noBootstrap := setupTokenTest("")
+	_, err = noBootstrap.Authenticate(ctx, "")
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
@@ -3,32 +3,348 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/mail"
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/repository"
 )
 
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+
+	anonymizedUsername = "former employee"
+)
+
 type UserService struct {
 	userRepo repository.UserRepository
 	prRepo   repository.PullRequestRepository
+	uow      repository.UnitOfWork
+	notifier Notifier
+}
+
+type UserList struct {
+	Users      []domain.User
+	NextCursor *domain.UserID
+}
+
+// Offboarding reports what happened to the work of a departing user.
+type Offboarding struct {
+	User                    domain.User
+	ReleasedReviews         []ReleasedReview
+	TransferredPullRequests []domain.PullRequestID
+	ClosedPullRequests      []domain.PullRequestID
+}
+
+// UserUpdate holds the profile fields to change, nil fields are kept as is.
+type UserUpdate struct {
+	Username *string
+	FullName *string
+	Email    *string
+	Role     *domain.UserRole
+}
+
+// UserPatch changes the profile of a user and, when IsActive is set, whether
+// the user is active.
+type UserPatch struct {
+	UserUpdate
+	IsActive *bool
 }
 
 type UserReviewAssignments struct {
 	UserID       domain.UserID
 	PullRequests []domain.PullRequestShort
+	NextCursor   *domain.PullRequestCursor
+}
+
+type UserAuthoredPullRequests struct {
+	UserID       domain.UserID
+	PullRequests []domain.PullRequest
+	NextCursor   *domain.PullRequestCursor
 }
 
-func NewUserService(ur repository.UserRepository, prr repository.PullRequestRepository) *UserService {
+func NewUserService(ur repository.UserRepository, prr repository.PullRequestRepository, uow repository.UnitOfWork, n Notifier) *UserService {
 	return &UserService{
 		userRepo: ur,
 		prRepo:   prr,
+		uow:      uow,
+		notifier: n,
+	}
+}
+
+func (s *UserService) User(ctx context.Context, userID domain.UserID) (domain.User, error) {
+	return s.userRepo.UserByID(ctx, userID)
+}
+
+// Principal gives a signed-in user the role the directory stores for them,
+// so that changing the role takes effect right away. The roles of the token
+// only count for users the directory doesn't know, deleted users are plain
+// members.
+func (s *UserService) Principal(ctx context.Context, principal domain.Principal) (domain.Principal, error) {
+	user, err := s.userRepo.UserByID(ctx, principal.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return principal, nil
+	}
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	principal.Roles = []domain.UserRole{domain.RoleMember}
+	if !user.IsDeleted() {
+		principal.Roles = []domain.UserRole{user.Role}
+	}
+
+	return principal, nil
+}
+
+// CreateUser adds a user who does not belong to any team yet.
+func (s *UserService) CreateUser(ctx context.Context, user domain.User) (domain.User, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.User{}, err
+	}
+
+	if user.ID == "" || user.Username == "" {
+		return domain.User{}, fmt.Errorf("%w: user_id and username are required", domain.ErrInvalidArgument)
+	}
+
+	if user.Role == "" {
+		user.Role = domain.RoleMember
+	}
+	if !isKnownRole(user.Role) {
+		return domain.User{}, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidArgument, user.Role)
+	}
+
+	if !isValidEmail(user.Email) {
+		return domain.User{}, fmt.Errorf("%w: invalid email %q", domain.ErrInvalidArgument, user.Email)
+	}
+
+	if _, err := s.userRepo.UserByID(ctx, user.ID); err == nil {
+		return domain.User{}, fmt.Errorf("%w: %s", domain.ErrUserExists, user.ID)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return domain.User{}, err
+	}
+
+	user.TeamName = ""
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return domain.User{}, err
+	}
+
+	return s.userRepo.UserByID(ctx, user.ID)
+}
+
+// FindUsers returns every user matching the filter at once, Limit and After
+// are ignored.
+func (s *UserService) FindUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	if filter.Role != "" && !isKnownRole(filter.Role) {
+		return nil, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidArgument, filter.Role)
+	}
+
+	filter.Limit = 0
+	filter.After = ""
+
+	return s.userRepo.List(ctx, filter)
+}
+
+func (s *UserService) ListUsers(ctx context.Context, filter domain.UserFilter) (UserList, error) {
+	if filter.Role != "" && !isKnownRole(filter.Role) {
+		return UserList{}, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidArgument, filter.Role)
+	}
+
+	switch {
+	case filter.Limit < 0:
+		return UserList{}, fmt.Errorf("%w: limit must be positive", domain.ErrInvalidArgument)
+	case filter.Limit == 0:
+		filter.Limit = defaultPageLimit
+	case filter.Limit > maxPageLimit:
+		filter.Limit = maxPageLimit
+	}
+
+	pageLimit := filter.Limit
+	filter.Limit++
+
+	users, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return UserList{}, err
+	}
+
+	users, nextCursor := cutPage(users, pageLimit, func(user domain.User) domain.UserID {
+		return user.ID
+	})
+
+	return UserList{Users: users, NextCursor: nextCursor}, nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, userID domain.UserID, update UserUpdate) (domain.User, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := s.userRepo.UserByID(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if user, err = updatedUser(user, update); err != nil {
+		return domain.User{}, err
+	}
+
+	return s.userRepo.UpdateProfile(ctx, user)
+}
+
+// PatchUser updates the profile and the active flag of the user at once. A
+// deactivated user hands their open reviews over like on DeactivateUser.
+func (s *UserService) PatchUser(ctx context.Context, userID domain.UserID, patch UserPatch) (domain.User, []ReleasedReview, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.User{}, nil, err
+	}
+
+	var user domain.User
+	released := []ReleasedReview{}
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		current, err := tx.Users().UserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if current, err = updatedUser(current, patch.UserUpdate); err != nil {
+			return err
+		}
+
+		if user, err = tx.Users().UpdateProfile(ctx, current); err != nil {
+			return err
+		}
+
+		if patch.IsActive == nil || *patch.IsActive == user.IsActive {
+			return nil
+		}
+
+		if user, err = tx.Users().SetIsActiveByID(ctx, userID, *patch.IsActive); err != nil {
+			return err
+		}
+
+		if !user.IsActive {
+			released, err = rr.releaseAllOpenReviews(ctx, user, domain.ReassignDeactivated)
+		}
+		return err
+	})
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+
+	return user, released, nil
+}
+
+// updatedUser validates the update and applies it to the user.
+func updatedUser(user domain.User, update UserUpdate) (domain.User, error) {
+	if update.Username != nil {
+		if *update.Username == "" {
+			return domain.User{}, fmt.Errorf("%w: username must not be empty", domain.ErrInvalidArgument)
+		}
+		user.Username = *update.Username
+	}
+
+	if update.FullName != nil {
+		user.FullName = *update.FullName
+	}
+
+	if update.Email != nil {
+		if !isValidEmail(*update.Email) {
+			return domain.User{}, fmt.Errorf("%w: invalid email %q", domain.ErrInvalidArgument, *update.Email)
+		}
+		user.Email = *update.Email
 	}
+
+	if update.Role != nil {
+		if !isKnownRole(*update.Role) {
+			return domain.User{}, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidArgument, *update.Role)
+		}
+		user.Role = *update.Role
+	}
+
+	return user, nil
+}
+
+// DeleteUser removes the user from the directory and from all teams. Users
+// with open reviews are only deleted when reassign is set, their reviews are
+// then handed over within the teams of the pull requests.
+func (s *UserService) DeleteUser(ctx context.Context, userID domain.UserID, reassign bool) ([]ReleasedReview, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	released := []ReleasedReview{}
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		// the lock keeps the user from being assigned until it is deleted
+		if _, err := tx.LockUsersForUpdate(ctx, []domain.UserID{userID}); err != nil {
+			return err
+		}
+
+		user, err := tx.Users().UserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if reassign {
+			if released, err = rr.releaseAllOpenReviews(ctx, user, domain.ReassignUserDeleted); err != nil {
+				return err
+			}
+		} else {
+			openReviews, err := tx.PullRequests().PullRequestsByReviewer(ctx, userID, domain.PullRequestFilter{
+				Statuses: []domain.PRStatus{domain.StatusOpen},
+				Limit:    1,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(openReviews) > 0 {
+				return fmt.Errorf("%w: %s", domain.ErrHasOpenReviews, userID)
+			}
+		}
+
+		return tx.Users().Delete(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return released, nil
 }
 
 func (s *UserService) SetIsActive(ctx context.Context, userID domain.UserID, isActive bool) (domain.User, error) {
+	if err := authorizeSelfOrTheirLead(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, err
+	}
+
 	return s.userRepo.SetIsActiveByID(ctx, userID, isActive)
 }
 
-func (s *UserService) ReviewAssignments(ctx context.Context, userID domain.UserID) (UserReviewAssignments, error) {
+// DeactivateUser takes the user out of the reviewer pool and hands their open
+// reviews over, each one within the team the pull request is reviewed by.
+func (s *UserService) DeactivateUser(ctx context.Context, userID domain.UserID) (domain.User, []ReleasedReview, error) {
+	if err := authorizeSelfOrTheirLead(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, nil, err
+	}
+
+	var user domain.User
+	var released []ReleasedReview
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		var err error
+		if user, err = tx.Users().SetIsActiveByID(ctx, userID, false); err != nil {
+			return err
+		}
+
+		released, err = rr.releaseAllOpenReviews(ctx, user, domain.ReassignDeactivated)
+		return err
+	})
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+
+	return user, released, nil
+}
+
+func (s *UserService) ReviewAssignments(ctx context.Context, userID domain.UserID, filter domain.PullRequestFilter) (UserReviewAssignments, error) {
 	if _, err := s.userRepo.UserByID(ctx, userID); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			return UserReviewAssignments{}, domain.ErrNotFound
@@ -37,13 +353,238 @@ func (s *UserService) ReviewAssignments(ctx context.Context, userID domain.UserI
 		return UserReviewAssignments{}, err
 	}
 
-	prs, err := s.prRepo.PullRequestsByReviewer(ctx, userID)
+	filter, err := normalizePullRequestFilter(filter)
 	if err != nil {
 		return UserReviewAssignments{}, err
 	}
 
+	pageLimit := filter.Limit
+	filter.Limit++
+
+	prs, err := s.prRepo.PullRequestsByReviewer(ctx, userID, filter)
+	if err != nil {
+		return UserReviewAssignments{}, err
+	}
+
+	prs, nextCursor := cutPage(prs, pageLimit, func(pr domain.PullRequestShort) domain.PullRequestCursor {
+		return domain.PullRequestCursor{CreatedAt: pr.CreatedAt, ID: pr.ID}
+	})
+
 	return UserReviewAssignments{
 		UserID:       userID,
 		PullRequests: prs,
+		NextCursor:   nextCursor,
+	}, nil
+}
+
+func (s *UserService) AuthoredPullRequests(ctx context.Context, userID domain.UserID, filter domain.PullRequestFilter) (UserAuthoredPullRequests, error) {
+	if _, err := s.userRepo.UserByID(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return UserAuthoredPullRequests{}, domain.ErrNotFound
+		}
+
+		return UserAuthoredPullRequests{}, err
+	}
+
+	filter, err := normalizePullRequestFilter(filter)
+	if err != nil {
+		return UserAuthoredPullRequests{}, err
+	}
+
+	pageLimit := filter.Limit
+	filter.Limit++
+
+	prs, err := s.prRepo.PullRequestsByAuthor(ctx, userID, filter)
+	if err != nil {
+		return UserAuthoredPullRequests{}, err
+	}
+
+	prs, nextCursor := cutPage(prs, pageLimit, func(pr domain.PullRequest) domain.PullRequestCursor {
+		return domain.PullRequestCursor{CreatedAt: pr.CreatedAt, ID: pr.ID}
+	})
+
+	return UserAuthoredPullRequests{
+		UserID:       userID,
+		PullRequests: prs,
+		NextCursor:   nextCursor,
 	}, nil
 }
+
+// cutPage trims a result fetched with one extra item to the page limit and
+// returns the cursor of the next page when that extra item was present.
+func cutPage[T, C any](items []T, limit int, cursorOf func(T) C) ([]T, *C) {
+	if len(items) <= limit {
+		return items, nil
+	}
+
+	items = items[:limit]
+	cursor := cursorOf(items[limit-1])
+
+	return items, &cursor
+}
+
+func normalizePullRequestFilter(filter domain.PullRequestFilter) (domain.PullRequestFilter, error) {
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = []domain.PRStatus{domain.StatusOpen}
+	}
+
+	for _, status := range filter.Statuses {
+		if !isKnownStatus(status) {
+			return domain.PullRequestFilter{}, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidArgument, status)
+		}
+	}
+
+	switch filter.Order {
+	case "":
+		filter.Order = domain.SortOldestFirst
+	case domain.SortOldestFirst, domain.SortNewestFirst:
+	default:
+		return domain.PullRequestFilter{}, fmt.Errorf("%w: unknown sort order %q", domain.ErrInvalidArgument, filter.Order)
+	}
+
+	switch {
+	case filter.Limit < 0:
+		return domain.PullRequestFilter{}, fmt.Errorf("%w: limit must be positive", domain.ErrInvalidArgument)
+	case filter.Limit == 0:
+		filter.Limit = defaultPageLimit
+	case filter.Limit > maxPageLimit:
+		filter.Limit = maxPageLimit
+	}
+
+	return filter, nil
+}
+
+// Offboard deactivates a departing user, hands their open reviews over and
+// either transfers their open pull requests to the successor or closes them
+// when no successor is given. The successor has to be active. The user is
+// anonymised but keeps its ID, so the pull request history stays intact.
+func (s *UserService) Offboard(ctx context.Context, userID domain.UserID, successorID domain.UserID) (Offboarding, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return Offboarding{}, err
+	}
+
+	if successorID == userID {
+		return Offboarding{}, fmt.Errorf("%w: user can not be their own successor", domain.ErrInvalidArgument)
+	}
+
+	offboarding := Offboarding{
+		TransferredPullRequests: []domain.PullRequestID{},
+		ClosedPullRequests:      []domain.PullRequestID{},
+	}
+
+	err := withinTx(ctx, s.uow, s.notifier, func(ctx context.Context, tx repository.Tx, rr reviewReleaser) error {
+		// the successor stays locked, so it can not be deactivated before
+		// taking the pull requests over
+		lockIDs := []domain.UserID{userID}
+		if successorID != "" {
+			lockIDs = append(lockIDs, successorID)
+		}
+		if _, err := tx.LockUsersForUpdate(ctx, lockIDs); err != nil {
+			return err
+		}
+
+		user, err := tx.Users().UserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if successorID != "" {
+			successor, err := tx.Users().UserByID(ctx, successorID)
+			if err != nil {
+				return err
+			}
+
+			if !successor.IsActive {
+				return fmt.Errorf("%w: successor %s is not active", domain.ErrInvalidArgument, successorID)
+			}
+		}
+
+		if _, err := tx.Users().SetIsActiveByID(ctx, userID, false); err != nil {
+			return err
+		}
+
+		if offboarding.ReleasedReviews, err = rr.releaseAllOpenReviews(ctx, user, domain.ReassignOffboarded); err != nil {
+			return err
+		}
+
+		authored, err := tx.PullRequests().PullRequestsByAuthor(ctx, userID, domain.PullRequestFilter{
+			Statuses: []domain.PRStatus{domain.StatusOpen},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, pr := range authored {
+			if successorID == "" {
+				if _, err := tx.PullRequests().CloseByID(ctx, pr.ID); err != nil {
+					return err
+				}
+
+				offboarding.ClosedPullRequests = append(offboarding.ClosedPullRequests, pr.ID)
+				continue
+			}
+
+			if _, err := tx.PullRequests().SetAuthor(ctx, pr.ID, successorID); err != nil {
+				return err
+			}
+
+			offboarding.TransferredPullRequests = append(offboarding.TransferredPullRequests, pr.ID)
+
+			poolTeam := pr.TeamName
+			if poolTeam == "" {
+				poolTeam = user.TeamName
+			}
+
+			// The successor can not keep reviewing a pull request they now own.
+			released, err := rr.releaseReview(ctx, successorID, pr.ID, poolTeam, domain.ReassignOffboarded)
+			if err != nil && !errors.Is(err, domain.ErrNotAssigned) {
+				return err
+			}
+			if err == nil {
+				offboarding.ReleasedReviews = append(offboarding.ReleasedReviews, released)
+			}
+		}
+
+		user.Username = anonymizedUsername
+		user.FullName = ""
+		user.Email = ""
+		offboarding.User, err = tx.Users().UpdateProfile(ctx, user)
+		return err
+	})
+	if err != nil {
+		return Offboarding{}, err
+	}
+
+	return offboarding, nil
+}
+
+// isValidEmail accepts a bare address or an empty string, which means the
+// user gets no email.
+func isValidEmail(email string) bool {
+	if email == "" {
+		return true
+	}
+
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+func isKnownRole(role domain.UserRole) bool {
+	for _, known := range domain.UserRoles {
+		if role == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isKnownStatus(status domain.PRStatus) bool {
+	for _, known := range domain.PRStatuses {
+		if status == known {
+			return true
+		}
+	}
+
+	return false
+}
@@ -31,7 +31,7 @@ func setupUserTest() testUserEnviroment {
 	teamRepo := inmemory.NewTeamRepo(storage)
 	prRepo := inmemory.NewPullRequestRepo(storage)
 
-	userService := service.NewUserService(userRepo, prRepo)
+	userService := service.NewUserService(userRepo, prRepo, inmemory.NewUnitOfWork(storage), nil)
 
 	return testUserEnviroment{
 		ctx:         context.Background(),
@@ -142,7 +142,8 @@ func setupReviewTest() testUserEnviroment {
 func TestReviewAssignmentsSuccess(t *testing.T) {
 	e := setupReviewTest()
 
-	assignments, err := e.userService.ReviewAssignments(e.ctx, userID1)
+	filter := domain.PullRequestFilter{Statuses: domain.PRStatuses}
+	assignments, err := e.userService.ReviewAssignments(e.ctx, userID1, filter)
 
 	require.NoError(t, err)
 	assert.Equal(t, userID1, assignments.UserID)
@@ -162,7 +163,7 @@ func TestGetReviewAssignmentsZeroPRs(t *testing.T) {
 
 	e.storage.Users[userID1] = testUser1
 
-	assignments, err := e.userService.ReviewAssignments(e.ctx, userID1)
+	assignments, err := e.userService.ReviewAssignments(e.ctx, userID1, domain.PullRequestFilter{})
 	require.NoError(t, err)
 	assert.Equal(t, userID1, assignments.UserID)
 	assert.Len(t, assignments.PullRequests, 0)
@@ -171,7 +172,337 @@ func TestGetReviewAssignmentsZeroPRs(t *testing.T) {
 func TestGetReviewAssignmentsFailsOnNotFound(t *testing.T) {
 	e := setupReviewTest()
 
-	_, err := e.userService.ReviewAssignments(e.ctx, "u-ghost")
+	_, err := e.userService.ReviewAssignments(e.ctx, "u-ghost", domain.PullRequestFilter{})
 	require.Error(t, err)
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
+
+func TestReviewAssignmentsDefaultsToOpen(t *testing.T) {
+	e := setupReviewTest()
+
+	assignments, err := e.userService.ReviewAssignments(e.ctx, userID1, domain.PullRequestFilter{})
+
+	require.NoError(t, err)
+	require.Len(t, assignments.PullRequests, 1)
+	assert.Equal(t, prID1, assignments.PullRequests[0].ID)
+	assert.Equal(t, domain.ReviewPending, assignments.PullRequests[0].ReviewState)
+	assert.Nil(t, assignments.NextCursor)
+}
+
+func TestReviewAssignmentsPaginates(t *testing.T) {
+	e := setupReviewTest()
+	filter := domain.PullRequestFilter{Statuses: domain.PRStatuses, Limit: 1}
+
+	firstPage, err := e.userService.ReviewAssignments(e.ctx, userID1, filter)
+	require.NoError(t, err)
+	require.Len(t, firstPage.PullRequests, 1)
+	assert.Equal(t, prID1, firstPage.PullRequests[0].ID)
+	require.NotNil(t, firstPage.NextCursor)
+
+	filter.After = firstPage.NextCursor
+	secondPage, err := e.userService.ReviewAssignments(e.ctx, userID1, filter)
+	require.NoError(t, err)
+	require.Len(t, secondPage.PullRequests, 1)
+	assert.Equal(t, prID2, secondPage.PullRequests[0].ID)
+	assert.Nil(t, secondPage.NextCursor)
+}
+
+func TestReviewAssignmentsFailsOnUnknownStatus(t *testing.T) {
+	e := setupReviewTest()
+
+	filter := domain.PullRequestFilter{Statuses: []domain.PRStatus{"DRAFT"}}
+	_, err := e.userService.ReviewAssignments(e.ctx, userID1, filter)
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestAuthoredPullRequestsReturnsOwnPRs(t *testing.T) {
+	e := setupReviewTest()
+
+	authored, err := e.userService.AuthoredPullRequests(e.ctx, userID2, domain.PullRequestFilter{Statuses: domain.PRStatuses})
+
+	require.NoError(t, err)
+	assert.Equal(t, userID2, authored.UserID)
+	require.Len(t, authored.PullRequests, 2)
+	assert.Equal(t, prID1, authored.PullRequests[0].ID)
+	assert.Equal(t, prID2, authored.PullRequests[1].ID)
+	assert.Equal(t, []domain.UserID{userID1}, authored.PullRequests[0].AssignedReviewers)
+}
+
+func TestAuthoredPullRequestsDefaultsToOpen(t *testing.T) {
+	e := setupReviewTest()
+
+	authored, err := e.userService.AuthoredPullRequests(e.ctx, userID2, domain.PullRequestFilter{})
+
+	require.NoError(t, err)
+	require.Len(t, authored.PullRequests, 1)
+	assert.Equal(t, prID1, authored.PullRequests[0].ID)
+}
+
+func TestAuthoredPullRequestsFailsOnNotFound(t *testing.T) {
+	e := setupReviewTest()
+
+	_, err := e.userService.AuthoredPullRequests(e.ctx, "u-ghost", domain.PullRequestFilter{})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestUserReturnsProfile(t *testing.T) {
+	e := setupReviewTest()
+
+	user, err := e.userService.User(e.ctx, userID1)
+
+	require.NoError(t, err)
+	assert.Equal(t, testUser1.Username, user.Username)
+
+	_, err = e.userService.User(e.ctx, "non-existent-user")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestListUsersFiltersAndPaginates(t *testing.T) {
+	e := setupReviewTest()
+	lead := domain.User{ID: "u-3", Username: "Lead", Role: domain.RoleLead, TeamName: userTeamName, IsActive: false}
+	e.storage.Users[lead.ID] = lead
+
+	firstPage, err := e.userService.ListUsers(e.ctx, domain.UserFilter{TeamName: userTeamName, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, firstPage.Users, 2)
+	assert.Equal(t, userID1, firstPage.Users[0].ID)
+	require.NotNil(t, firstPage.NextCursor)
+
+	secondPage, err := e.userService.ListUsers(e.ctx, domain.UserFilter{TeamName: userTeamName, Limit: 2, After: *firstPage.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, secondPage.Users, 1)
+	assert.Equal(t, lead.ID, secondPage.Users[0].ID)
+	assert.Nil(t, secondPage.NextCursor)
+
+	inactive := false
+	leads, err := e.userService.ListUsers(e.ctx, domain.UserFilter{Role: domain.RoleLead, IsActive: &inactive})
+	require.NoError(t, err)
+	require.Len(t, leads.Users, 1)
+	assert.Equal(t, lead.ID, leads.Users[0].ID)
+
+	_, err = e.userService.ListUsers(e.ctx, domain.UserFilter{Role: "OWNER"})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestUpdateUserChangesProfileFields(t *testing.T) {
+	e := setupReviewTest()
+	username := "Renamed"
+	fullName := "User One Jr."
+	email := "user1@example.com"
+	role := domain.RoleLead
+
+	user, err := e.userService.UpdateUser(e.ctx, userID1, service.UserUpdate{Username: &username, FullName: &fullName, Email: &email, Role: &role})
+
+	require.NoError(t, err)
+	assert.Equal(t, username, user.Username)
+	assert.Equal(t, fullName, user.FullName)
+	assert.Equal(t, email, user.Email)
+	assert.Equal(t, role, user.Role)
+	assert.Equal(t, userTeamName, user.TeamName)
+	assert.Equal(t, user, e.storage.Users[userID1])
+}
+
+func TestUpdateUserRejectsInvalidFields(t *testing.T) {
+	e := setupReviewTest()
+	empty := ""
+	role := domain.UserRole("OWNER")
+	email := "User One <user1@example.com>"
+
+	_, err := e.userService.UpdateUser(e.ctx, userID1, service.UserUpdate{Username: &empty})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, err = e.userService.UpdateUser(e.ctx, userID1, service.UserUpdate{Email: &email})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	_, err = e.userService.UpdateUser(e.ctx, userID1, service.UserUpdate{Role: &role})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+	assert.Equal(t, testUser1, e.storage.Users[userID1])
+}
+
+func TestDeleteUserRefusesWithOpenReviews(t *testing.T) {
+	e := setupReviewTest()
+
+	_, err := e.userService.DeleteUser(e.ctx, userID1, false)
+
+	assert.ErrorIs(t, err, domain.ErrHasOpenReviews)
+	assert.False(t, e.storage.Users[userID1].IsDeleted())
+}
+
+func TestDeleteUserReassignsOpenReviews(t *testing.T) {
+	e := setupReviewTest()
+	replacement := domain.User{ID: "u-3", Username: "Replacement", TeamName: userTeamName, IsActive: true}
+	e.storage.Users[replacement.ID] = replacement
+
+	released, err := e.userService.DeleteUser(e.ctx, userID1, true)
+
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, prID1, released[0].PullRequestID)
+	assert.Equal(t, replacement.ID, released[0].ReplacedBy)
+	assert.Equal(t, []domain.UserID{userID1}, e.storage.PRs[prID2].AssignedReviewers)
+
+	_, err = e.userService.User(e.ctx, userID1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	list, err := e.userService.ListUsers(e.ctx, domain.UserFilter{})
+	require.NoError(t, err)
+	assert.NotContains(t, list.Users, e.storage.Users[userID1])
+	assert.Len(t, list.Users, 2)
+}
+
+// failingUserService works on the same storage as the environment, but
+// every change of reviewers fails.
+func (e testUserEnviroment) failingUserService() *service.UserService {
+	return service.NewUserService(e.userRepo, e.prRepo, failingReviewUnitOfWork{inmemory.NewUnitOfWork(e.storage)}, nil)
+}
+
+func TestDeleteUserKeepsUserWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupReviewTest()
+
+	_, err := e.failingUserService().DeleteUser(e.ctx, userID1, true)
+
+	require.ErrorIs(t, err, errReviewerChange)
+	assert.False(t, e.storage.Users[userID1].IsDeleted())
+	assert.Equal(t, []domain.UserID{userID1}, e.storage.PRs[prID1].AssignedReviewers)
+}
+
+func TestPatchUserDeactivatesAndReleasesReviews(t *testing.T) {
+	e := setupReviewTest()
+	replacement := domain.User{ID: "u-3", Username: "Replacement", TeamName: userTeamName, IsActive: true}
+	e.storage.Users[replacement.ID] = replacement
+	username := "renamed"
+	isActive := false
+
+	user, released, err := e.userService.PatchUser(e.ctx, userID1, service.UserPatch{
+		UserUpdate: service.UserUpdate{Username: &username},
+		IsActive:   &isActive,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", user.Username)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, []service.ReleasedReview{{PullRequestID: prID1, ReplacedBy: replacement.ID}}, released)
+}
+
+func TestPatchUserChangesNothingWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupReviewTest()
+	username := "renamed"
+	isActive := false
+
+	_, _, err := e.failingUserService().PatchUser(e.ctx, userID1, service.UserPatch{
+		UserUpdate: service.UserUpdate{Username: &username},
+		IsActive:   &isActive,
+	})
+
+	require.ErrorIs(t, err, errReviewerChange)
+	assert.Equal(t, testUser1, e.storage.Users[userID1])
+}
+
+func TestOffboardTransfersPullRequestsToSuccessor(t *testing.T) {
+	e := setupReviewTest()
+	replacement := domain.User{ID: "u-3", Username: "Replacement", TeamName: userTeamName, IsActive: true}
+	e.storage.Users[replacement.ID] = replacement
+
+	offboarding, err := e.userService.Offboard(e.ctx, userID1, userID2)
+
+	require.NoError(t, err)
+	assert.False(t, offboarding.User.IsActive)
+	assert.NotEqual(t, testUser1.Username, offboarding.User.Username)
+	assert.Equal(t, userID1, offboarding.User.ID)
+	assert.Equal(t, []domain.PullRequestID{prID3}, offboarding.TransferredPullRequests)
+	assert.Empty(t, offboarding.ClosedPullRequests)
+
+	assert.ElementsMatch(t, []service.ReleasedReview{
+		{PullRequestID: prID1, ReplacedBy: replacement.ID},
+		{PullRequestID: prID3, ReplacedBy: replacement.ID},
+	}, offboarding.ReleasedReviews)
+
+	assert.Equal(t, userID2, e.storage.PRs[prID3].AuthorID)
+	assert.Equal(t, []domain.UserID{replacement.ID}, e.storage.PRs[prID3].AssignedReviewers)
+	assert.Equal(t, []domain.UserID{userID1}, e.storage.PRs[prID2].AssignedReviewers)
+}
+
+func TestOffboardClosesPullRequestsWithoutSuccessor(t *testing.T) {
+	e := setupReviewTest()
+
+	offboarding, err := e.userService.Offboard(e.ctx, userID1, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PullRequestID{prID3}, offboarding.ClosedPullRequests)
+	assert.Equal(t, domain.StatusClosed, e.storage.PRs[prID3].Status)
+	assert.NotNil(t, e.storage.PRs[prID3].ClosedAt)
+	assert.Equal(t, userID1, e.storage.PRs[prID3].AuthorID)
+}
+
+func TestOffboardFailsOnUnknownSuccessor(t *testing.T) {
+	e := setupReviewTest()
+
+	_, err := e.userService.Offboard(e.ctx, userID1, "non-existent-user")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.True(t, e.storage.Users[userID1].IsActive)
+}
+
+func TestOffboardFailsOnInactiveSuccessor(t *testing.T) {
+	e := setupReviewTest()
+	successor := e.storage.Users[userID2]
+	successor.IsActive = false
+	e.storage.Users[userID2] = successor
+
+	_, err := e.userService.Offboard(e.ctx, userID1, userID2)
+
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+	assert.True(t, e.storage.Users[userID1].IsActive)
+	assert.Equal(t, userID1, e.storage.PRs[prID3].AuthorID)
+}
+
+func TestOffboardRollsBackWhenReviewsCanNotBeReleased(t *testing.T) {
+	e := setupReviewTest()
+
+	_, err := e.failingUserService().Offboard(e.ctx, userID1, "")
+
+	require.ErrorIs(t, err, errReviewerChange)
+	assert.Equal(t, testUser1, e.storage.Users[userID1])
+	assert.Equal(t, domain.StatusOpen, e.storage.PRs[prID3].Status)
+	assert.Equal(t, []domain.UserID{userID1}, e.storage.PRs[prID1].AssignedReviewers)
+}
+
+func TestCreateUserWithoutTeam(t *testing.T) {
+	e := setupUserTest()
+
+	user, err := e.userService.CreateUser(e.ctx, domain.User{ID: userID1, Username: "alice", FullName: "Alice", IsActive: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleMember, user.Role)
+	assert.Empty(t, user.TeamName)
+	assert.Empty(t, user.Teams)
+
+	_, err = e.userService.CreateUser(e.ctx, domain.User{ID: userID1, Username: "alice"})
+	assert.ErrorIs(t, err, domain.ErrUserExists)
+
+	_, err = e.userService.CreateUser(e.ctx, domain.User{ID: userID2})
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
+
+func TestFindUsersByUsername(t *testing.T) {
+	e := setupReviewTest()
+
+	users, err := e.userService.FindUsers(e.ctx, domain.UserFilter{Username: testUser2.Username, Limit: 1, After: "u-0"})
+
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, userID2, users[0].ID)
+}
+
+func TestDeactivateUserReleasesOpenReviews(t *testing.T) {
+	e := setupReviewTest()
+	replacement := domain.User{ID: "u-3", Username: "Replacement", TeamName: userTeamName, Teams: []domain.TeamName{userTeamName}, IsActive: true}
+	e.storage.Users[replacement.ID] = replacement
+
+	user, released, err := e.userService.DeactivateUser(e.ctx, userID1)
+
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, []service.ReleasedReview{{PullRequestID: prID1, ReplacedBy: replacement.ID}}, released)
+	assert.Equal(t, []domain.UserID{replacement.ID}, e.storage.PRs[prID1].AssignedReviewers)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// vcsProviders are the identity providers that are Git hosts.
+var vcsProviders = []domain.IdentityProvider{domain.IdentityGitHub, domain.IdentityGitLab}
+
+// VCSPullRequest is a pull request as a Git host reports it in its
+// webhooks. Author is the login of the author at the host, hosts whose
+// webhooks carry only the account ID of the author set AuthorAccountID
+// instead.
+type VCSPullRequest struct {
+	Provider        domain.IdentityProvider
+	Repository      string
+	Number          int
+	Title           string
+	Author          string
+	AuthorAccountID int64
+	Draft           bool
+}
+
+// ID is the ID the pull request is tracked under, e.g.
+// "github:acme/api#42".
+func (pr VCSPullRequest) ID() domain.PullRequestID {
+	return domain.PullRequestID(fmt.Sprintf("%s:%s#%d", pr.Provider, pr.Repository, pr.Number))
+}
+
+// ParseVCSPullRequestID is the reverse of VCSPullRequest.ID, false means the
+// pull request was not opened at a Git host.
+func ParseVCSPullRequestID(id domain.PullRequestID) (VCSPullRequest, bool) {
+	provider, rest, ok := strings.Cut(string(id), ":")
+	if !ok || !slices.Contains(vcsProviders, domain.IdentityProvider(provider)) {
+		return VCSPullRequest{}, false
+	}
+
+	i := strings.LastIndex(rest, "#")
+	if i <= 0 {
+		return VCSPullRequest{}, false
+	}
+
+	number, err := strconv.Atoi(rest[i+1:])
+	if err != nil || number <= 0 {
+		return VCSPullRequest{}, false
+	}
+
+	return VCSPullRequest{
+		Provider:   domain.IdentityProvider(provider),
+		Repository: rest[:i],
+		Number:     number,
+	}, true
+}
+
+// VCSClient requests reviews on pull requests at a Git host, reviewers are
+// given by their logins at the host. Requesting or removing a reviewer twice
+// is not an error.
+type VCSClient interface {
+	RequestReviewers(ctx context.Context, repository string, number int, logins []string) error
+	RemoveReviewers(ctx context.Context, repository string, number int, logins []string) error
+}
+
+// VCSAccountResolver looks up the login of an account at a Git host by its
+// numeric ID.
+type VCSAccountResolver interface {
+	Login(ctx context.Context, accountID int64) (string, error)
+}
+
+// VCSReviewRequest tells which reviewers to request and which to remove on
+// the pull request at its Git host.
+type VCSReviewRequest struct {
+	PullRequest VCSPullRequest
+	Request     []string
+	Remove      []string
+}
+
+// VCSOutcome tells what a Git host event did to the tracked pull request.
+type VCSOutcome string
+
+const (
+	VCSCreated  VCSOutcome = "CREATED"
+	VCSMerged   VCSOutcome = "MERGED"
+	VCSClosed   VCSOutcome = "CLOSED"
+	VCSReopened VCSOutcome = "REOPENED"
+	// VCSUnchanged is the outcome of events delivered again, or of events
+	// that catch up with a state the pull request already has.
+	VCSUnchanged VCSOutcome = "UNCHANGED"
+	// VCSIgnored is the outcome of events about pull requests that are not
+	// tracked, like drafts.
+	VCSIgnored VCSOutcome = "IGNORED"
+)
+
+type VCSResult struct {
+	Outcome     VCSOutcome
+	PullRequest domain.PullRequest
+}
+
+// VCSService keeps pull requests in step with the Git hosts. Hosts deliver
+// webhooks at least once, so every method can be called again with the same
+// pull request.
+type VCSService struct {
+	identityRepo repository.IdentityRepository
+	prRepo       repository.PullRequestRepository
+	prService    *PullRequestService
+	accounts     map[domain.IdentityProvider]VCSAccountResolver
+}
+
+// NewVCSService creates the service, accounts look up the authors of pull
+// requests from hosts that report only their account IDs.
+func NewVCSService(ir repository.IdentityRepository, prr repository.PullRequestRepository, prs *PullRequestService, accounts map[domain.IdentityProvider]VCSAccountResolver) *VCSService {
+	return &VCSService{
+		identityRepo: ir,
+		prRepo:       prr,
+		prService:    prs,
+		accounts:     accounts,
+	}
+}
+
+// Open starts tracking the pull request and assigns reviewers from the home
+// team of the author. Drafts are ignored until they are ready for review.
+func (s *VCSService) Open(ctx context.Context, vpr VCSPullRequest) (VCSResult, error) {
+	if vpr.Draft {
+		return VCSResult{Outcome: VCSIgnored}, nil
+	}
+
+	pr, err := s.prRepo.PullRequestByID(ctx, vpr.ID())
+	if err == nil {
+		return VCSResult{Outcome: VCSUnchanged, PullRequest: pr}, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return VCSResult{}, err
+	}
+
+	login, err := s.authorLogin(ctx, vpr)
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	authorID, err := s.identityRepo.UserIDByIdentity(ctx, vpr.Provider, domain.NormalizeExternalID(vpr.Provider, login))
+	if errors.Is(err, domain.ErrNotFound) {
+		return VCSResult{}, fmt.Errorf("%w: no user is linked to %s login %q", domain.ErrNotFound, vpr.Provider, login)
+	}
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	pr, err = s.prService.CreatePR(ctx, vpr.ID(), vpr.Title, authorID, "")
+	if errors.Is(err, domain.ErrPRExists) {
+		// a concurrent delivery of the same event won
+		pr, err = s.prRepo.PullRequestByID(ctx, vpr.ID())
+		if err != nil {
+			return VCSResult{}, err
+		}
+		return VCSResult{Outcome: VCSUnchanged, PullRequest: pr}, nil
+	}
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	return VCSResult{Outcome: VCSCreated, PullRequest: pr}, nil
+}
+
+// Merge marks the tracked pull request as merged.
+func (s *VCSService) Merge(ctx context.Context, vpr VCSPullRequest) (VCSResult, error) {
+	pr, err := s.prRepo.PullRequestByID(ctx, vpr.ID())
+	if errors.Is(err, domain.ErrNotFound) {
+		return VCSResult{Outcome: VCSIgnored}, nil
+	}
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	if pr.Status == domain.StatusMerged {
+		return VCSResult{Outcome: VCSUnchanged, PullRequest: pr}, nil
+	}
+
+	pr, err = s.prService.MergePR(ctx, pr.ID)
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	return VCSResult{Outcome: VCSMerged, PullRequest: pr}, nil
+}
+
+// Close closes the tracked pull request without merging it.
+func (s *VCSService) Close(ctx context.Context, vpr VCSPullRequest) (VCSResult, error) {
+	pr, err := s.prRepo.PullRequestByID(ctx, vpr.ID())
+	if errors.Is(err, domain.ErrNotFound) {
+		return VCSResult{Outcome: VCSIgnored}, nil
+	}
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	if pr.Status == domain.StatusClosed {
+		return VCSResult{Outcome: VCSUnchanged, PullRequest: pr}, nil
+	}
+
+	pr, err = s.prService.ClosePR(ctx, pr.ID)
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	return VCSResult{Outcome: VCSClosed, PullRequest: pr}, nil
+}
+
+// Reopen opens the tracked pull request again. Pull requests that were
+// closed before they got tracked are opened like new ones.
+func (s *VCSService) Reopen(ctx context.Context, vpr VCSPullRequest) (VCSResult, error) {
+	pr, err := s.prRepo.PullRequestByID(ctx, vpr.ID())
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.Open(ctx, vpr)
+	}
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	if pr.Status == domain.StatusOpen {
+		return VCSResult{Outcome: VCSUnchanged, PullRequest: pr}, nil
+	}
+
+	pr, err = s.prService.ReopenPR(ctx, pr.ID)
+	if err != nil {
+		return VCSResult{}, err
+	}
+
+	return VCSResult{Outcome: VCSReopened, PullRequest: pr}, nil
+}
+
+func (s *VCSService) authorLogin(ctx context.Context, vpr VCSPullRequest) (string, error) {
+	if vpr.Author != "" || vpr.AuthorAccountID == 0 {
+		return vpr.Author, nil
+	}
+
+	resolver, ok := s.accounts[vpr.Provider]
+	if !ok {
+		return "", fmt.Errorf("%w: can not look up %s account %d without a token for %s", domain.ErrNotFound, vpr.Provider, vpr.AuthorAccountID, vpr.Provider)
+	}
+
+	return resolver.Login(ctx, vpr.AuthorAccountID)
+}
+
+// reviewRequestEventKinds are the events that change who is requested to
+// review at the Git host.
+var reviewRequestEventKinds = []domain.PREventKind{
+	domain.EventReviewerAssigned,
+	domain.EventReviewerReassigned,
+	domain.EventReviewerRemoved,
+}
+
+// ReviewRequest translates the reviewers of a notification about a pull
+// request from a Git host into logins at the host, a dropped reviewer is
+// only removed. Reviewers without a linked login are left out, false means
+// there is nothing to request.
+func (s *VCSService) ReviewRequest(ctx context.Context, n Notification) (VCSReviewRequest, bool, error) {
+	if !slices.Contains(reviewRequestEventKinds, n.Kind) {
+		return VCSReviewRequest{}, false, nil
+	}
+
+	vpr, ok := ParseVCSPullRequestID(n.PullRequest.ID)
+	if !ok {
+		return VCSReviewRequest{}, false, nil
+	}
+
+	request, err := s.logins(ctx, vpr.Provider, n.Reviewers)
+	if err != nil {
+		return VCSReviewRequest{}, false, err
+	}
+
+	remove := []string{}
+	if n.PreviousReviewer != "" {
+		remove, err = s.logins(ctx, vpr.Provider, []domain.UserID{n.PreviousReviewer})
+		if err != nil {
+			return VCSReviewRequest{}, false, err
+		}
+	}
+
+	if len(request) == 0 && len(remove) == 0 {
+		return VCSReviewRequest{}, false, nil
+	}
+
+	return VCSReviewRequest{PullRequest: vpr, Request: request, Remove: remove}, true, nil
+}
+
+func (s *VCSService) logins(ctx context.Context, provider domain.IdentityProvider, userIDs []domain.UserID) ([]string, error) {
+	logins := []string{}
+	for _, userID := range userIDs {
+		identities, err := s.identityRepo.IdentitiesByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, identity := range identities {
+			if identity.Provider == provider {
+				logins = append(logins, identity.ExternalID)
+				break
+			}
+		}
+	}
+
+	return logins, nil
+}
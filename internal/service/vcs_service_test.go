@@ -0,0 +1,211 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testVCSEnviroment struct {
+	testPREnviroment
+
+	identityService *service.IdentityService
+	vcsService      *service.VCSService
+}
+
+func setupVCSTest(t *testing.T) testVCSEnviroment {
+	t.Helper()
+
+	e := setup()
+	_, err := e.teamService.CreateTeam(e.ctx, testTeam, false)
+	require.NoError(t, err)
+
+	identityRepo := inmemory.NewIdentityRepo(e.storage)
+	ve := testVCSEnviroment{
+		testPREnviroment: e,
+		identityService:  service.NewIdentityService(identityRepo, e.userRepo),
+		vcsService:       service.NewVCSService(identityRepo, e.prRepo, e.prService, nil),
+	}
+
+	_, err = ve.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: authorID, Provider: domain.IdentityGitHub, ExternalID: "Octo-Author"})
+	require.NoError(t, err)
+
+	return ve
+}
+
+var githubPR = service.VCSPullRequest{
+	Provider:   domain.IdentityGitHub,
+	Repository: "acme/api",
+	Number:     42,
+	Title:      "Add search",
+	Author:     "octo-author",
+}
+
+func TestVCSPullRequestID(t *testing.T) {
+	assert.Equal(t, domain.PullRequestID("github:acme/api#42"), githubPR.ID())
+}
+
+func TestParseVCSPullRequestID(t *testing.T) {
+	parsed, ok := service.ParseVCSPullRequestID("gitlab:platform/backend/api#7")
+	require.True(t, ok)
+	assert.Equal(t, service.VCSPullRequest{Provider: domain.IdentityGitLab, Repository: "platform/backend/api", Number: 7}, parsed)
+
+	for _, id := range []domain.PullRequestID{"pr-1", "slack:acme/api#1", "github:acme/api", "github:#1", "github:acme/api#x"} {
+		_, ok := service.ParseVCSPullRequestID(id)
+		assert.False(t, ok, id)
+	}
+}
+
+func TestVCSOpenCreatesPRForLinkedAuthor(t *testing.T) {
+	e := setupVCSTest(t)
+
+	result, err := e.vcsService.Open(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSCreated, result.Outcome)
+	assert.Equal(t, authorID, result.PullRequest.AuthorID)
+	assert.Equal(t, "Add search", result.PullRequest.Name)
+	assert.Len(t, result.PullRequest.AssignedReviewers, 2)
+
+	redelivered, err := e.vcsService.Open(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSUnchanged, redelivered.Outcome)
+	assert.Equal(t, result.PullRequest.AssignedReviewers, redelivered.PullRequest.AssignedReviewers)
+}
+
+func TestVCSOpenWaitsForDraftToBeReady(t *testing.T) {
+	e := setupVCSTest(t)
+
+	draft := githubPR
+	draft.Draft = true
+	result, err := e.vcsService.Open(e.ctx, draft)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSIgnored, result.Outcome)
+	assert.Empty(t, e.storage.PRs)
+
+	result, err = e.vcsService.Open(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSCreated, result.Outcome)
+}
+
+func TestVCSOpenFailsForUnlinkedAuthor(t *testing.T) {
+	e := setupVCSTest(t)
+
+	pr := githubPR
+	pr.Author = "stranger"
+	_, err := e.vcsService.Open(e.ctx, pr)
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Empty(t, e.storage.PRs)
+}
+
+type staticAccounts map[int64]string
+
+func (a staticAccounts) Login(_ context.Context, accountID int64) (string, error) {
+	return a[accountID], nil
+}
+
+func TestVCSOpenLooksUpAuthorByAccountID(t *testing.T) {
+	e := setupVCSTest(t)
+	identityRepo := inmemory.NewIdentityRepo(e.storage)
+	_, err := e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: authorID, Provider: domain.IdentityGitLab, ExternalID: "jdoe"})
+	require.NoError(t, err)
+
+	pr := service.VCSPullRequest{Provider: domain.IdentityGitLab, Repository: "platform/api", Number: 7, Title: "Add search", AuthorAccountID: 42}
+
+	// without a GitLab token the account can't be looked up
+	_, err = e.vcsService.Open(e.ctx, pr)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	accounts := map[domain.IdentityProvider]service.VCSAccountResolver{domain.IdentityGitLab: staticAccounts{42: "JDoe"}}
+	vcsService := service.NewVCSService(identityRepo, e.prRepo, e.prService, accounts)
+	result, err := vcsService.Open(e.ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, authorID, result.PullRequest.AuthorID)
+}
+
+func TestVCSMergeAndClose(t *testing.T) {
+	e := setupVCSTest(t)
+	_, err := e.vcsService.Open(e.ctx, githubPR)
+	require.NoError(t, err)
+
+	result, err := e.vcsService.Merge(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSMerged, result.Outcome)
+	assert.Equal(t, domain.StatusMerged, result.PullRequest.Status)
+
+	result, err = e.vcsService.Merge(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSUnchanged, result.Outcome)
+
+	abandoned := githubPR
+	abandoned.Number = 43
+	_, err = e.vcsService.Open(e.ctx, abandoned)
+	require.NoError(t, err)
+
+	result, err = e.vcsService.Close(e.ctx, abandoned)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSClosed, result.Outcome)
+	assert.Equal(t, domain.StatusClosed, result.PullRequest.Status)
+
+	result, err = e.vcsService.Close(e.ctx, abandoned)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSUnchanged, result.Outcome)
+}
+
+func TestVCSReopen(t *testing.T) {
+	e := setupVCSTest(t)
+	_, err := e.vcsService.Open(e.ctx, githubPR)
+	require.NoError(t, err)
+	_, err = e.vcsService.Close(e.ctx, githubPR)
+	require.NoError(t, err)
+
+	result, err := e.vcsService.Reopen(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSReopened, result.Outcome)
+	assert.Equal(t, domain.StatusOpen, result.PullRequest.Status)
+
+	result, err = e.vcsService.Reopen(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSUnchanged, result.Outcome)
+
+	// closed before the integration saw it
+	untracked := githubPR
+	untracked.Number = 43
+	result, err = e.vcsService.Reopen(e.ctx, untracked)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSCreated, result.Outcome)
+}
+
+func TestVCSProvidersTrackPRsApart(t *testing.T) {
+	e := setupVCSTest(t)
+	_, err := e.identityService.LinkIdentity(e.ctx, domain.UserIdentity{UserID: authorID, Provider: domain.IdentityGitLab, ExternalID: "author"})
+	require.NoError(t, err)
+
+	gitlabPR := githubPR
+	gitlabPR.Provider = domain.IdentityGitLab
+	gitlabPR.Author = "Author"
+
+	for _, pr := range []service.VCSPullRequest{githubPR, gitlabPR} {
+		result, err := e.vcsService.Open(e.ctx, pr)
+		require.NoError(t, err)
+		assert.Equal(t, service.VCSCreated, result.Outcome)
+	}
+	assert.Contains(t, e.storage.PRs, domain.PullRequestID("gitlab:acme/api#42"))
+}
+
+func TestVCSIgnoresUntrackedPR(t *testing.T) {
+	e := setupVCSTest(t)
+
+	result, err := e.vcsService.Merge(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSIgnored, result.Outcome)
+
+	result, err = e.vcsService.Close(e.ctx, githubPR)
+	require.NoError(t, err)
+	assert.Equal(t, service.VCSIgnored, result.Outcome)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"slices"
+)
+
+const webhookSecretBytes = 32
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+}
+
+func NewWebhookService(wr repository.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		webhookRepo: wr,
+	}
+}
+
+// CreateSubscription registers an endpoint for the given event types. A
+// secret is generated when none is given, it is only returned here.
+func (s *WebhookService) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	endpoint, err := url.Parse(subscription.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return domain.WebhookSubscription{}, fmt.Errorf("%w: url must be an absolute http(s) url", domain.ErrInvalidArgument)
+	}
+
+	if len(subscription.EventTypes) == 0 {
+		return domain.WebhookSubscription{}, fmt.Errorf("%w: at least one event type is required", domain.ErrInvalidArgument)
+	}
+
+	eventTypes := make([]domain.PREventKind, 0, len(subscription.EventTypes))
+	for _, kind := range subscription.EventTypes {
+		if !slices.Contains(domain.PREventKinds, kind) {
+			return domain.WebhookSubscription{}, fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidArgument, kind)
+		}
+		if !slices.Contains(eventTypes, kind) {
+			eventTypes = append(eventTypes, kind)
+		}
+	}
+	subscription.EventTypes = eventTypes
+
+	if subscription.Secret == "" {
+		secret := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+
+	return s.webhookRepo.CreateSubscription(ctx, subscription)
+}
+
+func (s *WebhookService) Subscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.Subscriptions(ctx)
+}
+
+// DeleteSubscription removes the subscription together with its pending and
+// dead deliveries.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	return s.webhookRepo.DeleteSubscription(ctx, subscriptionID)
+}
+
+// DeadDeliveries lists deliveries that ran out of attempts, a zero
+// subscription ID lists those of all subscriptions.
+func (s *WebhookService) DeadDeliveries(ctx context.Context, subscriptionID int64) ([]domain.WebhookDelivery, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.DeadDeliveries(ctx, subscriptionID)
+}
+
+// Redeliver queues a dead delivery again with a fresh set of attempts.
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID int64) (domain.WebhookDelivery, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	return s.webhookRepo.Redeliver(ctx, deliveryID)
+}
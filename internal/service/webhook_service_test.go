@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookTest() (context.Context, *service.WebhookService) {
+	storage, _ := inmemory.NewStorage()
+
+	return context.Background(), service.NewWebhookService(inmemory.NewWebhookRepo(storage))
+}
+
+func TestCreateWebhookGeneratesSecret(t *testing.T) {
+	ctx, webhookService := setupWebhookTest()
+
+	subscription, err := webhookService.CreateSubscription(ctx, domain.WebhookSubscription{
+		URL:        "https://hooks.example.com/reviews",
+		EventTypes: []domain.PREventKind{domain.EventReviewerAssigned, domain.EventReviewerAssigned, domain.EventMerged},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, subscription.Secret, 64)
+	assert.Equal(t, []domain.PREventKind{domain.EventReviewerAssigned, domain.EventMerged}, subscription.EventTypes)
+}
+
+func TestCreateWebhookValidatesInput(t *testing.T) {
+	ctx, webhookService := setupWebhookTest()
+
+	tests := []domain.WebhookSubscription{
+		{URL: "hooks.example.com", EventTypes: []domain.PREventKind{domain.EventMerged}},
+		{URL: "ftp://hooks.example.com", EventTypes: []domain.PREventKind{domain.EventMerged}},
+		{URL: "https://hooks.example.com"},
+		{URL: "https://hooks.example.com", EventTypes: []domain.PREventKind{"DEPLOYED"}},
+	}
+
+	for _, subscription := range tests {
+		_, err := webhookService.CreateSubscription(ctx, subscription)
+		assert.ErrorIs(t, err, domain.ErrInvalidArgument, subscription)
+	}
+
+	subscriptions, err := webhookService.Subscriptions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
@@ -0,0 +1,390 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/orgspec"
+	"pr-reviewer-service/internal/service"
+	"strconv"
+	"time"
+)
+
+const (
+	maxOrgSpecBytes = 4 << 20
+
+	// exportWriteTimeout replaces the write timeout of the server for the
+	// export, which streams the whole storage.
+	exportWriteTimeout = 30 * time.Minute
+
+	// the import uploads and restores the whole storage, stateImportTimeout
+	// replaces both the read and write timeouts of the server for it
+	maxStateImportBytes = maxIdempotentRequestBytes
+	stateImportTimeout  = 30 * time.Minute
+)
+
+type orgChangeDTO struct {
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	TeamName    *string `json:"team_name,omitempty"`
+	UserID      *string `json:"user_id,omitempty"`
+}
+
+type orgSyncResponse struct {
+	Applied         bool                `json:"applied"`
+	Changes         []orgChangeDTO      `json:"changes"`
+	ReleasedReviews []releasedReviewDTO `json:"released_reviews"`
+}
+
+func newOrgSyncResponse(sync service.OrgSync) orgSyncResponse {
+	changes := make([]orgChangeDTO, len(sync.Changes))
+	for i, change := range sync.Changes {
+		changes[i] = orgChangeDTO{Kind: string(change.Kind), Description: change.String()}
+		if change.TeamName != "" {
+			teamName := string(change.TeamName)
+			changes[i].TeamName = &teamName
+		}
+		if change.User.ID != "" {
+			userID := string(change.User.ID)
+			changes[i].UserID = &userID
+		}
+	}
+
+	return orgSyncResponse{
+		Applied:         sync.Applied,
+		Changes:         changes,
+		ReleasedReviews: newReleasedReviewDTOs(sync.ReleasedReviews),
+	}
+}
+
+func (h *Handler) handleOrgSync(w http.ResponseWriter, r *http.Request) {
+	plan := false
+	if raw := r.URL.Query().Get("plan"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			apiErr := APIError{Code: "BAD_REQUEST", Message: "'plan' must be a boolean"}
+			h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+			return
+		}
+		plan = parsed
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxOrgSpecBytes))
+	if err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid request body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	spec, err := orgspec.Parse(body)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	sync, err := h.orgSyncService.Sync(r.Context(), spec, plan)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, newOrgSyncResponse(sync))
+}
+
+var stateSections = []string{"teams", "users", "pull_requests"}
+
+type stateDocument struct {
+	Version      int                   `json:"version"`
+	ExportedAt   time.Time             `json:"exported_at"`
+	Teams        []stateTeamDTO        `json:"teams"`
+	Users        []stateUserDTO        `json:"users"`
+	PullRequests []statePullRequestDTO `json:"pull_requests"`
+}
+
+type stateTeamDTO struct {
+	TeamName       string     `json:"team_name"`
+	ParentTeamName string     `json:"parent_team_name,omitempty"`
+	ArchivedAt     *time.Time `json:"archived_at,omitempty"`
+}
+
+type stateUserDTO struct {
+	UserID    string     `json:"user_id"`
+	Username  string     `json:"username"`
+	FullName  string     `json:"full_name,omitempty"`
+	Email     string     `json:"email,omitempty"`
+	Role      string     `json:"role"`
+	TeamName  string     `json:"team_name,omitempty"`
+	Teams     []string   `json:"teams"`
+	IsActive  bool       `json:"is_active"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type statePullRequestDTO struct {
+	PullRequestID     string            `json:"pull_request_id"`
+	PullRequestName   string            `json:"pull_request_name"`
+	AuthorID          string            `json:"author_id"`
+	TeamName          string            `json:"team_name,omitempty"`
+	Status            string            `json:"status"`
+	AssignedReviewers []string          `json:"assigned_reviewers"`
+	ReviewStates      map[string]string `json:"review_states"`
+	CreatedAt         time.Time         `json:"created_at"`
+	MergedAt          *time.Time        `json:"merged_at,omitempty"`
+	ClosedAt          *time.Time        `json:"closed_at,omitempty"`
+}
+
+type stateImportResponse struct {
+	Teams        int `json:"teams"`
+	Users        int `json:"users"`
+	PullRequests int `json:"pull_requests"`
+}
+
+// stateJSONWriter streams an export as a single JSON document, opening the
+// next section as soon as the first item of it arrives. Nothing is written
+// before the first item or close, so the status can still be chosen until
+// then.
+type stateJSONWriter struct {
+	w          io.Writer
+	enc        *json.Encoder
+	exportedAt time.Time
+	section    int
+	written    int
+}
+
+func newStateJSONWriter(w io.Writer, exportedAt time.Time) *stateJSONWriter {
+	return &stateJSONWriter{w: w, enc: json.NewEncoder(w), exportedAt: exportedAt, section: -1}
+}
+
+// started reports whether any part of the document was written.
+func (sw *stateJSONWriter) started() bool {
+	return sw.section >= 0
+}
+
+func (sw *stateJSONWriter) WriteTeam(team domain.Team) error {
+	return sw.write(0, stateTeamDTO{
+		TeamName:       string(team.Name),
+		ParentTeamName: string(team.ParentName),
+		ArchivedAt:     team.ArchivedAt,
+	})
+}
+
+func (sw *stateJSONWriter) WriteUser(user domain.User) error {
+	teams := make([]string, len(user.Teams))
+	for i, t := range user.Teams {
+		teams[i] = string(t)
+	}
+
+	return sw.write(1, stateUserDTO{
+		UserID:    string(user.ID),
+		Username:  user.Username,
+		FullName:  user.FullName,
+		Email:     user.Email,
+		Role:      string(user.Role),
+		TeamName:  string(user.TeamName),
+		Teams:     teams,
+		IsActive:  user.IsActive,
+		DeletedAt: user.DeletedAt,
+	})
+}
+
+func (sw *stateJSONWriter) WritePullRequest(pr domain.PullRequest) error {
+	reviewStates := make(map[string]string, len(pr.ReviewStates))
+	for reviewerID, state := range pr.ReviewStates {
+		reviewStates[string(reviewerID)] = string(state)
+	}
+
+	return sw.write(2, statePullRequestDTO{
+		PullRequestID:     string(pr.ID),
+		PullRequestName:   pr.Name,
+		AuthorID:          string(pr.AuthorID),
+		TeamName:          string(pr.TeamName),
+		Status:            string(pr.Status),
+		AssignedReviewers: userIDsToStrings(pr.AssignedReviewers),
+		ReviewStates:      reviewStates,
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+		ClosedAt:          pr.ClosedAt,
+	})
+}
+
+func (sw *stateJSONWriter) write(section int, item any) error {
+	for sw.section < section {
+		if err := sw.nextSection(); err != nil {
+			return err
+		}
+	}
+
+	if sw.written > 0 {
+		if _, err := io.WriteString(sw.w, ","); err != nil {
+			return err
+		}
+	}
+	sw.written++
+
+	return sw.enc.Encode(item)
+}
+
+func (sw *stateJSONWriter) nextSection() error {
+	if sw.started() {
+		if _, err := io.WriteString(sw.w, "],"); err != nil {
+			return err
+		}
+	} else {
+		header, err := json.Marshal(sw.exportedAt)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(sw.w, `{"version":%d,"exported_at":%s,`, service.StateVersion, header); err != nil {
+			return err
+		}
+	}
+
+	sw.section++
+	sw.written = 0
+	_, err := fmt.Fprintf(sw.w, `"%s":[`, stateSections[sw.section])
+	return err
+}
+
+func (sw *stateJSONWriter) close() error {
+	for sw.section < len(stateSections)-1 {
+		if err := sw.nextSection(); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(sw.w, "]}\n")
+	return err
+}
+
+func (d stateDocument) state() service.State {
+	state := service.State{
+		Version:      d.Version,
+		ExportedAt:   d.ExportedAt,
+		Teams:        make([]domain.Team, len(d.Teams)),
+		Users:        make([]domain.User, len(d.Users)),
+		PullRequests: make([]domain.PullRequest, len(d.PullRequests)),
+	}
+
+	for i, team := range d.Teams {
+		state.Teams[i] = domain.Team{
+			Name:       domain.TeamName(team.TeamName),
+			ParentName: domain.TeamName(team.ParentTeamName),
+			ArchivedAt: team.ArchivedAt,
+		}
+	}
+
+	for i, user := range d.Users {
+		teams := make([]domain.TeamName, len(user.Teams))
+		for j, teamName := range user.Teams {
+			teams[j] = domain.TeamName(teamName)
+		}
+
+		state.Users[i] = domain.User{
+			ID:        domain.UserID(user.UserID),
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Role:      domain.UserRole(user.Role),
+			TeamName:  domain.TeamName(user.TeamName),
+			Teams:     teams,
+			IsActive:  user.IsActive,
+			DeletedAt: user.DeletedAt,
+		}
+	}
+
+	for i, pr := range d.PullRequests {
+		reviewers := make([]domain.UserID, len(pr.AssignedReviewers))
+		for j, reviewerID := range pr.AssignedReviewers {
+			reviewers[j] = domain.UserID(reviewerID)
+		}
+
+		reviewStates := make(map[domain.UserID]domain.ReviewState, len(pr.ReviewStates))
+		for reviewerID, state := range pr.ReviewStates {
+			reviewStates[domain.UserID(reviewerID)] = domain.ReviewState(state)
+		}
+
+		state.PullRequests[i] = domain.PullRequest{
+			ID:                domain.PullRequestID(pr.PullRequestID),
+			Name:              pr.PullRequestName,
+			AuthorID:          domain.UserID(pr.AuthorID),
+			TeamName:          domain.TeamName(pr.TeamName),
+			Status:            domain.PRStatus(pr.Status),
+			AssignedReviewers: reviewers,
+			ReviewStates:      reviewStates,
+			CreatedAt:         pr.CreatedAt,
+			MergedAt:          pr.MergedAt,
+			ClosedAt:          pr.ClosedAt,
+		}
+	}
+
+	return state
+}
+
+// handleExportState streams the export. Errors before the first item get an
+// error response, once the first bytes are sent the status can no longer
+// change and a failure leaves the document truncated.
+func (h *Handler) handleExportState(w http.ResponseWriter, r *http.Request) {
+	// the export takes longer than the server allows for regular responses
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to extend export write deadline", "error", err)
+	}
+
+	sw := newStateJSONWriter(w, time.Now().UTC())
+	w.Header().Set("Content-Disposition", `attachment; filename="pr-reviewer-export.json"`)
+
+	if err := h.stateService.Export(r.Context(), sw); err != nil {
+		if !sw.started() {
+			w.Header().Del("Content-Disposition")
+			h.respondError(w, r, err)
+			return
+		}
+
+		h.logger.ErrorContext(r.Context(), "failed to write export", "error", err)
+		return
+	}
+
+	if err := sw.close(); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write export", "error", err)
+	}
+}
+
+// withStateImportDeadlines gives the import more time than the server allows
+// for regular requests. It has to run before idempotent, which reads the
+// body already.
+func (h *Handler) withStateImportDeadlines(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		deadline := time.Now().Add(stateImportTimeout)
+		if err := rc.SetReadDeadline(deadline); err != nil {
+			h.logger.WarnContext(r.Context(), "failed to extend import read deadline", "error", err)
+		}
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			h.logger.WarnContext(r.Context(), "failed to extend import write deadline", "error", err)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) handleImportState(w http.ResponseWriter, r *http.Request) {
+	var doc stateDocument
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStateImportBytes)).Decode(&doc); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	imported, err := h.stateService.Import(r.Context(), doc.state())
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusCreated, stateImportResponse{
+		Teams:        imported.Teams,
+		Users:        imported.Users,
+		PullRequests: imported.PullRequests,
+	})
+}
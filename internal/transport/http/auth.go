@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"strings"
+)
+
+// AuthConfig tells whether requests must carry a token, without it the API
+// is open to anyone who can reach it.
+type AuthConfig struct {
+	Required bool
+	// Verifier checks the JWTs of signed-in users, without it only API
+	// tokens are accepted.
+	Verifier TokenVerifier
+}
+
+// TokenVerifier maps a valid JWT onto the user it was issued to.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (domain.Principal, error)
+}
+
+type requestAuthKey struct{}
+
+// requestAuth is the token or user a request was made with. NewSlogLogger
+// puts it into the context empty and logs it once the auth middleware, which
+// runs later, filled it in.
+type requestAuth struct {
+	token     *domain.APIToken
+	principal *domain.Principal
+}
+
+func withRequestAuth(ctx context.Context) (context.Context, *requestAuth) {
+	auth := &requestAuth{}
+	return context.WithValue(ctx, requestAuthKey{}, auth), auth
+}
+
+func requestAuthFromContext(ctx context.Context) *requestAuth {
+	auth, _ := ctx.Value(requestAuthKey{}).(*requestAuth)
+	return auth
+}
+
+// requireScopes lets a request through when its token has the read scope
+// for GET and HEAD requests and the write scope for the others.
+//
+// The bearer token is either an API token or, with a verifier, the JWT of a
+// signed-in user. Requests with an API token are made for the token, with
+// the actor header as what the client claims, requests with a JWT always
+// for the user. Users have every scope but admin, which only admins have,
+// and their role is the one stored in the directory. The services check the
+// token again for admin work, so a teams:write token can't, say, delete
+// users.
+func (h *Handler) requireScopes(read, write domain.TokenScope) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !h.auth.Required {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			secret, ok := bearerToken(r)
+			if !ok {
+				h.respondUnauthorized(w, r, domain.ErrUnauthorized)
+				return
+			}
+
+			scope := write
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				scope = read
+			}
+
+			ctx := r.Context()
+			auth := requestAuthFromContext(ctx)
+			if auth == nil {
+				auth = &requestAuth{}
+			}
+
+			var allowed bool
+			if h.auth.Verifier != nil && isJWT(secret) {
+				principal, err := h.auth.Verifier.Verify(ctx, secret)
+				if errors.Is(err, domain.ErrUnauthorized) {
+					h.respondUnauthorized(w, r, err)
+					return
+				}
+				if err == nil {
+					principal, err = h.userService.Principal(ctx, principal)
+				}
+				if err != nil {
+					h.respondError(w, r, err)
+					return
+				}
+
+				auth.principal = &principal
+				allowed = scope != domain.ScopeAdmin || principal.HasRole(domain.RoleAdmin)
+				ctx = domain.WithActor(domain.WithPrincipal(ctx, principal), string(principal.UserID))
+			} else {
+				token, err := h.tokenService.Authenticate(ctx, secret)
+				if errors.Is(err, domain.ErrUnauthorized) {
+					h.respondUnauthorized(w, r, err)
+					return
+				}
+				if err != nil {
+					h.respondError(w, r, err)
+					return
+				}
+
+				auth.token = &token
+				allowed = token.Allows(scope)
+				ctx = domain.WithAPIToken(ctx, token)
+				actor := "token:" + token.Name
+				if claimed := claimedActor(r); claimed != "" {
+					actor += "/" + claimed
+				}
+				ctx = domain.WithActor(ctx, actor)
+			}
+
+			if !allowed {
+				h.respondError(w, r, fmt.Errorf("%w: the %q scope is required", domain.ErrForbidden, scope))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireScope is requireScopes with one scope for every method.
+func (h *Handler) requireScope(scope domain.TokenScope) func(next http.Handler) http.Handler {
+	return h.requireScopes(scope, scope)
+}
+
+func (h *Handler) respondUnauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="pr-reviewer"`)
+	h.respondError(w, r, err)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// isJWT tells JWTs apart from API token secrets, which have no dots.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+)
+
+type setChatChannelRequest struct {
+	TeamName   string            `json:"team_name"`
+	WebhookURL string            `json:"webhook_url"`
+	Templates  map[string]string `json:"templates"`
+}
+
+type removeChatChannelRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type chatChannelDTO struct {
+	TeamName   string            `json:"team_name"`
+	WebhookURL string            `json:"webhook_url"`
+	Templates  map[string]string `json:"templates"`
+}
+
+type chatChannelResponse struct {
+	Channel chatChannelDTO `json:"channel"`
+}
+
+func newChatChannelDTO(channel domain.ChatChannel) chatChannelDTO {
+	templates := make(map[string]string, len(channel.Templates))
+	for kind, text := range channel.Templates {
+		templates[string(kind)] = text
+	}
+
+	return chatChannelDTO{
+		TeamName:   string(channel.TeamName),
+		WebhookURL: channel.WebhookURL,
+		Templates:  templates,
+	}
+}
+
+func (h *Handler) handleSetChatChannel(w http.ResponseWriter, r *http.Request) {
+	var req setChatChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	templates := make(map[domain.PREventKind]string, len(req.Templates))
+	for kind, text := range req.Templates {
+		templates[domain.PREventKind(kind)] = text
+	}
+
+	channel, err := h.chatService.SetChannel(r.Context(), domain.ChatChannel{
+		TeamName:   domain.TeamName(req.TeamName),
+		WebhookURL: req.WebhookURL,
+		Templates:  templates,
+	})
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, chatChannelResponse{Channel: newChatChannelDTO(channel)})
+}
+
+func (h *Handler) handleGetChatChannel(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "missing required 'team_name' query parameter"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	channel, err := h.chatService.Channel(r.Context(), domain.TeamName(teamName))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, chatChannelResponse{Channel: newChatChannelDTO(channel)})
+}
+
+func (h *Handler) handleRemoveChatChannel(w http.ResponseWriter, r *http.Request) {
+	var req removeChatChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	if err := h.chatService.RemoveChannel(r.Context(), domain.TeamName(req.TeamName)); err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
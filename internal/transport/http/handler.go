@@ -15,6 +15,7 @@ import (
 type APIError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -22,18 +23,45 @@ type ErrorResponse struct {
 }
 
 type Handler struct {
-	teamService *service.TeamService
-	userService *service.UserService
-	prService   *service.PullRequestService
-	logger      *slog.Logger
+	teamService        *service.TeamService
+	userService        *service.UserService
+	prService          *service.PullRequestService
+	orgSyncService     *service.OrgSyncService
+	stateService       *service.StateService
+	webhookService     *service.WebhookService
+	chatService        *service.ChatService
+	identityService    *service.IdentityService
+	vcsService         *service.VCSService
+	tokenService       *service.TokenService
+	idempotencyService *service.IdempotencyService
+	integrations       IntegrationConfig
+	auth               AuthConfig
+	logger             *slog.Logger
 }
 
-func NewHandler(ts *service.TeamService, us *service.UserService, prs *service.PullRequestService, logger *slog.Logger) *Handler {
+// IntegrationConfig holds the secrets Git hosts authenticate their webhooks
+// with, an integration without a secret is disabled.
+type IntegrationConfig struct {
+	GitHubWebhookSecret string
+	GitLabWebhookToken  string
+}
+
+func NewHandler(ts *service.TeamService, us *service.UserService, prs *service.PullRequestService, oss *service.OrgSyncService, ss *service.StateService, ws *service.WebhookService, cs *service.ChatService, is *service.IdentityService, vs *service.VCSService, tks *service.TokenService, ids *service.IdempotencyService, ic IntegrationConfig, ac AuthConfig, logger *slog.Logger) *Handler {
 	return &Handler{
-		teamService: ts,
-		userService: us,
-		prService:   prs,
-		logger:      logger,
+		teamService:        ts,
+		userService:        us,
+		prService:          prs,
+		orgSyncService:     oss,
+		stateService:       ss,
+		webhookService:     ws,
+		chatService:        cs,
+		identityService:    is,
+		vcsService:         vs,
+		tokenService:       tks,
+		idempotencyService: ids,
+		integrations:       ic,
+		auth:               ac,
+		logger:             logger,
 	}
 }
 
@@ -46,37 +74,88 @@ func (h *Handler) respondJSON(w http.ResponseWriter, r *http.Request, status int
 }
 
 func (h *Handler) respondError(w http.ResponseWriter, r *http.Request, err error) {
+	status, apiErr := errorResponse(err)
+	if status == http.StatusInternalServerError {
+		h.logger.ErrorContext(r.Context(), "http server error", "error", err)
+	}
+
+	h.respondJSON(w, r, status, ErrorResponse{Error: apiErr})
+}
+
+// errorResponse maps a service error onto the HTTP status and API error code.
+func errorResponse(err error) (int, APIError) {
 	status := http.StatusInternalServerError
 	apiErr := APIError{
 		Code:    "INTERNAL_ERROR",
 		Message: "unknown error",
 	}
 
-	if errors.Is(err, domain.ErrNotFound) {
+	var teamConflictErr *domain.TeamConflictError
+
+	if errors.As(err, &teamConflictErr) {
+		status = http.StatusConflict
+		apiErr = APIError{
+			Code:    "USER_IN_OTHER_TEAM",
+			Message: err.Error(),
+			Details: map[string]any{"conflicts": newMemberConflictDTOs(teamConflictErr.Conflicts)},
+		}
+	} else if errors.Is(err, domain.ErrNotFound) {
 		status = http.StatusNotFound
 		apiErr = APIError{Code: "NOT_FOUND", Message: err.Error()}
 	} else if errors.Is(err, domain.ErrTeamExists) {
 		status = http.StatusBadRequest
 		apiErr = APIError{Code: "TEAM_EXISTS", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrUserExists) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "USER_EXISTS", Message: err.Error()}
 	} else if errors.Is(err, domain.ErrPRExists) {
 		status = http.StatusConflict
 		apiErr = APIError{Code: "PR_EXISTS", Message: err.Error()}
 	} else if errors.Is(err, domain.ErrPRMerged) {
 		status = http.StatusConflict
 		apiErr = APIError{Code: "PR_MERGED", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrPRClosed) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "PR_CLOSED", Message: err.Error()}
 	} else if errors.Is(err, domain.ErrNotAssigned) {
 		status = http.StatusConflict
 		apiErr = APIError{Code: "NOT_ASSIGNED", Message: err.Error()}
 	} else if errors.Is(err, domain.ErrNoCandidate) {
 		status = http.StatusConflict
 		apiErr = APIError{Code: "NO_CANDIDATE", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrUserInOtherTeam) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "USER_IN_OTHER_TEAM", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrTeamArchived) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "TEAM_ARCHIVED", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrTeamNotEmpty) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "TEAM_NOT_EMPTY", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrHasOpenReviews) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "HAS_OPEN_REVIEWS", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrStorageNotEmpty) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "STORAGE_NOT_EMPTY", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrUnauthorized) {
+		status = http.StatusUnauthorized
+		apiErr = APIError{Code: "UNAUTHORIZED", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrForbidden) {
+		status = http.StatusForbidden
+		apiErr = APIError{Code: "FORBIDDEN", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrKeyReused) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "IDEMPOTENCY_KEY_REUSED", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrKeyInProgress) {
+		status = http.StatusConflict
+		apiErr = APIError{Code: "IDEMPOTENCY_KEY_IN_PROGRESS", Message: err.Error()}
+	} else if errors.Is(err, domain.ErrInvalidArgument) {
+		status = http.StatusBadRequest
+		apiErr = APIError{Code: "BAD_REQUEST", Message: err.Error()}
 	}
 
-	if status == http.StatusInternalServerError {
-		h.logger.ErrorContext(r.Context(), "http server error", "error", err)
-	}
-
-	h.respondJSON(w, r, status, ErrorResponse{Error: apiErr})
+	return status, apiErr
 }
 
 func NewSlogLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
@@ -85,18 +164,50 @@ func NewSlogLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 			t1 := time.Now()
 
-			next.ServeHTTP(ww, r)
+			ctx, auth := withRequestAuth(r.Context())
+			next.ServeHTTP(ww, r.WithContext(ctx))
 
-			logger.InfoContext(r.Context(), "request served",
+			attrs := []any{
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", ww.Status(),
 				"duration_ms", time.Since(t1).Milliseconds(),
 				"bytes_written", ww.BytesWritten(),
 				"request_id", middleware.GetReqID(r.Context()),
-			)
+			}
+			if auth.token != nil {
+				attrs = append(attrs, "token_id", auth.token.ID, "token_name", auth.token.Name)
+			}
+			if auth.principal != nil {
+				attrs = append(attrs, "user_id", auth.principal.UserID)
+			}
+
+			logger.InfoContext(r.Context(), "request served", attrs...)
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+// actorHeader names the person or system a request is made for, the actor
+// is recorded in the pull request timeline. Nothing backs the header, so it
+// is recorded as a claim, and requests of signed-in users ignore it.
+const actorHeader = "X-Actor"
+
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := claimedActor(r); actor != "" {
+			r = r.WithContext(domain.WithActor(r.Context(), actor))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func claimedActor(r *http.Request) string {
+	if actor := r.Header.Get(actorHeader); actor != "" {
+		return "claimed:" + actor
+	}
+
+	return ""
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 25 << 20
+)
+
+// idempotent serves POST requests with an Idempotency-Key header once. A
+// retry with the same key and body gets the stored response, a retry with
+// another body or one that arrives while the first is in progress a
+// conflict. Keys are per token or signed-in user, so clients can't collide.
+func (h *Handler) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			apiErr := APIError{Code: "BAD_REQUEST", Message: fmt.Sprintf("'%s' must not be longer than %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)}
+			h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid request body"}
+			h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		key = idempotencyScope(ctx) + key
+
+		stored, replay, err := h.idempotencyService.Begin(ctx, key, requestHash(r, body))
+		if err != nil {
+			h.respondError(w, r, err)
+			return
+		}
+
+		if replay {
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(stored.StatusCode)
+			if _, err := w.Write(stored.Body); err != nil {
+				h.logger.ErrorContext(ctx, "failed to replay idempotent response", "error", err)
+			}
+			return
+		}
+
+		// the key is released when the handler panics, the client may retry
+		completed := false
+		defer func() {
+			if !completed {
+				if err := h.idempotencyService.Release(context.WithoutCancel(ctx), key); err != nil {
+					h.logger.ErrorContext(ctx, "failed to release idempotency key", "error", err)
+				}
+			}
+		}()
+
+		var response bytes.Buffer
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&response)
+
+		next.ServeHTTP(ww, r)
+		completed = true
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if err := h.idempotencyService.Complete(context.WithoutCancel(ctx), key, status, response.Bytes()); err != nil {
+			h.logger.ErrorContext(ctx, "failed to store idempotent response", "error", err)
+		}
+	})
+}
+
+// idempotencyScope prefixes keys with who made the request.
+func idempotencyScope(ctx context.Context) string {
+	auth := requestAuthFromContext(ctx)
+	switch {
+	case auth == nil:
+		return ""
+	case auth.principal != nil:
+		return "user:" + string(auth.principal.UserID) + "/"
+	case auth.token != nil:
+		return fmt.Sprintf("token:%d/", auth.token.ID)
+	}
+
+	return ""
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s?%s\n", r.Method, r.URL.Path, r.URL.RawQuery)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
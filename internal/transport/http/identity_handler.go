@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+)
+
+type linkIdentityRequest struct {
+	UserID     string `json:"user_id"`
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id"`
+}
+
+type identityDTO struct {
+	UserID     string `json:"user_id"`
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id"`
+}
+
+type identityResponse struct {
+	Identity identityDTO `json:"identity"`
+}
+
+func newIdentityDTO(identity domain.UserIdentity) identityDTO {
+	return identityDTO{
+		UserID:     string(identity.UserID),
+		Provider:   string(identity.Provider),
+		ExternalID: identity.ExternalID,
+	}
+}
+
+func (h *Handler) handleLinkIdentity(w http.ResponseWriter, r *http.Request) {
+	var req linkIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	identity, err := h.identityService.LinkIdentity(r.Context(), domain.UserIdentity{
+		UserID:     domain.UserID(req.UserID),
+		Provider:   domain.IdentityProvider(req.Provider),
+		ExternalID: req.ExternalID,
+	})
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, identityResponse{Identity: newIdentityDTO(identity)})
+}
+
+type unlinkIdentityRequest struct {
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id"`
+}
+
+type identitiesResponse struct {
+	UserID     string        `json:"user_id"`
+	Identities []identityDTO `json:"identities"`
+}
+
+func (h *Handler) handleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	var req unlinkIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	if err := h.identityService.UnlinkIdentity(r.Context(), domain.IdentityProvider(req.Provider), req.ExternalID); err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleListIdentities(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "missing required 'user_id' query parameter"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	identities, err := h.identityService.Identities(r.Context(), domain.UserID(userID))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	dtos := make([]identityDTO, len(identities))
+	for i, identity := range identities {
+		dtos[i] = newIdentityDTO(identity)
+	}
+
+	h.respondJSON(w, r, http.StatusOK, identitiesResponse{UserID: userID, Identities: dtos})
+}
+
+func (h *Handler) handleGetUserByIdentity(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for _, param := range []string{"provider", "external_id"} {
+		if query.Get(param) == "" {
+			apiErr := APIError{Code: "BAD_REQUEST", Message: "missing required '" + param + "' query parameter"}
+			h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+			return
+		}
+	}
+
+	user, err := h.identityService.UserByIdentity(r.Context(), domain.IdentityProvider(query.Get("provider")), query.Get("external_id"))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, getUserResponse{User: newUserResponse(user)})
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"pr-reviewer-service/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const statusAll = "ALL"
+
+func encodeCursor(cursor *domain.PullRequestCursor) *string {
+	if cursor == nil {
+		return nil
+	}
+
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + string(cursor.ID)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(raw))
+
+	return &encoded
+}
+
+func decodeCursor(encoded string) (*domain.PullRequestCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidArgument)
+	}
+
+	createdAtRaw, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidArgument)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidArgument)
+	}
+
+	return &domain.PullRequestCursor{CreatedAt: createdAt, ID: domain.PullRequestID(id)}, nil
+}
+
+// parsePullRequestFilter reads the status, team_name, sort, limit and cursor
+// query parameters. Missing parameters are left empty so the service applies its defaults.
+func parsePullRequestFilter(query url.Values) (domain.PullRequestFilter, error) {
+	filter := domain.PullRequestFilter{
+		TeamName: domain.TeamName(query.Get("team_name")),
+		Order:    domain.SortOrder(query.Get("sort")),
+	}
+
+	if status := query.Get("status"); status != "" {
+		if strings.EqualFold(status, statusAll) {
+			filter.Statuses = domain.PRStatuses
+		} else {
+			for _, s := range strings.Split(status, ",") {
+				filter.Statuses = append(filter.Statuses, domain.PRStatus(strings.ToUpper(strings.TrimSpace(s))))
+			}
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return domain.PullRequestFilter{}, fmt.Errorf("%w: 'limit' must be a positive integer", domain.ErrInvalidArgument)
+		}
+		filter.Limit = n
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return domain.PullRequestFilter{}, err
+		}
+		filter.After = after
+	}
+
+	return filter, nil
+}
+
+func encodeUserCursor(cursor *domain.UserID) *string {
+	if cursor == nil {
+		return nil
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(*cursor))
+
+	return &encoded
+}
+
+// parseUserFilter reads the team_name, is_active, role, limit and cursor
+// query parameters of the user directory.
+func parseUserFilter(query url.Values) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		TeamName: domain.TeamName(query.Get("team_name")),
+		Role:     domain.UserRole(strings.ToUpper(query.Get("role"))),
+	}
+
+	if isActive := query.Get("is_active"); isActive != "" {
+		parsed, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return domain.UserFilter{}, fmt.Errorf("%w: 'is_active' must be a boolean", domain.ErrInvalidArgument)
+		}
+		filter.IsActive = &parsed
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return domain.UserFilter{}, fmt.Errorf("%w: 'limit' must be a positive integer", domain.ErrInvalidArgument)
+		}
+		filter.Limit = n
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(raw) == 0 {
+			return domain.UserFilter{}, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidArgument)
+		}
+		filter.After = domain.UserID(raw)
+	}
+
+	return filter, nil
+}
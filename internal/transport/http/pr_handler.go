@@ -11,16 +11,20 @@ type createPRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	TeamName        string `json:"team_name"`
 }
 
 type pullRequestResponse struct {
-	PullRequestID     string   `json:"pull_request_id"`
-	PullRequestName   string   `json:"pull_request_name"`
-	AuthorID          string   `json:"author_id"`
-	Status            string   `json:"status"`
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	CreatedAt         string   `json:"createdAt"`
-	MergedAt          *string  `json:"mergedAt,omitempty"`
+	PullRequestID     string            `json:"pull_request_id"`
+	PullRequestName   string            `json:"pull_request_name"`
+	AuthorID          string            `json:"author_id"`
+	TeamName          string            `json:"team_name"`
+	Status            string            `json:"status"`
+	AssignedReviewers []string          `json:"assigned_reviewers"`
+	ReviewStates      map[string]string `json:"review_states"`
+	CreatedAt         string            `json:"createdAt"`
+	MergedAt          *string           `json:"mergedAt,omitempty"`
+	ClosedAt          *string           `json:"closedAt,omitempty"`
 }
 
 type createPRResponse struct {
@@ -45,10 +49,56 @@ type reassignPRResponse struct {
 	ReplacedBy string              `json:"replaced_by"`
 }
 
+type submitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	State         string `json:"state"`
+}
+
+type submitReviewResponse struct {
+	PR pullRequestResponse `json:"pr"`
+}
+
+type prEventDTO struct {
+	EventID        int64  `json:"event_id"`
+	Kind           string `json:"kind"`
+	UserID         string `json:"user_id,omitempty"`
+	PreviousUserID string `json:"previous_user_id,omitempty"`
+	ReviewState    string `json:"review_state,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	Actor          string `json:"actor,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type timelineResponse struct {
+	PullRequestID string       `json:"pull_request_id"`
+	Events        []prEventDTO `json:"events"`
+}
+
+func newTimelineResponse(prID domain.PullRequestID, events []domain.PREvent) timelineResponse {
+	dtos := make([]prEventDTO, len(events))
+	for i, event := range events {
+		dtos[i] = prEventDTO{
+			EventID:        event.ID,
+			Kind:           string(event.Kind),
+			UserID:         string(event.UserID),
+			PreviousUserID: string(event.PreviousUserID),
+			ReviewState:    string(event.ReviewState),
+			Reason:         string(event.Reason),
+			Actor:          event.Actor,
+			CreatedAt:      event.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return timelineResponse{PullRequestID: string(prID), Events: dtos}
+}
+
 func newPullRequestResponse(pr domain.PullRequest) pullRequestResponse {
 	reviewers := make([]string, len(pr.AssignedReviewers))
+	reviewStates := make(map[string]string, len(pr.AssignedReviewers))
 	for i, r := range pr.AssignedReviewers {
 		reviewers[i] = string(r)
+		reviewStates[string(r)] = string(pr.ReviewState(r))
 	}
 
 	var mergedAt *string
@@ -57,14 +107,23 @@ func newPullRequestResponse(pr domain.PullRequest) pullRequestResponse {
 		mergedAt = &ts
 	}
 
+	var closedAt *string
+	if pr.ClosedAt != nil {
+		ts := pr.ClosedAt.UTC().Format(time.RFC3339)
+		closedAt = &ts
+	}
+
 	return pullRequestResponse{
 		PullRequestID:     string(pr.ID),
 		PullRequestName:   pr.Name,
 		AuthorID:          string(pr.AuthorID),
+		TeamName:          string(pr.TeamName),
 		Status:            string(pr.Status),
 		AssignedReviewers: reviewers,
+		ReviewStates:      reviewStates,
 		CreatedAt:         pr.CreatedAt.UTC().Format(time.RFC3339),
 		MergedAt:          mergedAt,
+		ClosedAt:          closedAt,
 	}
 }
 
@@ -81,6 +140,7 @@ func (h *Handler) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 		domain.PullRequestID(req.PullRequestID),
 		req.PullRequestName,
 		domain.UserID(req.AuthorID),
+		domain.TeamName(req.TeamName),
 	)
 	if err != nil {
 		h.respondError(w, r, err)
@@ -140,3 +200,46 @@ func (h *Handler) handleReassignPR(w http.ResponseWriter, r *http.Request) {
 
 	h.respondJSON(w, r, http.StatusOK, resp)
 }
+
+func (h *Handler) handleSubmitReview(w http.ResponseWriter, r *http.Request) {
+	var req submitReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	pr, err := h.prService.SubmitReview(
+		r.Context(),
+		domain.PullRequestID(req.PullRequestID),
+		domain.UserID(req.ReviewerID),
+		domain.ReviewState(req.State),
+	)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := submitReviewResponse{
+		PR: newPullRequestResponse(pr),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetTimeline(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "missing required 'pull_request_id' query parameter"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	events, err := h.prService.Timeline(r.Context(), domain.PullRequestID(prID))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, newTimelineResponse(domain.PullRequestID(prID), events))
+}
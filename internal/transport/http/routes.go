@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"pr-reviewer-service/internal/domain"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -13,23 +14,122 @@ func (h *Handler) RegisterRoutes() http.Handler {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(NewSlogLogger(h.logger))
+	r.Use(withActor)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.SetHeader("Content-Type", "application/json"))
 
 	r.Route("/team", func(r chi.Router) {
+		r.Use(h.requireScopes(domain.ScopeRead, domain.ScopeTeamsWrite))
+		r.Use(h.idempotent)
+
 		r.Post("/add", h.handlerAddTeam)
 		r.Get("/get", h.handleGetTeam)
+		r.Post("/addMember", h.handleAddTeamMember)
+		r.Post("/removeMember", h.handleRemoveTeamMember)
+		r.Post("/moveMember", h.handleMoveTeamMember)
+		r.Post("/rename", h.handleRenameTeam)
+		r.Post("/setParent", h.handleSetParentTeam)
+		r.Post("/archive", h.handleArchiveTeam)
+		r.Post("/delete", h.handleDeleteTeam)
+		r.Post("/setChatChannel", h.handleSetChatChannel)
+		r.Get("/chatChannel", h.handleGetChatChannel)
+		r.Post("/removeChatChannel", h.handleRemoveChatChannel)
 	})
 
 	r.Route("/users", func(r chi.Router) {
+		r.Use(h.requireScopes(domain.ScopeRead, domain.ScopeTeamsWrite))
+		r.Use(h.idempotent)
+
+		r.Get("/get", h.handleGetUser)
+		r.Get("/list", h.handleListUsers)
+		r.Post("/update", h.handleUpdateUser)
+		r.Post("/delete", h.handleDeleteUser)
+		r.Post("/offboard", h.handleOffboardUser)
 		r.Post("/setIsActive", h.handleSetUserActive)
 		r.Get("/getReview", h.handleGetReview)
+		r.Get("/getAuthored", h.handleGetAuthored)
+		r.Post("/linkIdentity", h.handleLinkIdentity)
+		r.Post("/unlinkIdentity", h.handleUnlinkIdentity)
+		r.Get("/identities", h.handleListIdentities)
+		r.Get("/byIdentity", h.handleGetUserByIdentity)
 	})
 
 	r.Route("/pullRequest", func(r chi.Router) {
+		r.Use(h.requireScopes(domain.ScopeRead, domain.ScopePRsWrite))
+		r.Use(h.idempotent)
+
 		r.Post("/create", h.handleCreatePR)
 		r.Post("/merge", h.handleMergePR)
 		r.Post("/reassign", h.handleReassignPR)
+		r.Post("/review", h.handleSubmitReview)
+		r.Get("/timeline", h.handleGetTimeline)
+	})
+
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(h.requireScope(domain.ScopeAdmin))
+
+		// the response carries the signing secret, which must not be stored
+		r.Post("/create", h.handleCreateWebhook)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.idempotent)
+
+			r.Get("/list", h.handleListWebhooks)
+			r.Post("/delete", h.handleDeleteWebhook)
+			r.Get("/deadLetters", h.handleWebhookDeadLetters)
+			r.Post("/redeliver", h.handleRedeliverWebhook)
+		})
+	})
+
+	r.Route(scimBasePath, func(r chi.Router) {
+		r.Use(h.requireScope(domain.ScopeAdmin))
+		r.Use(h.idempotent)
+
+		r.Get("/ServiceProviderConfig", h.handleSCIMServiceProviderConfig)
+
+		r.Route("/Users", func(r chi.Router) {
+			r.Get("/", h.handleSCIMListUsers)
+			r.Post("/", h.handleSCIMCreateUser)
+			r.Get("/{id}", h.handleSCIMGetUser)
+			r.Put("/{id}", h.handleSCIMReplaceUser)
+			r.Patch("/{id}", h.handleSCIMPatchUser)
+			r.Delete("/{id}", h.handleSCIMDeleteUser)
+		})
+
+		r.Route("/Groups", func(r chi.Router) {
+			r.Get("/", h.handleSCIMListGroups)
+			r.Post("/", h.handleSCIMCreateGroup)
+			r.Get("/{id}", h.handleSCIMGetGroup)
+			r.Put("/{id}", h.handleSCIMReplaceGroup)
+			r.Patch("/{id}", h.handleSCIMPatchGroup)
+			r.Delete("/{id}", h.handleSCIMDeleteGroup)
+		})
+	})
+
+	r.Route("/integrations", func(r chi.Router) {
+		r.Use(h.idempotent)
+
+		r.Post("/github/webhook", h.handleGitHubWebhook)
+		r.Post("/gitlab/webhook", h.handleGitLabWebhook)
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(h.requireScope(domain.ScopeAdmin))
+
+		// the response carries the token secret, which must not be stored
+		r.Post("/tokens/issue", h.handleIssueToken)
+
+		r.With(h.withStateImportDeadlines, h.idempotent).Post("/import", h.handleImportState)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.idempotent)
+
+			r.Post("/sync", h.handleOrgSync)
+			r.Get("/export", h.handleExportState)
+
+			r.Get("/tokens/list", h.handleListTokens)
+			r.Post("/tokens/revoke", h.handleRevokeToken)
+		})
 	})
 
 	r.Get("/health", h.handleHealthCheck)
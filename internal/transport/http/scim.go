@@ -0,0 +1,424 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"pr-reviewer-service/internal/domain"
+	"strconv"
+	"strings"
+)
+
+const (
+	scimUserSchema          = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema         = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema         = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimServiceConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	scimContentType = "application/scim+json"
+	scimBasePath    = "/scim/v2"
+	scimMaxResults  = 200
+)
+
+var (
+	errInvalidSCIMFilter = fmt.Errorf("%w: invalid filter", domain.ErrInvalidArgument)
+	// errSCIMImmutable rejects renaming a group, its id is the team name and
+	// the IdP keeps addressing the group by it.
+	errSCIMImmutable = fmt.Errorf("%w: displayName can not be changed", domain.ErrInvalidArgument)
+)
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimName struct {
+	Formatted string `json:"formatted,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimRole struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimUser struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *scimName       `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Emails      []scimEmail     `json:"emails,omitempty"`
+	Roles       []scimRole      `json:"roles,omitempty"`
+	Groups      []scimReference `json:"groups,omitempty"`
+	Meta        *scimMeta       `json:"meta,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []scimReference `json:"members"`
+	Meta        *scimMeta       `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+type scimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// scimFilterTerm is one `attribute eq value` comparison of a filter, the
+// attribute is lower-cased since SCIM attribute names are case-insensitive.
+type scimFilterTerm struct {
+	attribute string
+	value     string
+}
+
+func newSCIMUser(user domain.User) scimUser {
+	active := user.IsActive
+	resource := scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       string(user.ID),
+		UserName: user.Username,
+		Active:   &active,
+		Roles:    []scimRole{{Value: string(user.Role), Primary: true}},
+		Groups:   []scimReference{},
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Location:     scimBasePath + "/Users/" + url.PathEscape(string(user.ID)),
+		},
+	}
+
+	if user.FullName != "" {
+		resource.Name = &scimName{Formatted: user.FullName}
+		resource.DisplayName = user.FullName
+	}
+
+	if user.Email != "" {
+		resource.Emails = []scimEmail{{Value: user.Email, Type: "work", Primary: true}}
+	}
+
+	for _, teamName := range user.Teams {
+		resource.Groups = append(resource.Groups, scimReference{Value: string(teamName), Display: string(teamName)})
+	}
+
+	return resource
+}
+
+func newSCIMGroup(team domain.Team) scimGroup {
+	resource := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          string(team.Name),
+		DisplayName: string(team.Name),
+		Members:     make([]scimReference, len(team.Members)),
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Location:     scimBasePath + "/Groups/" + url.PathEscape(string(team.Name)),
+		},
+	}
+
+	for i, member := range team.Members {
+		resource.Members[i] = scimReference{Value: string(member.UserID), Display: member.Username}
+	}
+
+	return resource
+}
+
+// domainUser maps a SCIM user onto a user. The IdP's externalId becomes the
+// user ID, falling back to userName when the IdP sends none.
+func (u scimUser) domainUser() domain.User {
+	user := domain.User{
+		ID:       domain.UserID(u.ExternalID),
+		Username: u.UserName,
+		FullName: u.fullName(),
+		Email:    u.email(),
+		Role:     u.role(),
+		IsActive: u.Active == nil || *u.Active,
+	}
+
+	if user.ID == "" {
+		user.ID = domain.UserID(u.UserName)
+	}
+
+	return user
+}
+
+func (u scimUser) fullName() string {
+	if u.Name != nil && u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+
+	return u.DisplayName
+}
+
+func (u scimUser) email() string {
+	return primaryEmail(u.Emails)
+}
+
+func primaryEmail(emails []scimEmail) string {
+	for _, email := range emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+
+	return ""
+}
+
+func (u scimUser) role() domain.UserRole {
+	for _, role := range u.Roles {
+		if role.Primary {
+			return domain.UserRole(role.Value)
+		}
+	}
+
+	if len(u.Roles) > 0 {
+		return domain.UserRole(u.Roles[0].Value)
+	}
+
+	return ""
+}
+
+// newSCIMListResponse cuts the page requested with startIndex and count out
+// of all matching resources.
+func newSCIMListResponse[T any](r *http.Request, resources []T) (scimListResponse, error) {
+	startIndex, err := scimIntParam(r, "startIndex", 1)
+	if err != nil {
+		return scimListResponse{}, err
+	}
+	startIndex = max(startIndex, 1)
+
+	count, err := scimIntParam(r, "count", scimMaxResults)
+	if err != nil {
+		return scimListResponse{}, err
+	}
+	count = min(max(count, 0), scimMaxResults)
+
+	from := min(startIndex-1, len(resources))
+	to := min(from+count, len(resources))
+
+	return scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: to - from,
+		Resources:    resources[from:to],
+	}, nil
+}
+
+func scimIntParam(r *http.Request, name string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: '%s' must be an integer", domain.ErrInvalidArgument, name)
+	}
+
+	return value, nil
+}
+
+// parseSCIMFilter supports the filters identity providers send in practice:
+// `eq` comparisons joined with `and`.
+func parseSCIMFilter(raw string) ([]scimFilterTerm, error) {
+	terms := []scimFilterTerm{}
+	rest := strings.TrimSpace(raw)
+
+	for rest != "" {
+		attribute, tail, ok := strings.Cut(rest, " ")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errInvalidSCIMFilter, raw)
+		}
+
+		operator, tail, ok := strings.Cut(strings.TrimSpace(tail), " ")
+		if !ok || !strings.EqualFold(operator, "eq") {
+			return nil, fmt.Errorf("%w: only 'eq' is supported", errInvalidSCIMFilter)
+		}
+
+		value, tail, err := scimFilterValue(strings.TrimSpace(tail))
+		if err != nil {
+			return nil, err
+		}
+
+		terms = append(terms, scimFilterTerm{attribute: strings.ToLower(attribute), value: value})
+
+		rest = strings.TrimSpace(tail)
+		if rest == "" {
+			break
+		}
+
+		conjunction, tail, _ := strings.Cut(rest, " ")
+		if !strings.EqualFold(conjunction, "and") {
+			return nil, fmt.Errorf("%w: only 'and' is supported", errInvalidSCIMFilter)
+		}
+		rest = strings.TrimSpace(tail)
+		if rest == "" {
+			return nil, fmt.Errorf("%w: %q", errInvalidSCIMFilter, raw)
+		}
+	}
+
+	return terms, nil
+}
+
+// scimFilterValue reads a quoted string or a bare literal such as true.
+func scimFilterValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		value, tail, _ := strings.Cut(s, " ")
+		if value == "" {
+			return "", "", fmt.Errorf("%w: missing value", errInvalidSCIMFilter)
+		}
+		return value, tail, nil
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			var value string
+			if err := json.Unmarshal([]byte(s[:i+1]), &value); err != nil {
+				return "", "", fmt.Errorf("%w: %v", errInvalidSCIMFilter, err)
+			}
+			return value, s[i+1:], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("%w: unterminated string", errInvalidSCIMFilter)
+}
+
+// scimMemberPath extracts the user ID from a `members[value eq "u1"]` path.
+func scimMemberPath(path string) (domain.UserID, bool, error) {
+	inner, found := strings.CutPrefix(path, "members[")
+	if !found {
+		return "", false, nil
+	}
+
+	inner, found = strings.CutSuffix(inner, "]")
+	if !found {
+		return "", false, fmt.Errorf("%w: %q", errInvalidSCIMFilter, path)
+	}
+
+	terms, err := parseSCIMFilter(inner)
+	if err != nil {
+		return "", false, err
+	}
+	if len(terms) != 1 || terms[0].attribute != "value" {
+		return "", false, fmt.Errorf("%w: members can only be selected by value", errInvalidSCIMFilter)
+	}
+
+	return domain.UserID(terms[0].value), true, nil
+}
+
+// scimBool accepts booleans and, as some IdPs send them, strings like "False".
+func scimBool(raw json.RawMessage) (bool, error) {
+	var value bool
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, fmt.Errorf("%w: expected a boolean", domain.ErrInvalidArgument)
+	}
+
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("%w: expected a boolean", domain.ErrInvalidArgument)
+	}
+
+	return value, nil
+}
+
+func scimString(raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%w: expected a string", domain.ErrInvalidArgument)
+	}
+
+	return value, nil
+}
+
+func (h *Handler) respondSCIM(w http.ResponseWriter, r *http.Request, status int, data any) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write scim responce", "error", err)
+	}
+}
+
+func (h *Handler) respondSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	status, apiErr := errorResponse(err)
+
+	scimType := ""
+	switch {
+	case errors.Is(err, domain.ErrUserExists), errors.Is(err, domain.ErrTeamExists):
+		status = http.StatusConflict
+		scimType = "uniqueness"
+	case errors.Is(err, errInvalidSCIMFilter):
+		scimType = "invalidFilter"
+	case errors.Is(err, errSCIMImmutable):
+		scimType = "mutability"
+	case status == http.StatusBadRequest:
+		scimType = "invalidValue"
+	case status == http.StatusInternalServerError:
+		h.logger.ErrorContext(r.Context(), "scim server error", "error", err)
+	}
+
+	h.respondSCIM(w, r, status, scimError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   apiErr.Message,
+	})
+}
+
+func (h *Handler) decodeSCIM(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.respondSCIMError(w, r, fmt.Errorf("%w: invalid json body", domain.ErrInvalidArgument))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,512 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// scimUserPatch collects the changes of all operations of a PATCH request.
+type scimUserPatch struct {
+	update service.UserUpdate
+	active *bool
+}
+
+func (h *Handler) handleSCIMServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	h.respondSCIM(w, r, http.StatusOK, map[string]any{
+		"schemas":               []string{scimServiceConfigSchema},
+		"patch":                 map[string]bool{"supported": true},
+		"bulk":                  map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":                map[string]any{"supported": true, "maxResults": scimMaxResults},
+		"changePassword":        map[string]bool{"supported": false},
+		"sort":                  map[string]bool{"supported": false},
+		"etag":                  map[string]bool{"supported": false},
+		"authenticationSchemes": []any{},
+	})
+}
+
+func (h *Handler) handleSCIMListUsers(w http.ResponseWriter, r *http.Request) {
+	terms, err := parseSCIMFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	filter := domain.UserFilter{}
+	userID := domain.UserID("")
+	for _, term := range terms {
+		switch term.attribute {
+		case "username":
+			filter.Username = term.value
+		case "id", "externalid":
+			userID = domain.UserID(term.value)
+		case "active":
+			isActive, err := strconv.ParseBool(term.value)
+			if err != nil {
+				h.respondSCIMError(w, r, fmt.Errorf("%w: active must be a boolean", errInvalidSCIMFilter))
+				return
+			}
+			filter.IsActive = &isActive
+		default:
+			h.respondSCIMError(w, r, fmt.Errorf("%w: unsupported attribute %s", errInvalidSCIMFilter, term.attribute))
+			return
+		}
+	}
+
+	users, err := h.userService.FindUsers(r.Context(), filter)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	resources := []scimUser{}
+	for _, user := range users {
+		if userID == "" || user.ID == userID {
+			resources = append(resources, newSCIMUser(user))
+		}
+	}
+
+	resp, err := newSCIMListResponse(r, resources)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.respondSCIM(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleSCIMGetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userService.User(r.Context(), domain.UserID(chi.URLParam(r, "id")))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.respondSCIM(w, r, http.StatusOK, newSCIMUser(user))
+}
+
+func (h *Handler) handleSCIMCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req scimUser
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	user, err := h.userService.CreateUser(r.Context(), req.domainUser())
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	resource := newSCIMUser(user)
+	w.Header().Set("Location", resource.Meta.Location)
+	h.respondSCIM(w, r, http.StatusCreated, resource)
+}
+
+func (h *Handler) handleSCIMReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var req scimUser
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	fullName := req.fullName()
+	email := req.email()
+	patch := scimUserPatch{
+		update: service.UserUpdate{Username: &req.UserName, FullName: &fullName, Email: &email},
+		active: req.Active,
+	}
+	if role := req.role(); role != "" {
+		patch.update.Role = &role
+	}
+
+	h.applySCIMUserPatch(w, r, domain.UserID(chi.URLParam(r, "id")), patch)
+}
+
+func (h *Handler) handleSCIMPatchUser(w http.ResponseWriter, r *http.Request) {
+	var req scimPatchRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	patch := scimUserPatch{}
+	for _, op := range req.Operations {
+		if err := patch.apply(op); err != nil {
+			h.respondSCIMError(w, r, err)
+			return
+		}
+	}
+
+	h.applySCIMUserPatch(w, r, domain.UserID(chi.URLParam(r, "id")), patch)
+}
+
+// applySCIMUserPatch updates the profile and the active flag at once,
+// deactivated users hand their open reviews over.
+func (h *Handler) applySCIMUserPatch(w http.ResponseWriter, r *http.Request, userID domain.UserID, patch scimUserPatch) {
+	user, _, err := h.userService.PatchUser(r.Context(), userID, service.UserPatch{UserUpdate: patch.update, IsActive: patch.active})
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.respondSCIM(w, r, http.StatusOK, newSCIMUser(user))
+}
+
+func (p *scimUserPatch) apply(op scimPatchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+	case "remove":
+		switch strings.ToLower(op.Path) {
+		case "name.formatted", "displayname":
+			empty := ""
+			p.update.FullName = &empty
+			return nil
+		case "emails":
+			empty := ""
+			p.update.Email = &empty
+			return nil
+		}
+		return fmt.Errorf("%w: %s cannot be removed", domain.ErrInvalidArgument, op.Path)
+	default:
+		return fmt.Errorf("%w: unsupported op %q", domain.ErrInvalidArgument, op.Op)
+	}
+
+	if op.Path != "" {
+		return p.set(op.Path, op.Value)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &values); err != nil {
+		return fmt.Errorf("%w: value must be an object without path", domain.ErrInvalidArgument)
+	}
+
+	for path, value := range values {
+		if err := p.set(path, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *scimUserPatch) set(path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := scimBool(value)
+		if err != nil {
+			return err
+		}
+		p.active = &active
+	case "username":
+		username, err := scimString(value)
+		if err != nil {
+			return err
+		}
+		p.update.Username = &username
+	case "name.formatted", "displayname":
+		fullName, err := scimString(value)
+		if err != nil {
+			return err
+		}
+		p.update.FullName = &fullName
+	case "name":
+		var name scimName
+		if err := json.Unmarshal(value, &name); err != nil {
+			return fmt.Errorf("%w: invalid name", domain.ErrInvalidArgument)
+		}
+		p.update.FullName = &name.Formatted
+	case "emails":
+		var emails []scimEmail
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return fmt.Errorf("%w: invalid emails", domain.ErrInvalidArgument)
+		}
+		email := primaryEmail(emails)
+		p.update.Email = &email
+	case "roles":
+		var roles []scimRole
+		if err := json.Unmarshal(value, &roles); err != nil {
+			return fmt.Errorf("%w: invalid roles", domain.ErrInvalidArgument)
+		}
+		if role := (scimUser{Roles: roles}).role(); role != "" {
+			p.update.Role = &role
+		}
+	}
+
+	// Other attributes such as the external ID, which is the user ID here,
+	// are not stored and ignored like on create.
+	return nil
+}
+
+func (h *Handler) handleSCIMDeleteUser(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.userService.DeleteUser(r.Context(), domain.UserID(chi.URLParam(r, "id")), true); err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleSCIMListGroups(w http.ResponseWriter, r *http.Request) {
+	terms, err := parseSCIMFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	teamName := domain.TeamName("")
+	for _, term := range terms {
+		switch term.attribute {
+		case "displayname", "id":
+			teamName = domain.TeamName(term.value)
+		default:
+			h.respondSCIMError(w, r, fmt.Errorf("%w: unsupported attribute %s", errInvalidSCIMFilter, term.attribute))
+			return
+		}
+	}
+
+	teams, err := h.teamService.ActiveTeams(r.Context())
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	resources := []scimGroup{}
+	for _, team := range teams {
+		if teamName == "" || team.Name == teamName {
+			resources = append(resources, newSCIMGroup(team))
+		}
+	}
+
+	resp, err := newSCIMListResponse(r, resources)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.respondSCIM(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleSCIMGetGroup(w http.ResponseWriter, r *http.Request) {
+	team, err := h.scimGroup(r.Context(), domain.TeamName(chi.URLParam(r, "id")))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.respondSCIM(w, r, http.StatusOK, newSCIMGroup(team))
+}
+
+func (h *Handler) handleSCIMCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req scimGroup
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	if req.DisplayName == "" {
+		h.respondSCIMError(w, r, fmt.Errorf("%w: displayName is required", domain.ErrInvalidArgument))
+		return
+	}
+
+	team, err := h.teamService.CreateTeamOfUsers(r.Context(), domain.TeamName(req.DisplayName), scimMemberIDs(req.Members))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	resource := newSCIMGroup(team)
+	w.Header().Set("Location", resource.Meta.Location)
+	h.respondSCIM(w, r, http.StatusCreated, resource)
+}
+
+func (h *Handler) handleSCIMReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	var req scimGroup
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	teamName := domain.TeamName(chi.URLParam(r, "id"))
+	if err := checkSCIMDisplayName(teamName, req.DisplayName); err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.changeSCIMGroup(w, r, teamName, []service.TeamChange{
+		{Kind: service.TeamChangeSetMembers, Members: scimMemberIDs(req.Members)},
+	})
+}
+
+func (h *Handler) handleSCIMPatchGroup(w http.ResponseWriter, r *http.Request) {
+	var req scimPatchRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	teamName := domain.TeamName(chi.URLParam(r, "id"))
+	changes := []service.TeamChange{}
+	for _, op := range req.Operations {
+		opChanges, err := scimGroupChanges(teamName, op)
+		if err != nil {
+			h.respondSCIMError(w, r, err)
+			return
+		}
+		changes = append(changes, opChanges...)
+	}
+
+	h.changeSCIMGroup(w, r, teamName, changes)
+}
+
+// changeSCIMGroup applies all changes of a request to the group at once.
+func (h *Handler) changeSCIMGroup(w http.ResponseWriter, r *http.Request, teamName domain.TeamName, changes []service.TeamChange) {
+	team, err := h.scimGroup(r.Context(), teamName)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	if team, err = h.teamService.ChangeTeam(r.Context(), team.Name, changes); err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	h.respondSCIM(w, r, http.StatusOK, newSCIMGroup(team))
+}
+
+// checkSCIMDisplayName accepts the current name of the group only, as the
+// name is the id of the group.
+func checkSCIMDisplayName(teamName domain.TeamName, displayName string) error {
+	if displayName != "" && domain.TeamName(displayName) != teamName {
+		return errSCIMImmutable
+	}
+
+	return nil
+}
+
+// scimGroupChanges translates a single PATCH operation into team changes.
+func scimGroupChanges(teamName domain.TeamName, op scimPatchOperation) ([]service.TeamChange, error) {
+	path := strings.ToLower(op.Path)
+
+	switch strings.ToLower(op.Op) {
+	case "add":
+		if path != "members" {
+			return nil, fmt.Errorf("%w: unsupported path %q", domain.ErrInvalidArgument, op.Path)
+		}
+
+		members, err := scimMembers(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return []service.TeamChange{{Kind: service.TeamChangeAddMembers, Members: scimMemberIDs(members)}}, nil
+
+	case "remove":
+		userID, selected, err := scimMemberPath(op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case selected:
+			return []service.TeamChange{{Kind: service.TeamChangeRemoveMembers, Members: []domain.UserID{userID}}}, nil
+		case path == "members" && len(op.Value) > 0:
+			members, err := scimMembers(op.Value)
+			if err != nil {
+				return nil, err
+			}
+			return []service.TeamChange{{Kind: service.TeamChangeRemoveMembers, Members: scimMemberIDs(members)}}, nil
+		case path == "members":
+			return []service.TeamChange{{Kind: service.TeamChangeSetMembers, Members: []domain.UserID{}}}, nil
+		default:
+			return nil, fmt.Errorf("%w: unsupported path %q", domain.ErrInvalidArgument, op.Path)
+		}
+
+	case "replace":
+		var replacement scimGroup
+		switch path {
+		case "":
+			if err := json.Unmarshal(op.Value, &replacement); err != nil {
+				return nil, fmt.Errorf("%w: value must be an object without path", domain.ErrInvalidArgument)
+			}
+		case "displayname":
+			displayName, err := scimString(op.Value)
+			if err != nil {
+				return nil, err
+			}
+			replacement.DisplayName = displayName
+		case "members":
+			members, err := scimMembers(op.Value)
+			if err != nil {
+				return nil, err
+			}
+			replacement.Members = members
+		default:
+			return nil, fmt.Errorf("%w: unsupported path %q", domain.ErrInvalidArgument, op.Path)
+		}
+
+		if err := checkSCIMDisplayName(teamName, replacement.DisplayName); err != nil {
+			return nil, err
+		}
+
+		changes := []service.TeamChange{}
+		if replacement.Members != nil {
+			changes = append(changes, service.TeamChange{Kind: service.TeamChangeSetMembers, Members: scimMemberIDs(replacement.Members)})
+		}
+
+		return changes, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported op %q", domain.ErrInvalidArgument, op.Op)
+	}
+}
+
+func (h *Handler) handleSCIMDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	team, err := h.scimGroup(r.Context(), domain.TeamName(chi.URLParam(r, "id")))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	if _, err := h.teamService.ArchiveTeam(r.Context(), team.Name, ""); err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scimGroup returns the team behind a group, archived teams are deleted
+// groups as far as the IdP is concerned.
+func (h *Handler) scimGroup(ctx context.Context, teamName domain.TeamName) (domain.Team, error) {
+	team, err := h.teamService.Team(ctx, teamName)
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	if team.ArchivedAt != nil {
+		return domain.Team{}, fmt.Errorf("%w: %s", domain.ErrNotFound, teamName)
+	}
+
+	return team, nil
+}
+
+func scimMembers(raw json.RawMessage) ([]scimReference, error) {
+	members := []scimReference{}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, fmt.Errorf("%w: members must be a list of references", domain.ErrInvalidArgument)
+	}
+
+	return members, nil
+}
+
+func scimMemberIDs(members []scimReference) []domain.UserID {
+	userIDs := make([]domain.UserID, len(members))
+	for i, member := range members {
+		userIDs[i] = domain.UserID(member.Value)
+	}
+
+	return userIDs
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+	"strconv"
+	"time"
 )
 
 type teamMemberDTO struct {
@@ -13,17 +16,87 @@ type teamMemberDTO struct {
 }
 
 type teamRequest struct {
-	TeamName string          `json:"team_name"`
-	Members  []teamMemberDTO `json:"members"`
+	TeamName       string          `json:"team_name"`
+	ParentTeamName string          `json:"parent_team_name"`
+	Members        []teamMemberDTO `json:"members"`
+	MoveExisting   bool            `json:"move_existing"`
 }
 
 type teamResponse struct {
-	TeamName string          `json:"team_name"`
-	Members  []teamMemberDTO `json:"members"`
+	TeamName       string          `json:"team_name"`
+	ParentTeamName *string         `json:"parent_team_name,omitempty"`
+	Members        []teamMemberDTO `json:"members"`
+	IsArchived     bool            `json:"is_archived"`
+	ArchivedAt     *string         `json:"archived_at,omitempty"`
+	SubTeams       []teamResponse  `json:"sub_teams,omitempty"`
 }
 
 type teamAddResponse struct {
-	Team teamResponse `json:"team"`
+	Team            teamResponse        `json:"team"`
+	MovedMembers    []memberConflictDTO `json:"moved_members,omitempty"`
+	ReleasedReviews []releasedReviewDTO `json:"released_reviews,omitempty"`
+}
+
+type memberConflictDTO struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type addMemberRequest struct {
+	TeamName string `json:"team_name"`
+	teamMemberDTO
+}
+
+type removeMemberRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
+
+type moveMemberRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type renameTeamRequest struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+type archiveTeamRequest struct {
+	TeamName      string `json:"team_name"`
+	MoveMembersTo string `json:"move_members_to"`
+}
+
+type archiveTeamResponse struct {
+	Team               teamResponse        `json:"team"`
+	MovedMembers       []string            `json:"moved_members"`
+	DeactivatedMembers []string            `json:"deactivated_members"`
+	ReleasedReviews    []releasedReviewDTO `json:"released_reviews"`
+}
+
+type setParentTeamRequest struct {
+	TeamName       string `json:"team_name"`
+	ParentTeamName string `json:"parent_team_name"`
+}
+
+type deleteTeamRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type releasedReviewDTO struct {
+	PullRequestID string  `json:"pull_request_id"`
+	ReplacedBy    *string `json:"replaced_by"`
+}
+
+type removeMemberResponse struct {
+	TeamName        string              `json:"team_name"`
+	UserID          string              `json:"user_id"`
+	ReleasedReviews []releasedReviewDTO `json:"released_reviews"`
+}
+
+type moveMemberResponse struct {
+	User            userResponse        `json:"user"`
+	ReleasedReviews []releasedReviewDTO `json:"released_reviews"`
 }
 
 func (req *teamRequest) toDomainTeam() domain.Team {
@@ -36,8 +109,9 @@ func (req *teamRequest) toDomainTeam() domain.Team {
 		}
 	}
 	return domain.Team{
-		Name:    domain.TeamName(req.TeamName),
-		Members: members,
+		Name:       domain.TeamName(req.TeamName),
+		ParentName: domain.TeamName(req.ParentTeamName),
+		Members:    members,
 	}
 }
 
@@ -50,10 +124,69 @@ func newTeamResponse(team domain.Team) teamResponse {
 			IsActive: m.IsActive,
 		}
 	}
+
+	var archivedAt *string
+	if team.ArchivedAt != nil {
+		ts := team.ArchivedAt.UTC().Format(time.RFC3339)
+		archivedAt = &ts
+	}
+
+	var parentTeamName *string
+	if team.ParentName != "" {
+		name := string(team.ParentName)
+		parentTeamName = &name
+	}
+
+	var subTeams []teamResponse
+	if team.SubTeams != nil {
+		subTeams = make([]teamResponse, len(team.SubTeams))
+		for i, subTeam := range team.SubTeams {
+			subTeams[i] = newTeamResponse(subTeam)
+		}
+	}
+
 	return teamResponse{
-		TeamName: string(team.Name),
-		Members:  members,
+		TeamName:       string(team.Name),
+		ParentTeamName: parentTeamName,
+		Members:        members,
+		IsArchived:     team.IsArchived(),
+		ArchivedAt:     archivedAt,
+		SubTeams:       subTeams,
+	}
+}
+
+func newReleasedReviewDTOs(released []service.ReleasedReview) []releasedReviewDTO {
+	dtos := make([]releasedReviewDTO, len(released))
+	for i, rr := range released {
+		dtos[i] = releasedReviewDTO{PullRequestID: string(rr.PullRequestID)}
+		if rr.ReplacedBy != "" {
+			replacedBy := string(rr.ReplacedBy)
+			dtos[i].ReplacedBy = &replacedBy
+		}
+	}
+
+	return dtos
+}
+
+func userIDsToStrings(ids []domain.UserID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = string(id)
 	}
+
+	return out
+}
+
+func newMemberConflictDTOs(conflicts []domain.MemberConflict) []memberConflictDTO {
+	dtos := make([]memberConflictDTO, len(conflicts))
+	for i, c := range conflicts {
+		dtos[i] = memberConflictDTO{
+			UserID:   string(c.UserID),
+			TeamName: string(c.TeamName),
+		}
+	}
+
+	return dtos
 }
 
 func (h *Handler) handlerAddTeam(w http.ResponseWriter, r *http.Request) {
@@ -66,12 +199,16 @@ func (h *Handler) handlerAddTeam(w http.ResponseWriter, r *http.Request) {
 
 	team := req.toDomainTeam()
 
-	if err := h.teamService.CreateTeam(r.Context(), team); err != nil {
+	creation, err := h.teamService.CreateTeam(r.Context(), team, req.MoveExisting)
+	if err != nil {
 		h.respondError(w, r, err)
+		return
 	}
 
 	resp := teamAddResponse{
-		Team: newTeamResponse(team),
+		Team:            newTeamResponse(team),
+		MovedMembers:    newMemberConflictDTOs(creation.MovedMembers),
+		ReleasedReviews: newReleasedReviewDTOs(creation.ReleasedReviews),
 	}
 
 	h.respondJSON(w, r, http.StatusCreated, resp)
@@ -85,7 +222,26 @@ func (h *Handler) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	team, err := h.teamService.Team(r.Context(), domain.TeamName(teamName))
+	includeDescendants := false
+	if raw := r.URL.Query().Get("include_descendants"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			apiErr := APIError{Code: "BAD_REQUEST", Message: "'include_descendants' must be a boolean"}
+			h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+			return
+		}
+		includeDescendants = parsed
+	}
+
+	var (
+		team domain.Team
+		err  error
+	)
+	if includeDescendants {
+		team, err = h.teamService.TeamWithDescendants(r.Context(), domain.TeamName(teamName))
+	} else {
+		team, err = h.teamService.Team(r.Context(), domain.TeamName(teamName))
+	}
 	if err != nil {
 		h.respondError(w, r, err)
 		return
@@ -95,3 +251,157 @@ func (h *Handler) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 
 	h.respondJSON(w, r, http.StatusOK, resp)
 }
+
+func (h *Handler) handleAddTeamMember(w http.ResponseWriter, r *http.Request) {
+	var req addMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	member := domain.TeamMember{
+		UserID:   domain.UserID(req.UserID),
+		Username: req.Username,
+		IsActive: req.IsActive,
+	}
+
+	team, err := h.teamService.AddMember(r.Context(), domain.TeamName(req.TeamName), member)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := teamAddResponse{
+		Team: newTeamResponse(team),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleRemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	var req removeMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	released, err := h.teamService.RemoveMember(r.Context(), domain.TeamName(req.TeamName), domain.UserID(req.UserID))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := removeMemberResponse{
+		TeamName:        req.TeamName,
+		UserID:          req.UserID,
+		ReleasedReviews: newReleasedReviewDTOs(released),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleMoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	var req moveMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	move, err := h.teamService.MoveMember(r.Context(), domain.UserID(req.UserID), domain.TeamName(req.TeamName))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := moveMemberResponse{
+		User:            newUserResponse(move.User),
+		ReleasedReviews: newReleasedReviewDTOs(move.ReleasedReviews),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleRenameTeam(w http.ResponseWriter, r *http.Request) {
+	var req renameTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	team, err := h.teamService.RenameTeam(r.Context(), domain.TeamName(req.TeamName), domain.TeamName(req.NewTeamName))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := teamAddResponse{
+		Team: newTeamResponse(team),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleSetParentTeam(w http.ResponseWriter, r *http.Request) {
+	var req setParentTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	team, err := h.teamService.SetParent(r.Context(), domain.TeamName(req.TeamName), domain.TeamName(req.ParentTeamName))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := teamAddResponse{
+		Team: newTeamResponse(team),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleArchiveTeam(w http.ResponseWriter, r *http.Request) {
+	var req archiveTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	archival, err := h.teamService.ArchiveTeam(r.Context(), domain.TeamName(req.TeamName), domain.TeamName(req.MoveMembersTo))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := archiveTeamResponse{
+		Team:               newTeamResponse(archival.Team),
+		MovedMembers:       userIDsToStrings(archival.MovedMembers),
+		DeactivatedMembers: userIDsToStrings(archival.DeactivatedMembers),
+		ReleasedReviews:    newReleasedReviewDTOs(archival.ReleasedReviews),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleDeleteTeam(w http.ResponseWriter, r *http.Request) {
+	var req deleteTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	if err := h.teamService.DeleteTeam(r.Context(), domain.TeamName(req.TeamName)); err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"time"
+)
+
+type issueTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type revokeTokenRequest struct {
+	TokenID int64 `json:"token_id"`
+}
+
+type apiTokenDTO struct {
+	TokenID    int64    `json:"token_id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"created_at"`
+	LastUsedAt *string  `json:"last_used_at,omitempty"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+}
+
+type issueTokenResponse struct {
+	Token  apiTokenDTO `json:"token"`
+	Secret string      `json:"secret"`
+}
+
+type apiTokenResponse struct {
+	Token apiTokenDTO `json:"token"`
+}
+
+type apiTokensResponse struct {
+	Tokens []apiTokenDTO `json:"tokens"`
+}
+
+func newAPITokenDTO(token domain.APIToken) apiTokenDTO {
+	scopes := make([]string, len(token.Scopes))
+	for i, scope := range token.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	dto := apiTokenDTO{
+		TokenID:   token.ID,
+		Name:      token.Name,
+		Scopes:    scopes,
+		CreatedAt: token.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if token.LastUsedAt != nil {
+		lastUsedAt := token.LastUsedAt.UTC().Format(time.RFC3339)
+		dto.LastUsedAt = &lastUsedAt
+	}
+	if token.RevokedAt != nil {
+		revokedAt := token.RevokedAt.UTC().Format(time.RFC3339)
+		dto.RevokedAt = &revokedAt
+	}
+
+	return dto
+}
+
+func (h *Handler) handleIssueToken(w http.ResponseWriter, r *http.Request) {
+	var req issueTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	scopes := make([]domain.TokenScope, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = domain.TokenScope(scope)
+	}
+
+	token, secret, err := h.tokenService.Issue(r.Context(), req.Name, scopes)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusCreated, issueTokenResponse{Token: newAPITokenDTO(token), Secret: secret})
+}
+
+func (h *Handler) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.tokenService.Tokens(r.Context())
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := apiTokensResponse{Tokens: make([]apiTokenDTO, len(tokens))}
+	for i, token := range tokens {
+		resp.Tokens[i] = newAPITokenDTO(token)
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	var req revokeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	token, err := h.tokenService.Revoke(r.Context(), req.TokenID)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, apiTokenResponse{Token: newAPITokenDTO(token)})
+}
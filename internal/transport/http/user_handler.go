@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/service"
+	"strings"
+	"time"
 )
 
 type setIsActiveRequest struct {
@@ -13,33 +15,105 @@ type setIsActiveRequest struct {
 }
 
 type userResponse struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	FullName string   `json:"full_name"`
+	Email    string   `json:"email"`
+	Role     string   `json:"role"`
+	TeamName string   `json:"team_name"`
+	Teams    []string `json:"teams"`
+	IsActive bool     `json:"is_active"`
 }
 
 type setUserActiveResponse struct {
 	User userResponse `json:"user"`
 }
 
+type getUserResponse struct {
+	User userResponse `json:"user"`
+}
+
+type listUsersResponse struct {
+	Users      []userResponse `json:"users"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+}
+
+type updateUserRequest struct {
+	UserID   string  `json:"user_id"`
+	Username *string `json:"username"`
+	FullName *string `json:"full_name"`
+	Email    *string `json:"email"`
+	Role     *string `json:"role"`
+}
+
+type updateUserResponse struct {
+	User userResponse `json:"user"`
+}
+
+type deleteUserRequest struct {
+	UserID          string `json:"user_id"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+type offboardUserRequest struct {
+	UserID      string `json:"user_id"`
+	SuccessorID string `json:"successor_id"`
+}
+
+type offboardUserResponse struct {
+	User                    userResponse        `json:"user"`
+	ReleasedReviews         []releasedReviewDTO `json:"released_reviews"`
+	TransferredPullRequests []string            `json:"transferred_pull_requests"`
+	ClosedPullRequests      []string            `json:"closed_pull_requests"`
+	SuccessorID             *string             `json:"successor_id,omitempty"`
+}
+
+type deleteUserResponse struct {
+	UserID          string              `json:"user_id"`
+	ReleasedReviews []releasedReviewDTO `json:"released_reviews"`
+}
+
 type pullRequestShortDTO struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	TeamName        string `json:"team_name"`
 	Status          string `json:"status"`
+	CreatedAt       string `json:"created_at"`
+	ReviewState     string `json:"review_state"`
 }
 
 type userReviewResponse struct {
 	UserID       string                `json:"user_id"`
 	PullRequests []pullRequestShortDTO `json:"pull_requests"`
+	NextCursor   *string               `json:"next_cursor,omitempty"`
+}
+
+type authoredPullRequestDTO struct {
+	pullRequestResponse
+	TimeOpenSeconds int64 `json:"time_open_seconds"`
+}
+
+type userAuthoredResponse struct {
+	UserID       string                   `json:"user_id"`
+	PullRequests []authoredPullRequestDTO `json:"pull_requests"`
+	NextCursor   *string                  `json:"next_cursor,omitempty"`
 }
 
 func newUserResponse(user domain.User) userResponse {
+	teams := make([]string, len(user.Teams))
+	for i, t := range user.Teams {
+		teams[i] = string(t)
+	}
+
 	return userResponse{
 		UserID:   string(user.ID),
 		Username: user.Username,
+		FullName: user.FullName,
+		Email:    user.Email,
+		Role:     string(user.Role),
 		TeamName: string(user.TeamName),
+		Teams:    teams,
 		IsActive: user.IsActive,
 	}
 }
@@ -51,13 +125,33 @@ func newUserReviewResponse(assigns service.UserReviewAssignments) userReviewResp
 			PullRequestID:   string(pr.ID),
 			PullRequestName: pr.Name,
 			AuthorID:        string(pr.AuthorID),
+			TeamName:        string(pr.TeamName),
 			Status:          string(pr.Status),
+			CreatedAt:       pr.CreatedAt.UTC().Format(time.RFC3339),
+			ReviewState:     string(pr.ReviewState),
 		}
 	}
 
 	return userReviewResponse{
 		UserID:       string(assigns.UserID),
 		PullRequests: prs,
+		NextCursor:   encodeCursor(assigns.NextCursor),
+	}
+}
+
+func newUserAuthoredResponse(authored service.UserAuthoredPullRequests, now time.Time) userAuthoredResponse {
+	prs := make([]authoredPullRequestDTO, len(authored.PullRequests))
+	for i, pr := range authored.PullRequests {
+		prs[i] = authoredPullRequestDTO{
+			pullRequestResponse: newPullRequestResponse(pr),
+			TimeOpenSeconds:     int64(pr.OpenDuration(now).Seconds()),
+		}
+	}
+
+	return userAuthoredResponse{
+		UserID:       string(authored.UserID),
+		PullRequests: prs,
+		NextCursor:   encodeCursor(authored.NextCursor),
 	}
 }
 
@@ -90,7 +184,13 @@ func (h *Handler) handleGetReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assignments, err := h.userService.ReviewAssignments(r.Context(), domain.UserID(userID))
+	filter, err := parsePullRequestFilter(r.URL.Query())
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	assignments, err := h.userService.ReviewAssignments(r.Context(), domain.UserID(userID), filter)
 	if err != nil {
 		h.respondError(w, r, err)
 		return
@@ -100,3 +200,164 @@ func (h *Handler) handleGetReview(w http.ResponseWriter, r *http.Request) {
 
 	h.respondJSON(w, r, http.StatusOK, resp)
 }
+
+func (h *Handler) handleGetAuthored(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "missing required 'user_id' query parameter"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	filter, err := parsePullRequestFilter(r.URL.Query())
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	authored, err := h.userService.AuthoredPullRequests(r.Context(), domain.UserID(userID), filter)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := newUserAuthoredResponse(authored, time.Now())
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "missing required 'user_id' query parameter"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	user, err := h.userService.User(r.Context(), domain.UserID(userID))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := getUserResponse{
+		User: newUserResponse(user),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseUserFilter(r.URL.Query())
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	list, err := h.userService.ListUsers(r.Context(), filter)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	users := make([]userResponse, len(list.Users))
+	for i, user := range list.Users {
+		users[i] = newUserResponse(user)
+	}
+
+	resp := listUsersResponse{
+		Users:      users,
+		NextCursor: encodeUserCursor(list.NextCursor),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	var req updateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	update := service.UserUpdate{
+		Username: req.Username,
+		FullName: req.FullName,
+		Email:    req.Email,
+	}
+	if req.Role != nil {
+		role := domain.UserRole(strings.ToUpper(*req.Role))
+		update.Role = &role
+	}
+
+	user, err := h.userService.UpdateUser(r.Context(), domain.UserID(req.UserID), update)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := updateUserResponse{
+		User: newUserResponse(user),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	var req deleteUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	released, err := h.userService.DeleteUser(r.Context(), domain.UserID(req.UserID), req.ReassignReviews)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := deleteUserResponse{
+		UserID:          req.UserID,
+		ReleasedReviews: newReleasedReviewDTOs(released),
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleOffboardUser(w http.ResponseWriter, r *http.Request) {
+	var req offboardUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	offboarding, err := h.userService.Offboard(r.Context(), domain.UserID(req.UserID), domain.UserID(req.SuccessorID))
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := offboardUserResponse{
+		User:                    newUserResponse(offboarding.User),
+		ReleasedReviews:         newReleasedReviewDTOs(offboarding.ReleasedReviews),
+		TransferredPullRequests: pullRequestIDsToStrings(offboarding.TransferredPullRequests),
+		ClosedPullRequests:      pullRequestIDsToStrings(offboarding.ClosedPullRequests),
+	}
+	if req.SuccessorID != "" {
+		resp.SuccessorID = &req.SuccessorID
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func pullRequestIDsToStrings(ids []domain.PullRequestID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = string(id)
+	}
+
+	return out
+}
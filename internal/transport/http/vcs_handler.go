@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/github"
+	"pr-reviewer-service/internal/gitlab"
+	"pr-reviewer-service/internal/service"
+)
+
+const maxVCSWebhookBytes = 25 << 20
+
+type vcsWebhookResponse struct {
+	Outcome string               `json:"outcome"`
+	PR      *pullRequestResponse `json:"pr,omitempty"`
+}
+
+func (h *Handler) respondVCSResult(w http.ResponseWriter, r *http.Request, result service.VCSResult) {
+	resp := vcsWebhookResponse{Outcome: string(result.Outcome)}
+	if result.PullRequest.ID != "" {
+		pr := newPullRequestResponse(result.PullRequest)
+		resp.PR = &pr
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+// readVCSWebhook reads the body of a Git host webhook, responding with an
+// error when the integration is disabled or the body can't be read.
+func (h *Handler) readVCSWebhook(w http.ResponseWriter, r *http.Request, secret string, provider domain.IdentityProvider) ([]byte, bool) {
+	if secret == "" {
+		apiErr := APIError{Code: "NOT_FOUND", Message: string(provider) + " integration is not configured"}
+		h.respondJSON(w, r, http.StatusNotFound, ErrorResponse{Error: apiErr})
+		return nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxVCSWebhookBytes))
+	if err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid request body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return nil, false
+	}
+
+	return body, true
+}
+
+func (h *Handler) respondUnauthorizedWebhook(w http.ResponseWriter, r *http.Request) {
+	apiErr := APIError{Code: "UNAUTHORIZED", Message: "invalid webhook signature"}
+	h.respondJSON(w, r, http.StatusUnauthorized, ErrorResponse{Error: apiErr})
+}
+
+// handleGitHubWebhook takes pull_request events of the GitHub webhook. The
+// sender of the event is recorded as the actor.
+func (h *Handler) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	secret := h.integrations.GitHubWebhookSecret
+	body, ok := h.readVCSWebhook(w, r, secret, domain.IdentityGitHub)
+	if !ok {
+		return
+	}
+
+	if !github.VerifySignature(secret, body, r.Header.Get(github.SignatureHeader)) {
+		h.respondUnauthorizedWebhook(w, r)
+		return
+	}
+
+	if r.Header.Get(github.EventHeader) != "pull_request" {
+		h.respondVCSResult(w, r, service.VCSResult{Outcome: service.VCSIgnored})
+		return
+	}
+
+	var event github.PullRequestEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	ctx := r.Context()
+	if event.Sender.Login != "" {
+		ctx = domain.WithActor(ctx, "github:"+event.Sender.Login)
+	}
+
+	vpr := event.VCSPullRequest()
+
+	var result service.VCSResult
+	var err error
+	switch {
+	case event.Action == "opened" || event.Action == "ready_for_review":
+		result, err = h.vcsService.Open(ctx, vpr)
+	case event.Action == "reopened":
+		result, err = h.vcsService.Reopen(ctx, vpr)
+	case event.Action == "closed" && event.PullRequest.Merged:
+		result, err = h.vcsService.Merge(ctx, vpr)
+	case event.Action == "closed":
+		result, err = h.vcsService.Close(ctx, vpr)
+	default:
+		result = service.VCSResult{Outcome: service.VCSIgnored}
+	}
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondVCSResult(w, r, result)
+}
+
+// handleGitLabWebhook takes Merge Request Hook events of the GitLab webhook.
+// The user that triggered the event is recorded as the actor. Of the updates
+// only marking ready for review counts, the service picks the reviewers
+// itself and does not follow reviewer changes or retargets made at GitLab.
+func (h *Handler) handleGitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	secret := h.integrations.GitLabWebhookToken
+	body, ok := h.readVCSWebhook(w, r, secret, domain.IdentityGitLab)
+	if !ok {
+		return
+	}
+
+	if !gitlab.VerifyToken(secret, r.Header.Get(gitlab.TokenHeader)) {
+		h.respondUnauthorizedWebhook(w, r)
+		return
+	}
+
+	if r.Header.Get(gitlab.EventHeader) != gitlab.MergeRequestHook {
+		h.respondVCSResult(w, r, service.VCSResult{Outcome: service.VCSIgnored})
+		return
+	}
+
+	var event gitlab.MergeRequestEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	ctx := r.Context()
+	if event.User.Username != "" {
+		ctx = domain.WithActor(ctx, "gitlab:"+event.User.Username)
+	}
+
+	vpr := event.VCSPullRequest()
+
+	var result service.VCSResult
+	var err error
+	switch action := event.ObjectAttributes.Action; {
+	case action == "open" || (action == "update" && event.MarkedReady()):
+		result, err = h.vcsService.Open(ctx, vpr)
+	case action == "reopen":
+		result, err = h.vcsService.Reopen(ctx, vpr)
+	case action == "merge":
+		result, err = h.vcsService.Merge(ctx, vpr)
+	case action == "close":
+		result, err = h.vcsService.Close(ctx, vpr)
+	default:
+		result = service.VCSResult{Outcome: service.VCSIgnored}
+	}
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondVCSResult(w, r, result)
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"strconv"
+	"time"
+)
+
+type createWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+type webhookSubscriptionDTO struct {
+	SubscriptionID int64    `json:"subscription_id"`
+	URL            string   `json:"url"`
+	EventTypes     []string `json:"event_types"`
+	Secret         string   `json:"secret,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+type webhookSubscriptionResponse struct {
+	Subscription webhookSubscriptionDTO `json:"subscription"`
+}
+
+type webhookSubscriptionsResponse struct {
+	Subscriptions []webhookSubscriptionDTO `json:"subscriptions"`
+}
+
+type deleteWebhookRequest struct {
+	SubscriptionID int64 `json:"subscription_id"`
+}
+
+type redeliverWebhookRequest struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+type webhookDeliveryDTO struct {
+	DeliveryID     int64  `json:"delivery_id"`
+	SubscriptionID int64  `json:"subscription_id"`
+	EventID        int64  `json:"event_id"`
+	Event          string `json:"event"`
+	PullRequestID  string `json:"pull_request_id"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	NextAttemptAt  string `json:"next_attempt_at"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+type webhookDeliveryResponse struct {
+	Delivery webhookDeliveryDTO `json:"delivery"`
+}
+
+type webhookDeliveriesResponse struct {
+	Deliveries []webhookDeliveryDTO `json:"deliveries"`
+}
+
+// newWebhookSubscriptionDTO leaves the secret out unless withSecret is set,
+// it is only shown once when the subscription is created.
+func newWebhookSubscriptionDTO(subscription domain.WebhookSubscription, withSecret bool) webhookSubscriptionDTO {
+	eventTypes := make([]string, len(subscription.EventTypes))
+	for i, kind := range subscription.EventTypes {
+		eventTypes[i] = string(kind)
+	}
+
+	dto := webhookSubscriptionDTO{
+		SubscriptionID: subscription.ID,
+		URL:            subscription.URL,
+		EventTypes:     eventTypes,
+		CreatedAt:      subscription.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if withSecret {
+		dto.Secret = subscription.Secret
+	}
+
+	return dto
+}
+
+func newWebhookDeliveryDTO(delivery domain.WebhookDelivery) webhookDeliveryDTO {
+	return webhookDeliveryDTO{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: delivery.Subscription.ID,
+		EventID:        delivery.Event.ID,
+		Event:          string(delivery.Event.Kind),
+		PullRequestID:  string(delivery.Event.PullRequestID),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt.UTC().Format(time.RFC3339),
+		LastError:      delivery.LastError,
+	}
+}
+
+func (h *Handler) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	eventTypes := make([]domain.PREventKind, len(req.EventTypes))
+	for i, kind := range req.EventTypes {
+		eventTypes[i] = domain.PREventKind(kind)
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(r.Context(), domain.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := webhookSubscriptionResponse{
+		Subscription: newWebhookSubscriptionDTO(subscription, true),
+	}
+
+	h.respondJSON(w, r, http.StatusCreated, resp)
+}
+
+func (h *Handler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.webhookService.Subscriptions(r.Context())
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := webhookSubscriptionsResponse{
+		Subscriptions: make([]webhookSubscriptionDTO, len(subscriptions)),
+	}
+	for i, subscription := range subscriptions {
+		resp.Subscriptions[i] = newWebhookSubscriptionDTO(subscription, false)
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	var req deleteWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), req.SubscriptionID); err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	var subscriptionID int64
+	if raw := r.URL.Query().Get("subscription_id"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			apiErr := APIError{Code: "BAD_REQUEST", Message: "'subscription_id' must be an integer"}
+			h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+			return
+		}
+		subscriptionID = parsed
+	}
+
+	deliveries, err := h.webhookService.DeadDeliveries(r.Context(), subscriptionID)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	resp := webhookDeliveriesResponse{
+		Deliveries: make([]webhookDeliveryDTO, len(deliveries)),
+	}
+	for i, delivery := range deliveries {
+		resp.Deliveries[i] = newWebhookDeliveryDTO(delivery)
+	}
+
+	h.respondJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	var req redeliverWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiErr := APIError{Code: "BAD_REQUEST", Message: "invalid json body"}
+		h.respondJSON(w, r, http.StatusBadRequest, ErrorResponse{Error: apiErr})
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), req.DeliveryID)
+	if err != nil {
+		h.respondError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, webhookDeliveryResponse{Delivery: newWebhookDeliveryDTO(delivery)})
+}
@@ -0,0 +1,70 @@
+package vcs
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+	"time"
+)
+
+type Config struct {
+	// QueueSize bounds the reviewer changes waiting to be pushed.
+	QueueSize int
+	// Attempts and Backoff control how failed calls to a Git host are
+	// retried, see notify.Retry.
+	Attempts int
+	Backoff  time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		QueueSize: 256,
+		Attempts:  5,
+		Backoff:   time.Second,
+	}
+}
+
+// Sender pushes reviewer assignments of pull requests from Git hosts back to
+// the hosts, so that the reviewers are requested there too.
+type Sender struct {
+	vcsService *service.VCSService
+	clients    map[domain.IdentityProvider]service.VCSClient
+}
+
+// NewSender creates a sender for the hosts with a client, changes on pull
+// requests of other hosts are skipped.
+func NewSender(vs *service.VCSService, clients map[domain.IdentityProvider]service.VCSClient) *Sender {
+	return &Sender{
+		vcsService: vs,
+		clients:    clients,
+	}
+}
+
+// Send removes the replaced reviewer and requests the new ones. Both calls
+// are idempotent, so a failed send can be repeated as a whole.
+func (s *Sender) Send(ctx context.Context, n service.Notification) error {
+	request, ok, err := s.vcsService.ReviewRequest(ctx, n)
+	if err != nil || !ok {
+		return err
+	}
+
+	client, ok := s.clients[request.PullRequest.Provider]
+	if !ok {
+		return nil
+	}
+
+	vpr := request.PullRequest
+	if len(request.Remove) > 0 {
+		if err := client.RemoveReviewers(ctx, vpr.Repository, vpr.Number, request.Remove); err != nil {
+			return err
+		}
+	}
+
+	if len(request.Request) > 0 {
+		if err := client.RequestReviewers(ctx, vpr.Repository, vpr.Number, request.Request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
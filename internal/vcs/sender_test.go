@@ -0,0 +1,122 @@
+package vcs_test
+
+import (
+	"context"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/service"
+	"pr-reviewer-service/internal/vcs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clientCall struct {
+	Method     string
+	Repository string
+	Number     int
+	Logins     []string
+}
+
+type recordingClient struct {
+	calls []clientCall
+}
+
+func (c *recordingClient) RequestReviewers(_ context.Context, repository string, number int, logins []string) error {
+	c.calls = append(c.calls, clientCall{Method: "request", Repository: repository, Number: number, Logins: logins})
+	return nil
+}
+
+func (c *recordingClient) RemoveReviewers(_ context.Context, repository string, number int, logins []string) error {
+	c.calls = append(c.calls, clientCall{Method: "remove", Repository: repository, Number: number, Logins: logins})
+	return nil
+}
+
+func setupSenderTest(t *testing.T) (context.Context, *recordingClient, *vcs.Sender) {
+	t.Helper()
+
+	ctx := context.Background()
+	storage, _ := inmemory.NewStorage()
+	userRepo := inmemory.NewUserRepo(storage)
+	teamRepo := inmemory.NewTeamRepo(storage)
+	prRepo := inmemory.NewPullRequestRepo(storage)
+	identityRepo := inmemory.NewIdentityRepo(storage)
+
+	require.NoError(t, teamRepo.Create(ctx, domain.Team{Name: "backend"}))
+	for _, userID := range []domain.UserID{"u1", "u2", "u3"} {
+		require.NoError(t, userRepo.Create(ctx, domain.User{ID: userID, Username: string(userID), TeamName: "backend", IsActive: true}))
+	}
+	identityService := service.NewIdentityService(identityRepo, userRepo)
+	for userID, login := range map[domain.UserID]string{"u1": "alice", "u2": "bob"} {
+		_, err := identityService.LinkIdentity(ctx, domain.UserIdentity{UserID: userID, Provider: domain.IdentityGitHub, ExternalID: login})
+		require.NoError(t, err)
+	}
+
+	prService := service.NewPullRequestService(prRepo, userRepo, teamRepo, inmemory.NewUnitOfWork(storage), nil)
+	vcsService := service.NewVCSService(identityRepo, prRepo, prService, nil)
+	client := &recordingClient{}
+
+	return ctx, client, vcs.NewSender(vcsService, map[domain.IdentityProvider]service.VCSClient{domain.IdentityGitHub: client})
+}
+
+func TestSenderRequestsAssignedReviewers(t *testing.T) {
+	ctx, client, sender := setupSenderTest(t)
+
+	err := sender.Send(ctx, service.Notification{
+		Kind:        domain.EventReviewerAssigned,
+		PullRequest: domain.PullRequest{ID: "github:acme/api#42"},
+		Reviewers:   []domain.UserID{"u1", "u3"},
+	})
+	require.NoError(t, err)
+
+	// u3 has no GitHub login
+	assert.Equal(t, []clientCall{{Method: "request", Repository: "acme/api", Number: 42, Logins: []string{"alice"}}}, client.calls)
+}
+
+func TestSenderMovesReassignedReview(t *testing.T) {
+	ctx, client, sender := setupSenderTest(t)
+
+	err := sender.Send(ctx, service.Notification{
+		Kind:             domain.EventReviewerReassigned,
+		PullRequest:      domain.PullRequest{ID: "github:acme/api#42"},
+		Reviewers:        []domain.UserID{"u2"},
+		PreviousReviewer: "u1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []clientCall{
+		{Method: "remove", Repository: "acme/api", Number: 42, Logins: []string{"alice"}},
+		{Method: "request", Repository: "acme/api", Number: 42, Logins: []string{"bob"}},
+	}, client.calls)
+}
+
+func TestSenderRemovesDroppedReviewer(t *testing.T) {
+	ctx, client, sender := setupSenderTest(t)
+
+	err := sender.Send(ctx, service.Notification{
+		Kind:             domain.EventReviewerRemoved,
+		PullRequest:      domain.PullRequest{ID: "github:acme/api#42"},
+		PreviousReviewer: "u1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []clientCall{{Method: "remove", Repository: "acme/api", Number: 42, Logins: []string{"alice"}}}, client.calls)
+}
+
+func TestSenderSkipsOtherPullRequests(t *testing.T) {
+	ctx, client, sender := setupSenderTest(t)
+
+	notifications := []service.Notification{
+		// created through the API
+		{Kind: domain.EventReviewerAssigned, PullRequest: domain.PullRequest{ID: "pr-1"}, Reviewers: []domain.UserID{"u1"}},
+		// no client for GitLab
+		{Kind: domain.EventReviewerAssigned, PullRequest: domain.PullRequest{ID: "gitlab:platform/api#7"}, Reviewers: []domain.UserID{"u1"}},
+		{Kind: domain.EventMerged, PullRequest: domain.PullRequest{ID: "github:acme/api#42"}, Reviewers: []domain.UserID{"u1"}},
+	}
+	for _, n := range notifications {
+		require.NoError(t, sender.Send(ctx, n))
+	}
+
+	assert.Empty(t, client.calls)
+}
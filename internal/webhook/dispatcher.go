@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+
+	maxErrorBodyBytes = 512
+)
+
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout limits a single request to a receiver.
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:   time.Second,
+		BatchSize:      50,
+		MaxAttempts:    8,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Hour,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// Payload is the JSON body posted to the receivers.
+type Payload struct {
+	DeliveryID     int64     `json:"delivery_id"`
+	EventID        int64     `json:"event_id"`
+	Event          string    `json:"event"`
+	PullRequestID  string    `json:"pull_request_id"`
+	UserID         string    `json:"user_id,omitempty"`
+	PreviousUserID string    `json:"previous_user_id,omitempty"`
+	ReviewState    string    `json:"review_state,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	Actor          string    `json:"actor,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+func newPayload(delivery domain.WebhookDelivery) Payload {
+	return Payload{
+		DeliveryID:     delivery.ID,
+		EventID:        delivery.Event.ID,
+		Event:          string(delivery.Event.Kind),
+		PullRequestID:  string(delivery.Event.PullRequestID),
+		UserID:         string(delivery.Event.UserID),
+		PreviousUserID: string(delivery.Event.PreviousUserID),
+		ReviewState:    string(delivery.Event.ReviewState),
+		Reason:         string(delivery.Event.Reason),
+		Actor:          delivery.Event.Actor,
+		OccurredAt:     delivery.Event.CreatedAt.UTC(),
+	}
+}
+
+// Dispatcher delivers the outbox. Failed deliveries are retried with
+// exponential backoff until MaxAttempts, then they are kept as dead letters.
+type Dispatcher struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	cfg         Config
+	logger      *slog.Logger
+}
+
+func NewDispatcher(wr repository.WebhookRepository, client *http.Client, cfg Config, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		webhookRepo: wr,
+		client:      client,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// Run dispatches every PollInterval until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Dispatch(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				d.logger.ErrorContext(ctx, "webhook dispatch failed", "error", err)
+			}
+		}
+	}
+}
+
+// Dispatch attempts the deliveries that are due at now once and returns how
+// many were attempted.
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	// Deliveries are sent one after another, the lease has to outlast the
+	// whole batch.
+	lease := time.Duration(d.cfg.BatchSize+1) * d.cfg.Timeout
+
+	deliveries, err := d.webhookRepo.ClaimDeliveries(ctx, now, d.cfg.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		delivery = d.attempt(ctx, delivery, now)
+
+		// The subscription may have been deleted in the meantime.
+		err := d.webhookRepo.UpdateDelivery(ctx, delivery)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return 0, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery and returns it updated with the outcome.
+func (d *Dispatcher) attempt(ctx context.Context, delivery domain.WebhookDelivery, now time.Time) domain.WebhookDelivery {
+	delivery.Attempts++
+
+	err := d.send(ctx, delivery, now)
+	if err == nil {
+		delivery.Status = domain.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		delivery.Status = domain.DeliveryDead
+		d.logger.WarnContext(ctx, "webhook delivery moved to dead letters",
+			"delivery_id", delivery.ID,
+			"subscription_id", delivery.Subscription.ID,
+			"attempts", delivery.Attempts,
+			"error", err,
+		)
+		return delivery
+	}
+
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	return delivery
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery domain.WebhookDelivery, now time.Time) error {
+	body, err := json.Marshal(newPayload(delivery))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.Event.Kind))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return fmt.Errorf("receiver responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return nil
+}
+
+// backoff doubles the delay with every failed attempt up to MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, d.cfg.MaxBackoff)
+}
@@ -0,0 +1,216 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/inmemory"
+	"pr-reviewer-service/internal/webhook"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "s3cret"
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver records the requests it gets and answers with the queued status
+// codes, 200 once they run out.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, receivedRequest{header: r.Header.Clone(), body: body})
+
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+type testDispatchEnviroment struct {
+	ctx     context.Context
+	storage *inmemory.InMemoryStorage
+
+	webhookRepo *inmemory.WebhookRepo
+	prRepo      *inmemory.PullRequestRepo
+	receiver    *receiver
+	dispatcher  *webhook.Dispatcher
+}
+
+func setupDispatchTest(t *testing.T, eventTypes ...domain.PREventKind) testDispatchEnviroment {
+	t.Helper()
+
+	storage, _ := inmemory.NewStorage()
+	webhookRepo := inmemory.NewWebhookRepo(storage)
+
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	cfg := webhook.Config{
+		BatchSize:      10,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+		Timeout:        time.Second,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	e := testDispatchEnviroment{
+		ctx:         context.Background(),
+		storage:     storage,
+		webhookRepo: webhookRepo,
+		prRepo:      inmemory.NewPullRequestRepo(storage),
+		receiver:    rc,
+		dispatcher:  webhook.NewDispatcher(webhookRepo, server.Client(), cfg, logger),
+	}
+
+	_, err := webhookRepo.CreateSubscription(e.ctx, domain.WebhookSubscription{
+		URL:        server.URL,
+		EventTypes: eventTypes,
+		Secret:     testSecret,
+	})
+	require.NoError(t, err)
+
+	return e
+}
+
+func (e testDispatchEnviroment) createPR(t *testing.T) {
+	t.Helper()
+
+	_, err := e.prRepo.Create(domain.WithActor(e.ctx, "ci"), domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Add search",
+		AuthorID:          "u1",
+		Status:            domain.StatusOpen,
+		AssignedReviewers: []domain.UserID{"u2"},
+	})
+	require.NoError(t, err)
+}
+
+func (e testDispatchEnviroment) onlyDelivery(t *testing.T) domain.WebhookDelivery {
+	t.Helper()
+
+	require.Len(t, e.storage.WebhookDeliveries, 1)
+	for _, delivery := range e.storage.WebhookDeliveries {
+		return delivery
+	}
+
+	return domain.WebhookDelivery{}
+}
+
+func TestDispatchSendsSignedPayload(t *testing.T) {
+	e := setupDispatchTest(t, domain.EventReviewerAssigned)
+	e.createPR(t)
+
+	delivery := e.onlyDelivery(t)
+	assert.Equal(t, domain.EventReviewerAssigned, delivery.Event.Kind)
+
+	now := time.Now()
+	attempted, err := e.dispatcher.Dispatch(e.ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+
+	require.Len(t, e.receiver.requests, 1)
+	req := e.receiver.requests[0]
+
+	timestamp, err := strconv.ParseInt(req.header.Get(webhook.TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, webhook.Verify(testSecret, timestamp, req.body, req.header.Get(webhook.SignatureHeader)))
+	assert.False(t, webhook.Verify("other", timestamp, req.body, req.header.Get(webhook.SignatureHeader)))
+	assert.Equal(t, string(domain.EventReviewerAssigned), req.header.Get(webhook.EventHeader))
+
+	var payload webhook.Payload
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "pr-1", payload.PullRequestID)
+	assert.Equal(t, "u2", payload.UserID)
+	assert.Equal(t, "ci", payload.Actor)
+
+	delivery = e.onlyDelivery(t)
+	assert.Equal(t, domain.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+
+	attempted, err = e.dispatcher.Dispatch(e.ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+}
+
+func TestDispatchBacksOffAndDeadLetters(t *testing.T) {
+	e := setupDispatchTest(t, domain.EventReviewerAssigned)
+	e.receiver.statuses = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+	}
+	e.createPR(t)
+
+	now := time.Now()
+	_, err := e.dispatcher.Dispatch(e.ctx, now)
+	require.NoError(t, err)
+
+	delivery := e.onlyDelivery(t)
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Equal(t, now.Add(time.Minute), delivery.NextAttemptAt)
+	assert.Contains(t, delivery.LastError, "500")
+
+	attempted, err := e.dispatcher.Dispatch(e.ctx, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Zero(t, attempted, "not due before the backoff passed")
+
+	_, err = e.dispatcher.Dispatch(e.ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(3*time.Minute), e.onlyDelivery(t).NextAttemptAt)
+
+	_, err = e.dispatcher.Dispatch(e.ctx, now.Add(3*time.Minute))
+	require.NoError(t, err)
+
+	dead, err := e.webhookRepo.DeadDeliveries(e.ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Contains(t, dead[0].LastError, "503")
+	assert.Len(t, e.receiver.requests, 3)
+
+	_, err = e.webhookRepo.Redeliver(e.ctx, dead[0].ID)
+	require.NoError(t, err)
+
+	_, err = e.dispatcher.Dispatch(e.ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryDelivered, e.onlyDelivery(t).Status)
+}
+
+func TestOnlySubscribedEventsAreQueued(t *testing.T) {
+	e := setupDispatchTest(t, domain.EventMerged)
+	e.createPR(t)
+
+	assert.Empty(t, e.storage.WebhookDeliveries)
+
+	_, err := e.prRepo.MergeByID(e.ctx, "pr-1")
+	require.NoError(t, err)
+	_, err = e.prRepo.MergeByID(e.ctx, "pr-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.EventMerged, e.onlyDelivery(t).Event.Kind)
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pull_requests_created_at;
+ALTER TABLE pull_request_reviewers DROP COLUMN IF EXISTS review_state;
+DROP TYPE IF EXISTS review_state;
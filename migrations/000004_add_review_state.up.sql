@@ -0,0 +1,6 @@
+CREATE TYPE review_state AS ENUM ('PENDING', 'APPROVED', 'CHANGES_REQUESTED');
+
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS review_state review_state NOT NULL DEFAULT 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created_at ON pull_requests(created_at, pull_request_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pull_requests_team_name;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS team_name;
+DROP TABLE IF EXISTS team_memberships;
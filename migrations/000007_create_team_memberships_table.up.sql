@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS team_memberships (
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE RESTRICT ON UPDATE CASCADE,
+
+    PRIMARY KEY (user_id, team_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_memberships_team_name ON team_memberships(team_name);
+
+INSERT INTO team_memberships (user_id, team_name)
+SELECT user_id, team_name FROM users WHERE team_name IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS team_name TEXT REFERENCES teams(team_name) ON DELETE RESTRICT ON UPDATE CASCADE;
+
+UPDATE pull_requests pr
+SET team_name = u.team_name
+FROM users u
+WHERE u.user_id = pr.author_id AND pr.team_name IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_team_name ON pull_requests(team_name);
@@ -0,0 +1,4 @@
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS parent_team_name TEXT REFERENCES teams(team_name) ON DELETE RESTRICT ON UPDATE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_teams_parent_team_name ON teams(parent_team_name);
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS role,
+    DROP COLUMN IF EXISTS full_name;
+
+DROP TYPE IF EXISTS user_role;
@@ -0,0 +1,6 @@
+CREATE TYPE user_role AS ENUM ('MEMBER', 'LEAD', 'ADMIN');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS full_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS role user_role NOT NULL DEFAULT 'MEMBER',
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,15 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS closed_at;
+
+-- Enum values can not be dropped, the type is recreated without CLOSED.
+-- Closed pull requests are reopened.
+UPDATE pull_requests SET status = 'OPEN' WHERE status = 'CLOSED';
+
+ALTER TYPE pr_status RENAME TO pr_status_old;
+CREATE TYPE pr_status AS ENUM ('OPEN', 'MERGED');
+
+ALTER TABLE pull_requests
+    ALTER COLUMN status DROP DEFAULT,
+    ALTER COLUMN status TYPE pr_status USING status::text::pr_status,
+    ALTER COLUMN status SET DEFAULT 'OPEN';
+
+DROP TYPE pr_status_old;
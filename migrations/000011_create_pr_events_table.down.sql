@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pr_events;
+DROP TYPE IF EXISTS pr_event_kind;
@@ -0,0 +1,41 @@
+CREATE TYPE pr_event_kind AS ENUM (
+    'CREATED',
+    'REVIEWER_ASSIGNED',
+    'REVIEWER_REASSIGNED',
+    'REVIEWER_REMOVED',
+    'REVIEW_SUBMITTED',
+    'AUTHOR_CHANGED',
+    'MERGED',
+    'CLOSED'
+);
+
+-- Append-only, rows are never updated. User IDs are not foreign keys so the
+-- history outlives the users.
+CREATE TABLE IF NOT EXISTS pr_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    kind pr_event_kind NOT NULL,
+    user_id TEXT,
+    previous_user_id TEXT,
+    review_state review_state,
+    reason TEXT,
+    actor TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pull_request_id ON pr_events(pull_request_id, event_id);
+
+-- Existing pull requests start their timeline with what is still known.
+INSERT INTO pr_events (pull_request_id, kind, user_id, created_at)
+SELECT pull_request_id, 'CREATED', author_id, created_at FROM pull_requests;
+
+INSERT INTO pr_events (pull_request_id, kind, user_id, created_at)
+SELECT prr.pull_request_id, 'REVIEWER_ASSIGNED', prr.user_id, pr.created_at
+FROM pull_request_reviewers prr
+JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id;
+
+INSERT INTO pr_events (pull_request_id, kind, created_at)
+SELECT pull_request_id, 'MERGED', merged_at FROM pull_requests WHERE merged_at IS NOT NULL;
+
+INSERT INTO pr_events (pull_request_id, kind, created_at)
+SELECT pull_request_id, 'CLOSED', closed_at FROM pull_requests WHERE closed_at IS NOT NULL;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS team_chat_channels (
+    team_name TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE,
+    webhook_url TEXT NOT NULL,
+    templates JSONB NOT NULL DEFAULT '{}'
+);
@@ -0,0 +1,20 @@
+-- Enum values can not be dropped, the type is recreated without REOPENED.
+DELETE FROM pr_events WHERE kind = 'REOPENED';
+UPDATE webhook_subscriptions SET event_types = array_remove(event_types, 'REOPENED');
+
+ALTER TYPE pr_event_kind RENAME TO pr_event_kind_old;
+CREATE TYPE pr_event_kind AS ENUM (
+    'CREATED',
+    'REVIEWER_ASSIGNED',
+    'REVIEWER_REASSIGNED',
+    'REVIEWER_REMOVED',
+    'REVIEW_SUBMITTED',
+    'AUTHOR_CHANGED',
+    'MERGED',
+    'CLOSED'
+);
+
+ALTER TABLE pr_events ALTER COLUMN kind TYPE pr_event_kind USING kind::text::pr_event_kind;
+ALTER TABLE webhook_subscriptions ALTER COLUMN event_types TYPE pr_event_kind[] USING event_types::text[]::pr_event_kind[];
+
+DROP TYPE pr_event_kind_old;
@@ -0,0 +1 @@
+ALTER TYPE pr_event_kind ADD VALUE IF NOT EXISTS 'REOPENED';